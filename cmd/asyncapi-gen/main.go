@@ -0,0 +1,92 @@
+// Command asyncapi-gen writes the AsyncAPI 3.0 document and per-payload JSON Schemas
+// for every event registered in events/*.
+//
+// Usage:
+//
+//	go run ./cmd/asyncapi-gen -schemas asyncapi/schemas > asyncapi/asyncapi.yaml
+//
+// The document is written to stdout (or -out) as JSON, which is also valid YAML.
+// With -schemas, each payload is written to <dir>/<package>.<Payload>.schema.json.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/events/asyncapi"
+)
+
+func main() {
+	out := flag.String("out", "", "file to write the AsyncAPI document to (default stdout)")
+	schemaDir := flag.String("schemas", "", "directory to write per-payload JSON Schemas to (optional)")
+	title := flag.String("title", "Frolf Bot Events", "document title")
+	version := flag.String("version", "1.0.0", "API version recorded in the document")
+	flag.Parse()
+
+	if err := run(*out, *schemaDir, *title, *version); err != nil {
+		fmt.Fprintf(os.Stderr, "asyncapi-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(out, schemaDir, title, version string) error {
+	gen := asyncapi.NewGenerator(asyncapi.DefaultDomains(),
+		asyncapi.WithTitle(title),
+		asyncapi.WithVersion(version),
+	)
+
+	doc, err := gen.Document()
+	if err != nil {
+		return fmt.Errorf("failed to build AsyncAPI document: %w", err)
+	}
+
+	if out == "" {
+		if err := encodeJSON(os.Stdout, doc); err != nil {
+			return fmt.Errorf("failed to write AsyncAPI document: %w", err)
+		}
+	} else if err := writeJSON(out, doc); err != nil {
+		return err
+	}
+
+	if schemaDir == "" {
+		return nil
+	}
+
+	schemas, err := gen.PayloadSchemas()
+	if err != nil {
+		return fmt.Errorf("failed to build payload schemas: %w", err)
+	}
+	if err := os.MkdirAll(schemaDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create schema directory: %w", err)
+	}
+	for name, schema := range schemas {
+		if err := writeJSON(filepath.Join(schemaDir, name+".schema.json"), schema); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(os.Stderr, "wrote %d payload schemas to %s\n", len(schemas), schemaDir)
+	return nil
+}
+
+func writeJSON(path string, v any) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if err := encodeJSON(f, v); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+func encodeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
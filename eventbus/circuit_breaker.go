@@ -0,0 +1,157 @@
+package eventbus
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultCircuitFailureThreshold is the number of consecutive failed publishes that
+	// opens the circuit.
+	defaultCircuitFailureThreshold = 5
+
+	// defaultCircuitOpenTimeout is how long the circuit stays open before a probe publish.
+	defaultCircuitOpenTimeout = 10 * time.Second
+)
+
+// ErrCircuitOpen is returned by Publish while the publish circuit breaker is open and
+// no spool is configured to buffer the messages.
+var ErrCircuitOpen = errors.New("publish circuit breaker is open")
+
+// CircuitState is the state of the publish circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets publishes through.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails publishes fast until the open timeout elapses.
+	CircuitOpen
+	// CircuitHalfOpen lets a single probe publish through to test recovery.
+	CircuitHalfOpen
+)
+
+// String returns the state name used in logs and metrics.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerConfig tunes the publish circuit breaker. Zero fields use the defaults.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive publishes failing with retryable
+	// (connectivity) errors that opens the circuit.
+	FailureThreshold int
+
+	// OpenTimeout is how long the circuit stays open before a probe publish is allowed.
+	OpenTimeout time.Duration
+}
+
+// circuitBreaker trips after consecutive connectivity failures so publishes fail fast
+// instead of each waiting out the retry loop while NATS is down.
+type circuitBreaker struct {
+	threshold   int
+	openTimeout time.Duration
+	now         func() time.Time
+	// onChange is called, outside the lock, after every state transition.
+	onChange func(from, to CircuitState)
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newCircuitBreaker(cfg CircuitBreakerConfig, onChange func(from, to CircuitState)) *circuitBreaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = defaultCircuitFailureThreshold
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = defaultCircuitOpenTimeout
+	}
+	return &circuitBreaker{
+		threshold:   cfg.FailureThreshold,
+		openTimeout: cfg.OpenTimeout,
+		now:         time.Now,
+		onChange:    onChange,
+	}
+}
+
+// State returns the current state.
+func (b *circuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// allow reports whether a publish may be attempted. Once the open timeout has elapsed
+// it admits one probe at a time until the probe's outcome is recorded.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	from := b.state
+	switch b.state {
+	case CircuitClosed:
+		b.mu.Unlock()
+		return true
+	case CircuitOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			b.mu.Unlock()
+			return false
+		}
+		b.state = CircuitHalfOpen
+	}
+	if b.probing {
+		b.mu.Unlock()
+		return false
+	}
+	b.probing = true
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+	return true
+}
+
+// success records a successful publish, closing the circuit.
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	from := b.state
+	b.state = CircuitClosed
+	b.failures = 0
+	b.probing = false
+	b.mu.Unlock()
+
+	b.notify(from, CircuitClosed)
+}
+
+// failure records a publish that failed with a connectivity error. A failed probe, or
+// reaching the failure threshold, opens the circuit.
+func (b *circuitBreaker) failure() {
+	b.mu.Lock()
+	from := b.state
+	b.failures++
+	if b.state == CircuitHalfOpen || b.failures >= b.threshold {
+		b.state = CircuitOpen
+		b.openedAt = b.now()
+	}
+	b.probing = false
+	to := b.state
+	b.mu.Unlock()
+
+	b.notify(from, to)
+}
+
+func (b *circuitBreaker) notify(from, to CircuitState) {
+	if from != to && b.onChange != nil {
+		b.onChange(from, to)
+	}
+}
@@ -0,0 +1,55 @@
+package eventbus
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker_OpensAfterThresholdAndProbes(t *testing.T) {
+	var transitions []CircuitState
+	b := newCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute}, func(_, to CircuitState) {
+		transitions = append(transitions, to)
+	})
+	now := time.Unix(0, 0)
+	b.now = func() time.Time { return now }
+
+	b.failure()
+	if !b.allow() {
+		t.Fatal("expected circuit to stay closed below the threshold")
+	}
+	b.failure()
+	if b.State() != CircuitOpen || b.allow() {
+		t.Fatalf("expected circuit to open at the threshold, got %s", b.State())
+	}
+
+	now = now.Add(time.Minute)
+	if !b.allow() {
+		t.Fatal("expected a probe once the open timeout elapsed")
+	}
+	if b.allow() {
+		t.Fatal("expected only one probe at a time")
+	}
+	b.failure()
+	if b.State() != CircuitOpen {
+		t.Fatalf("expected a failed probe to reopen the circuit, got %s", b.State())
+	}
+
+	now = now.Add(time.Minute)
+	if !b.allow() {
+		t.Fatal("expected another probe")
+	}
+	b.success()
+	if b.State() != CircuitClosed || !b.allow() {
+		t.Fatalf("expected a successful probe to close the circuit, got %s", b.State())
+	}
+
+	want := []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitOpen, CircuitHalfOpen, CircuitClosed}
+	if len(transitions) != len(want) {
+		t.Fatalf("expected transitions %v, got %v", want, transitions)
+	}
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Fatalf("expected transitions %v, got %v", want, transitions)
+		}
+	}
+}
@@ -18,26 +18,33 @@ type ConsumerManager struct {
 	js       jetstream.JetStream
 	logger   *slog.Logger
 	registry *ConsumerConfigRegistry
+	strategy MigrationStrategy
 
 	mu        sync.RWMutex
 	consumers map[string]jetstream.Consumer
 }
 
 // NewConsumerManager creates a new ConsumerManager.
-func NewConsumerManager(js jetstream.JetStream, logger *slog.Logger, registry *ConsumerConfigRegistry) *ConsumerManager {
+func NewConsumerManager(js jetstream.JetStream, logger *slog.Logger, registry *ConsumerConfigRegistry, opts ...ConsumerManagerOption) *ConsumerManager {
 	if registry == nil {
 		registry = NewConsumerConfigRegistry()
 	}
-	return &ConsumerManager{
+	cm := &ConsumerManager{
 		js:        js,
 		logger:    logger,
 		registry:  registry,
+		strategy:  MigrationUpdateInPlace,
 		consumers: make(map[string]jetstream.Consumer),
 	}
+	for _, opt := range opts {
+		opt(cm)
+	}
+	return cm
 }
 
-// EnsureConsumer creates or updates a durable consumer for the given stream and topic.
-// It uses CreateOrUpdateConsumer for idempotent operations and caches the result.
+// EnsureConsumer creates a durable consumer for the given stream and topic, or migrates
+// an existing one whose config has drifted from the registry according to the manager's
+// MigrationStrategy, and caches the result.
 // Returns the consumer, which can be used to create message iterators.
 func (cm *ConsumerManager) EnsureConsumer(ctx context.Context, streamName, topic, appType string) (jetstream.Consumer, error) {
 	consumerName := buildConsumerName(appType, topic)
@@ -71,27 +78,16 @@ func (cm *ConsumerManager) EnsureConsumer(ctx context.Context, streamName, topic
 
 	consumerConfig := buildDurableConsumerConfig(consumerName, topic, cfg)
 
-	ctxLogger.DebugContext(ctx, "Creating or updating consumer",
+	ctxLogger.DebugContext(ctx, "Ensuring consumer",
 		"ack_wait", cfg.AckWait,
 		"max_deliver", cfg.MaxDeliver,
 		"max_ack_pending", cfg.MaxAckPending,
 	)
 
-	// CreateOrUpdateConsumer is idempotent - safe to call multiple times
-	cons, err := cm.js.CreateOrUpdateConsumer(ctx, streamName, consumerConfig)
+	cons, err := cm.reconcileConsumer(ctx, streamName, consumerConfig, ctxLogger)
 	if err != nil {
-		ctxLogger.ErrorContext(ctx, "Failed to create or update consumer", "error", err)
-		return nil, fmt.Errorf("failed to create or update consumer %s on stream %s: %w", consumerName, streamName, err)
-	}
-
-	info, err := cons.Info(ctx)
-	if err != nil {
-		ctxLogger.ErrorContext(ctx, "Failed to fetch consumer info after create/update", "error", err)
-		return nil, fmt.Errorf("failed to fetch consumer info %s on stream %s: %w", consumerName, streamName, err)
-	}
-	if err := validateConsumerInfo(info, consumerConfig); err != nil {
-		ctxLogger.ErrorContext(ctx, "Consumer configuration validation failed", "error", err)
-		return nil, fmt.Errorf("consumer %s validation failed: %w", consumerName, err)
+		ctxLogger.ErrorContext(ctx, "Failed to ensure consumer", "error", err)
+		return nil, err
 	}
 
 	// Cache the consumer (in case another goroutine created one while we were in-flight,
@@ -108,6 +104,75 @@ func (cm *ConsumerManager) EnsureConsumer(ctx context.Context, streamName, topic
 	return cons, nil
 }
 
+// ApplyConsumerConfig updates a cached durable consumer in place when the registry's
+// AckWait, BackOff or MaxAckPending for it differ from the server's, logging each
+// transition for audit. Other settings are reconciled by EnsureConsumer according to the
+// migration strategy. Consumers not yet ensured pick up the registry on creation.
+func (cm *ConsumerManager) ApplyConsumerConfig(ctx context.Context, streamName, topic, appType string) error {
+	consumerName := buildConsumerName(appType, topic)
+
+	cm.mu.RLock()
+	cons, ok := cm.consumers[consumerName]
+	cm.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	ctxLogger := cm.logger.With(
+		"operation", "apply_consumer_config",
+		"stream", streamName,
+		"topic", topic,
+		"app_type", appType,
+		"consumer_name", consumerName,
+	)
+
+	info, err := cons.Info(ctx)
+	if err != nil {
+		ctxLogger.ErrorContext(ctx, "Failed to fetch consumer info", "error", err)
+		return fmt.Errorf("failed to fetch consumer info %s on stream %s: %w", consumerName, streamName, err)
+	}
+
+	cfg := cm.registry.Resolve(appType, topic)
+	next := info.Config
+	next.AckWait = cfg.AckWait
+	next.BackOff = cfg.BackOff
+	next.MaxAckPending = cfg.MaxAckPending
+
+	diffs := diffConsumerConfig(info.Config, next)
+	if len(diffs) == 0 {
+		return nil
+	}
+
+	updated, err := cm.js.CreateOrUpdateConsumer(ctx, streamName, next)
+	if err != nil {
+		ctxLogger.ErrorContext(ctx, "Failed to update consumer", "error", err)
+		return fmt.Errorf("failed to update consumer %s on stream %s: %w", consumerName, streamName, err)
+	}
+
+	updatedInfo, err := updated.Info(ctx)
+	if err != nil {
+		ctxLogger.ErrorContext(ctx, "Failed to fetch consumer info after update", "error", err)
+		return fmt.Errorf("failed to fetch consumer info %s on stream %s: %w", consumerName, streamName, err)
+	}
+	if err := validateConsumerInfo(updatedInfo, next); err != nil {
+		ctxLogger.ErrorContext(ctx, "Consumer configuration validation failed after update", "error", err)
+		return fmt.Errorf("consumer %s validation failed: %w", consumerName, err)
+	}
+
+	cm.mu.Lock()
+	cm.consumers[consumerName] = updated
+	cm.mu.Unlock()
+
+	for _, d := range diffs {
+		ctxLogger.InfoContext(ctx, "Consumer config transition",
+			"field", d.Field,
+			"from", d.Live,
+			"to", d.Desired,
+		)
+	}
+	return nil
+}
+
 func buildDurableConsumerConfig(consumerName, topic string, cfg ConsumerConfig) jetstream.ConsumerConfig {
 	return jetstream.ConsumerConfig{
 		Durable:       consumerName,
@@ -174,8 +239,18 @@ func effectiveAckWait(cfg jetstream.ConsumerConfig) time.Duration {
 	return cfg.AckWait
 }
 
+// ackWaitMatches accepts either the effective AckWait or the configured one, since
+// config read back from a client rather than a server is not normalized.
 func ackWaitMatches(actual, expected jetstream.ConsumerConfig) bool {
-	return actual.AckWait == effectiveAckWait(expected)
+	return actual.AckWait == effectiveAckWait(expected) || actual.AckWait == expected.AckWait
+}
+
+// backOffEqual treats nil and empty backoff lists as equal.
+func backOffEqual(a, b []time.Duration) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
 }
 
 // GetConsumerInfo returns information about a cached consumer.
@@ -210,28 +285,3 @@ func buildConsumerName(appType, topic string) string {
 
 	return fmt.Sprintf("%s-%s", appType, sanitized)
 }
-
-// ResolveStreamFromTopic determines the stream name from a topic.
-// This centralizes stream resolution logic.
-func ResolveStreamFromTopic(topic string) (string, error) {
-	switch {
-	case strings.HasPrefix(topic, "user."):
-		return "user", nil
-	case strings.HasPrefix(topic, "leaderboard."):
-		return "leaderboard", nil
-	case strings.HasPrefix(topic, "round."):
-		return "round", nil
-	case strings.HasPrefix(topic, "score."):
-		return "score", nil
-	case strings.HasPrefix(topic, "guild."):
-		return "guild", nil
-	case strings.HasPrefix(topic, "discord."):
-		return "discord", nil
-	case strings.HasPrefix(topic, "auth."):
-		return "auth", nil
-	case strings.HasPrefix(topic, "club."):
-		return "club", nil
-	default:
-		return "", fmt.Errorf("unknown topic prefix: %s", topic)
-	}
-}
@@ -0,0 +1,299 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// ErrConsumerConfigDrift is returned when a durable consumer's live config differs from
+// the registry and the migration strategy does not allow reconciling it.
+var ErrConsumerConfigDrift = errors.New("consumer config drift")
+
+// recreatedDeliverPolicyMetadataKey records the deliver policy a consumer was recreated
+// for. Recreated consumers start by sequence, so drift checks compare against this.
+const recreatedDeliverPolicyMetadataKey = "frolf_deliver_policy"
+
+// MigrationStrategy controls what EnsureConsumer does when an existing durable consumer's
+// live config differs from the registry.
+type MigrationStrategy int
+
+const (
+	// MigrationFail refuses to start the consumer until the drift is resolved by hand.
+	MigrationFail MigrationStrategy = iota
+
+	// MigrationUpdateInPlace updates fields JetStream can change on a live consumer and
+	// fails when an immutable field, such as the deliver policy, differs.
+	MigrationUpdateInPlace
+
+	// MigrationRecreate updates in place when possible and otherwise deletes the consumer
+	// and recreates it starting after its ack floor. Messages acked out of order above the
+	// ack floor are redelivered.
+	MigrationRecreate
+)
+
+// String returns the strategy name used in logs and reports.
+func (s MigrationStrategy) String() string {
+	switch s {
+	case MigrationFail:
+		return "fail"
+	case MigrationUpdateInPlace:
+		return "update_in_place"
+	case MigrationRecreate:
+		return "recreate"
+	default:
+		return fmt.Sprintf("MigrationStrategy(%d)", int(s))
+	}
+}
+
+// MigrationAction is what a migration strategy does with a drifted consumer.
+type MigrationAction string
+
+const (
+	MigrationActionFail     MigrationAction = "fail"
+	MigrationActionUpdate   MigrationAction = "update"
+	MigrationActionRecreate MigrationAction = "recreate"
+)
+
+// ConsumerConfigDiff is one field whose live value differs from the desired one.
+type ConsumerConfigDiff struct {
+	Field   string
+	Live    string
+	Desired string
+	// Mutable reports whether JetStream can change the field on a live consumer.
+	Mutable bool
+}
+
+// ConsumerDrift describes a durable consumer whose live config differs from the registry.
+type ConsumerDrift struct {
+	Stream   string
+	Consumer string
+	Topic    string
+	Diffs    []ConsumerConfigDiff
+	// Action is what EnsureConsumer would do under the manager's strategy.
+	Action MigrationAction
+}
+
+// ConsumerManagerOption configures a ConsumerManager.
+type ConsumerManagerOption func(*ConsumerManager)
+
+// WithMigrationStrategy sets how EnsureConsumer reconciles drifted consumers.
+// The default is MigrationUpdateInPlace.
+func WithMigrationStrategy(strategy MigrationStrategy) ConsumerManagerOption {
+	return func(cm *ConsumerManager) {
+		cm.strategy = strategy
+	}
+}
+
+// DriftReport lists every durable consumer of appType on the topology's streams whose
+// live config differs from the registry, with the action the manager's strategy would
+// take. It changes nothing, so it can be run as a dry run before a deploy.
+func (cm *ConsumerManager) DriftReport(ctx context.Context, appType string, topology *StreamTopology) ([]ConsumerDrift, error) {
+	var report []ConsumerDrift
+	for _, def := range topology.Streams() {
+		stream, err := cm.js.Stream(ctx, def.Name)
+		if errors.Is(err, jetstream.ErrStreamNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get stream %s: %w", def.Name, err)
+		}
+
+		lister := stream.ListConsumers(ctx)
+		for info := range lister.Info() {
+			if info.Config.Durable == "" || !strings.HasPrefix(info.Name, appType+"-") {
+				continue
+			}
+			topic := info.Config.FilterSubject
+			desired := buildDurableConsumerConfig(info.Name, topic, cm.registry.Resolve(appType, topic))
+			diffs := diffConsumerConfig(info.Config, desired)
+			if len(diffs) == 0 {
+				continue
+			}
+			report = append(report, ConsumerDrift{
+				Stream:   def.Name,
+				Consumer: info.Name,
+				Topic:    topic,
+				Diffs:    diffs,
+				Action:   migrationAction(cm.strategy, diffs),
+			})
+		}
+		if err := lister.Err(); err != nil {
+			return nil, fmt.Errorf("failed to list consumers on stream %s: %w", def.Name, err)
+		}
+	}
+	return report, nil
+}
+
+// reconcileConsumer returns the durable consumer for desired, creating it if missing and
+// migrating it according to the manager's strategy if its live config has drifted.
+func (cm *ConsumerManager) reconcileConsumer(ctx context.Context, streamName string, desired jetstream.ConsumerConfig, logger *slog.Logger) (jetstream.Consumer, error) {
+	live, err := cm.js.Consumer(ctx, streamName, desired.Durable)
+	if errors.Is(err, jetstream.ErrConsumerNotFound) {
+		// CreateOrUpdateConsumer is idempotent - safe if another replica races us
+		cons, err := cm.js.CreateOrUpdateConsumer(ctx, streamName, desired)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create or update consumer %s on stream %s: %w", desired.Durable, streamName, err)
+		}
+		return cons, cm.verifyConsumer(ctx, cons, desired)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consumer %s on stream %s: %w", desired.Durable, streamName, err)
+	}
+
+	info := live.CachedInfo()
+	diffs := diffConsumerConfig(info.Config, desired)
+	if len(diffs) == 0 {
+		return live, nil
+	}
+
+	action := migrationAction(cm.strategy, diffs)
+	logger.WarnContext(ctx, "Consumer config drift detected",
+		"strategy", cm.strategy.String(),
+		"action", string(action),
+		"drift", describeConsumerDiffs(diffs),
+	)
+
+	var (
+		cons     jetstream.Consumer
+		expected jetstream.ConsumerConfig
+	)
+	switch action {
+	case MigrationActionUpdate:
+		// Immutable fields match (possibly via a recreation marker); keep the live ones.
+		expected = desired
+		expected.DeliverPolicy = info.Config.DeliverPolicy
+		expected.OptStartSeq = info.Config.OptStartSeq
+		expected.OptStartTime = info.Config.OptStartTime
+		expected.Metadata = info.Config.Metadata
+		cons, err = cm.js.CreateOrUpdateConsumer(ctx, streamName, expected)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update consumer %s on stream %s: %w", desired.Durable, streamName, err)
+		}
+
+	case MigrationActionRecreate:
+		expected = desired
+		expected.DeliverPolicy = jetstream.DeliverByStartSequencePolicy
+		expected.OptStartSeq = info.AckFloor.Stream + 1
+		expected.Metadata = map[string]string{recreatedDeliverPolicyMetadataKey: desired.DeliverPolicy.String()}
+		if err := cm.js.DeleteConsumer(ctx, streamName, desired.Durable); err != nil && !errors.Is(err, jetstream.ErrConsumerNotFound) {
+			return nil, fmt.Errorf("failed to delete consumer %s on stream %s: %w", desired.Durable, streamName, err)
+		}
+		cons, err = cm.js.CreateConsumer(ctx, streamName, expected)
+		if err != nil {
+			return nil, fmt.Errorf("failed to recreate consumer %s on stream %s: %w", desired.Durable, streamName, err)
+		}
+		logger.InfoContext(ctx, "Consumer recreated", "start_sequence", expected.OptStartSeq)
+
+	default:
+		return nil, fmt.Errorf("consumer %s on stream %s: %w: %s", desired.Durable, streamName, ErrConsumerConfigDrift, describeConsumerDiffs(diffs))
+	}
+
+	if err := cm.verifyConsumer(ctx, cons, expected); err != nil {
+		return nil, err
+	}
+	for _, d := range diffs {
+		logger.InfoContext(ctx, "Consumer config transition",
+			"field", d.Field,
+			"from", d.Live,
+			"to", d.Desired,
+		)
+	}
+	return cons, nil
+}
+
+// verifyConsumer checks that the server applied expected.
+func (cm *ConsumerManager) verifyConsumer(ctx context.Context, cons jetstream.Consumer, expected jetstream.ConsumerConfig) error {
+	info, err := cons.Info(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch consumer info %s: %w", expected.Durable, err)
+	}
+	if err := validateConsumerInfo(info, expected); err != nil {
+		return fmt.Errorf("consumer %s validation failed: %w", expected.Durable, err)
+	}
+	return nil
+}
+
+// migrationAction picks what strategy does with a consumer that has diffs.
+func migrationAction(strategy MigrationStrategy, diffs []ConsumerConfigDiff) MigrationAction {
+	mutable := true
+	for _, d := range diffs {
+		mutable = mutable && d.Mutable
+	}
+
+	switch {
+	case strategy == MigrationUpdateInPlace && mutable, strategy == MigrationRecreate && mutable:
+		return MigrationActionUpdate
+	case strategy == MigrationRecreate:
+		return MigrationActionRecreate
+	default:
+		return MigrationActionFail
+	}
+}
+
+// diffConsumerConfig lists the fields EnsureConsumer manages whose live value differs
+// from desired.
+func diffConsumerConfig(live, desired jetstream.ConsumerConfig) []ConsumerConfigDiff {
+	var diffs []ConsumerConfigDiff
+	add := func(field string, liveValue, desiredValue any, mutable bool) {
+		diffs = append(diffs, ConsumerConfigDiff{
+			Field:   field,
+			Live:    fmt.Sprint(liveValue),
+			Desired: fmt.Sprint(desiredValue),
+			Mutable: mutable,
+		})
+	}
+
+	if liveDeliver, desiredDeliver := effectiveDeliverPolicy(live), effectiveDeliverPolicy(desired); liveDeliver != desiredDeliver {
+		add("deliver_policy", liveDeliver, desiredDeliver, false)
+	}
+	if live.AckPolicy != desired.AckPolicy {
+		add("ack_policy", live.AckPolicy, desired.AckPolicy, false)
+	}
+	if live.FilterSubject != desired.FilterSubject {
+		add("filter_subject", live.FilterSubject, desired.FilterSubject, true)
+	}
+	if !ackWaitMatches(live, desired) {
+		add("ack_wait", live.AckWait, effectiveAckWait(desired), true)
+	}
+	if !backOffEqual(live.BackOff, desired.BackOff) {
+		add("backoff", live.BackOff, desired.BackOff, true)
+	}
+	if live.MaxDeliver != desired.MaxDeliver {
+		add("max_deliver", live.MaxDeliver, desired.MaxDeliver, true)
+	}
+	if live.MaxAckPending != desired.MaxAckPending {
+		add("max_ack_pending", live.MaxAckPending, desired.MaxAckPending, true)
+	}
+	if live.InactiveThreshold != desired.InactiveThreshold {
+		add("inactive_threshold", live.InactiveThreshold, desired.InactiveThreshold, true)
+	}
+	return diffs
+}
+
+// effectiveDeliverPolicy returns the policy a consumer was created for, looking through
+// the start-by-sequence policy of consumers recreated by MigrationRecreate.
+func effectiveDeliverPolicy(cfg jetstream.ConsumerConfig) jetstream.DeliverPolicy {
+	recorded, ok := cfg.Metadata[recreatedDeliverPolicyMetadataKey]
+	if !ok || cfg.DeliverPolicy != jetstream.DeliverByStartSequencePolicy {
+		return cfg.DeliverPolicy
+	}
+	var policy jetstream.DeliverPolicy
+	if err := policy.UnmarshalJSON([]byte(strconv.Quote(recorded))); err != nil {
+		return cfg.DeliverPolicy
+	}
+	return policy
+}
+
+func describeConsumerDiffs(diffs []ConsumerConfigDiff) string {
+	parts := make([]string, 0, len(diffs))
+	for _, d := range diffs {
+		parts = append(parts, fmt.Sprintf("%s: %s -> %s", d.Field, d.Live, d.Desired))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,79 @@
+package eventbus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func TestDiffConsumerConfig(t *testing.T) {
+	desired := buildDurableConsumerConfig("backend-round-created-v1", "round.created.v1", DefaultConsumerConfig())
+
+	// The server reports the first backoff as the ack wait.
+	live := desired
+	live.AckWait = desired.BackOff[0]
+	if diffs := diffConsumerConfig(live, desired); len(diffs) != 0 {
+		t.Fatalf("expected no drift, got %+v", diffs)
+	}
+
+	live.MaxAckPending = 10
+	live.DeliverPolicy = jetstream.DeliverNewPolicy
+	diffs := diffConsumerConfig(live, desired)
+	if len(diffs) != 2 {
+		t.Fatalf("expected 2 diffs, got %+v", diffs)
+	}
+	if diffs[0] != (ConsumerConfigDiff{Field: "deliver_policy", Live: "new", Desired: "all", Mutable: false}) {
+		t.Fatalf("unexpected deliver policy diff %+v", diffs[0])
+	}
+	if diffs[1] != (ConsumerConfigDiff{Field: "max_ack_pending", Live: "10", Desired: "100", Mutable: true}) {
+		t.Fatalf("unexpected max ack pending diff %+v", diffs[1])
+	}
+}
+
+func TestDiffConsumerConfig_RecreatedConsumerMatchesDesiredPolicy(t *testing.T) {
+	desired := buildDurableConsumerConfig("backend-round-created-v1", "round.created.v1", DefaultConsumerConfig())
+
+	live := desired
+	live.DeliverPolicy = jetstream.DeliverByStartSequencePolicy
+	live.OptStartSeq = 42
+	live.Metadata = map[string]string{recreatedDeliverPolicyMetadataKey: "all"}
+	if diffs := diffConsumerConfig(live, desired); len(diffs) != 0 {
+		t.Fatalf("expected recreated consumer to match, got %+v", diffs)
+	}
+}
+
+func TestMigrationAction(t *testing.T) {
+	mutable := []ConsumerConfigDiff{{Field: "max_ack_pending", Mutable: true}}
+	immutable := append([]ConsumerConfigDiff{{Field: "deliver_policy"}}, mutable...)
+
+	cases := []struct {
+		strategy MigrationStrategy
+		diffs    []ConsumerConfigDiff
+		want     MigrationAction
+	}{
+		{MigrationFail, mutable, MigrationActionFail},
+		{MigrationUpdateInPlace, mutable, MigrationActionUpdate},
+		{MigrationUpdateInPlace, immutable, MigrationActionFail},
+		{MigrationRecreate, mutable, MigrationActionUpdate},
+		{MigrationRecreate, immutable, MigrationActionRecreate},
+	}
+	for _, tc := range cases {
+		if got := migrationAction(tc.strategy, tc.diffs); got != tc.want {
+			t.Fatalf("%s with %d diffs: expected %s, got %s", tc.strategy, len(tc.diffs), tc.want, got)
+		}
+	}
+}
+
+func TestAckWaitMatches_AcceptsConfiguredOrEffective(t *testing.T) {
+	expected := jetstream.ConsumerConfig{AckWait: 45 * time.Second, BackOff: []time.Duration{time.Second}}
+	if !ackWaitMatches(jetstream.ConsumerConfig{AckWait: time.Second}, expected) {
+		t.Fatal("expected the first backoff to match")
+	}
+	if !ackWaitMatches(jetstream.ConsumerConfig{AckWait: 45 * time.Second}, expected) {
+		t.Fatal("expected the configured ack wait to match")
+	}
+	if ackWaitMatches(jetstream.ConsumerConfig{AckWait: 10 * time.Second}, expected) {
+		t.Fatal("expected a different ack wait not to match")
+	}
+}
@@ -0,0 +1,405 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/observability/attr"
+	"github.com/ThreeDotsLabs/watermill-nats/v2/pkg/nats"
+	"github.com/ThreeDotsLabs/watermill/message"
+	nc "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	// deadLetterSubjectPrefix prefixes the original subject of every dead-lettered message.
+	// round.created.v1 is stored as dlq.round.created.v1 in the round-dlq stream.
+	deadLetterSubjectPrefix = "dlq."
+
+	// deadLetterMaxAge is how long dead letters are kept for inspection and replay.
+	deadLetterMaxAge = 14 * 24 * time.Hour
+
+	// LastErrorMetadataKey carries the most recent handler error so it can be recorded
+	// alongside the dead letter. Set by RecordLastError.
+	LastErrorMetadataKey = "last_error"
+
+	// DeadLetterMetadataKey flags messages that were dead-lettered or replayed from a DLQ.
+	DeadLetterMetadataKey = "dead_letter"
+)
+
+// Reasons a message is moved to a dead-letter stream.
+const (
+	DeadLetterReasonMaxDeliver = "max_deliver_exceeded"
+	DeadLetterReasonTerminated = "terminated"
+)
+
+// Headers added to a dead letter on top of the original message headers.
+const (
+	dlqOriginalSubjectHeader = "Dlq-Original-Subject"
+	dlqOriginalStreamHeader  = "Dlq-Original-Stream"
+	dlqOriginalSeqHeader     = "Dlq-Original-Seq"
+	dlqOriginalMsgIDHeader   = "Dlq-Original-Msg-Id"
+	dlqConsumerHeader        = "Dlq-Consumer"
+	dlqDeliveryCountHeader   = "Dlq-Delivery-Count"
+	dlqReasonHeader          = "Dlq-Reason"
+	dlqLastErrorHeader       = "Dlq-Last-Error"
+	dlqFailedAtHeader        = "Dlq-Failed-At"
+)
+
+// DeadLetter is a message that exhausted its deliveries or was terminated.
+type DeadLetter struct {
+	// Sequence is the position of the entry in the domain's DLQ stream.
+	Sequence uint64
+	Domain   string
+
+	OriginalSubject  string
+	OriginalStream   string
+	OriginalSequence uint64
+	Consumer         string
+	DeliveryCount    uint64
+	Reason           string
+	LastError        string
+	FailedAt         time.Time
+
+	// Headers are the original message headers without the DLQ bookkeeping headers.
+	Headers map[string]string
+	Payload []byte
+}
+
+// DeadLetterStreamName returns the DLQ stream name for a domain stream (e.g. "round" -> "round-dlq").
+func DeadLetterStreamName(domain string) string {
+	return domain + "-dlq"
+}
+
+// RecordLastError stores a failing handler's error in message metadata so the
+// subscriber can attach it to the dead letter if this was the final delivery.
+func RecordLastError() message.HandlerMiddleware {
+	return func(next message.HandlerFunc) message.HandlerFunc {
+		return func(msg *message.Message) ([]*message.Message, error) {
+			msgs, err := next(msg)
+			if err != nil {
+				msg.Metadata.Set(LastErrorMetadataKey, err.Error())
+			}
+			return msgs, err
+		}
+	}
+}
+
+// deadLetterQueue copies failed JetStream messages into per-domain DLQ streams.
+type deadLetterQueue struct {
+	js       jetstream.JetStream
+	logger   *slog.Logger
+	topology *StreamTopology
+
+	mu      sync.Mutex
+	streams map[string]bool
+}
+
+func newDeadLetterQueue(js jetstream.JetStream, logger *slog.Logger) *deadLetterQueue {
+	return &deadLetterQueue{
+		js:       js,
+		logger:   logger,
+		topology: DefaultStreamTopology(),
+		streams:  make(map[string]bool),
+	}
+}
+
+// ensureStream creates the DLQ stream for a domain once per process.
+func (q *deadLetterQueue) ensureStream(ctx context.Context, domain string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.streams[domain] {
+		return nil
+	}
+
+	_, err := q.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:       DeadLetterStreamName(domain),
+		Subjects:   []string{deadLetterSubjectPrefix + domain + ".>"},
+		Retention:  jetstream.LimitsPolicy,
+		Duplicates: 10 * time.Minute,
+		MaxAge:     deadLetterMaxAge,
+		MaxBytes:   1024 * 1024 * 100,
+		Discard:    jetstream.DiscardOld,
+		Storage:    jetstream.FileStorage,
+		Replicas:   1,
+		MaxMsgSize: 1024 * 1024,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create dead letter stream for %s: %w", domain, err)
+	}
+	q.streams[domain] = true
+	return nil
+}
+
+// deadLetterSource is a failed message and its delivery state, taken from a delivery or,
+// for messages that exhausted their deliveries without one, read back from the stream.
+type deadLetterSource struct {
+	subject    string
+	header     nc.Header
+	data       []byte
+	stream     string
+	streamSeq  uint64
+	consumer   string
+	deliveries uint64
+}
+
+func deadLetterSourceFromMsg(jsMsg jetstream.Msg) deadLetterSource {
+	src := deadLetterSource{subject: jsMsg.Subject(), header: jsMsg.Headers(), data: jsMsg.Data()}
+	if meta, err := jsMsg.Metadata(); err == nil {
+		src.stream = meta.Stream
+		src.streamSeq = meta.Sequence.Stream
+		src.consumer = meta.Consumer
+		src.deliveries = meta.NumDelivered
+	}
+	return src
+}
+
+// publish copies jsMsg, its delivery state and lastErr into the domain's DLQ stream.
+func (q *deadLetterQueue) publish(ctx context.Context, jsMsg jetstream.Msg, reason, lastErr string) error {
+	return q.publishSource(ctx, deadLetterSourceFromMsg(jsMsg), reason, lastErr)
+}
+
+// publishSource copies src and lastErr into the domain's DLQ stream.
+func (q *deadLetterQueue) publishSource(ctx context.Context, src deadLetterSource, reason, lastErr string) error {
+	subject := src.subject
+	domain, err := q.topology.ResolveStream(subject)
+	if err != nil {
+		return err
+	}
+	if err := q.ensureStream(ctx, domain); err != nil {
+		return err
+	}
+
+	hdr := nc.Header{}
+	for k, v := range src.header {
+		if k == nc.MsgIdHdr {
+			continue
+		}
+		hdr[k] = append([]string(nil), v...)
+	}
+	if id := src.header.Get(nc.MsgIdHdr); id != "" {
+		hdr.Set(dlqOriginalMsgIDHeader, id)
+	}
+	hdr.Set(DeadLetterMetadataKey, "true")
+	hdr.Set(dlqOriginalSubjectHeader, subject)
+	hdr.Set(dlqReasonHeader, reason)
+	hdr.Set(dlqFailedAtHeader, time.Now().UTC().Format(time.RFC3339Nano))
+	if lastErr != "" {
+		hdr.Set(dlqLastErrorHeader, lastErr)
+	}
+
+	msgID := "dlq-" + subject + "-" + src.header.Get(nc.MsgIdHdr)
+	if src.stream != "" {
+		hdr.Set(dlqOriginalStreamHeader, src.stream)
+		hdr.Set(dlqOriginalSeqHeader, strconv.FormatUint(src.streamSeq, 10))
+		hdr.Set(dlqConsumerHeader, src.consumer)
+		hdr.Set(dlqDeliveryCountHeader, strconv.FormatUint(src.deliveries, 10))
+		// The stream sequence uniquely identifies the failed message.
+		msgID = fmt.Sprintf("dlq-%s-%d", src.stream, src.streamSeq)
+	}
+
+	_, err = q.js.PublishMsg(ctx, &nc.Msg{
+		Subject: deadLetterSubjectPrefix + subject,
+		Header:  hdr,
+		Data:    src.data,
+	}, jetstream.WithMsgID(msgID))
+	if err != nil {
+		return fmt.Errorf("failed to publish dead letter for %s: %w", subject, err)
+	}
+	return nil
+}
+
+// maxDeliveriesAdvisory is the part of JetStream's max-deliveries advisory we use. The
+// server sends it when a message exceeds MaxDeliver without being settled, for example
+// because its AckWait expired on the final delivery.
+type maxDeliveriesAdvisory struct {
+	Stream     string `json:"stream"`
+	Consumer   string `json:"consumer"`
+	StreamSeq  uint64 `json:"stream_seq"`
+	Deliveries uint64 `json:"deliveries"`
+}
+
+// maxDeliveriesAdvisorySubject returns the subject of stream/consumer's max-deliveries advisories.
+func maxDeliveriesAdvisorySubject(stream, consumer string) string {
+	return "$JS.EVENT.ADVISORY.CONSUMER.MAX_DELIVERIES." + stream + "." + consumer
+}
+
+// deadLetterExhausted reads the message named by a max-deliveries advisory back from
+// its stream and dead-letters it. Messages terminated into the DLQ never raise the
+// advisory, and one dead-lettered twice is dropped by the DLQ's duplicate window.
+func (q *deadLetterQueue) deadLetterExhausted(ctx context.Context, adv maxDeliveriesAdvisory) error {
+	stream, err := q.js.Stream(ctx, adv.Stream)
+	if err != nil {
+		return fmt.Errorf("failed to access stream %s: %w", adv.Stream, err)
+	}
+	raw, err := stream.GetMsg(ctx, adv.StreamSeq)
+	if err != nil {
+		return fmt.Errorf("failed to read exhausted message %d from %s: %w", adv.StreamSeq, adv.Stream, err)
+	}
+	return q.publishSource(ctx, deadLetterSource{
+		subject:    raw.Subject,
+		header:     raw.Header,
+		data:       raw.Data,
+		stream:     adv.Stream,
+		streamSeq:  adv.StreamSeq,
+		consumer:   adv.Consumer,
+		deliveries: adv.Deliveries,
+	}, DeadLetterReasonMaxDeliver, "")
+}
+
+// isFinalDelivery reports whether this delivery is the last one JetStream will attempt.
+func isFinalDelivery(jsMsg jetstream.Msg, maxDeliver int) bool {
+	if maxDeliver <= 0 {
+		return false
+	}
+	meta, err := jsMsg.Metadata()
+	if err != nil {
+		return false
+	}
+	return meta.NumDelivered >= uint64(maxDeliver)
+}
+
+// toDeadLetter decodes a stored DLQ entry.
+func toDeadLetter(domain string, raw *jetstream.RawStreamMsg) *DeadLetter {
+	dl := &DeadLetter{
+		Sequence: raw.Sequence,
+		Domain:   domain,
+		Headers:  make(map[string]string),
+		Payload:  raw.Data,
+	}
+
+	for k, v := range raw.Header {
+		if len(v) == 0 {
+			continue
+		}
+		switch k {
+		case dlqOriginalSubjectHeader:
+			dl.OriginalSubject = v[0]
+		case dlqOriginalStreamHeader:
+			dl.OriginalStream = v[0]
+		case dlqOriginalSeqHeader:
+			dl.OriginalSequence, _ = strconv.ParseUint(v[0], 10, 64)
+		case dlqConsumerHeader:
+			dl.Consumer = v[0]
+		case dlqDeliveryCountHeader:
+			dl.DeliveryCount, _ = strconv.ParseUint(v[0], 10, 64)
+		case dlqReasonHeader:
+			dl.Reason = v[0]
+		case dlqLastErrorHeader:
+			dl.LastError = v[0]
+		case dlqFailedAtHeader:
+			dl.FailedAt, _ = time.Parse(time.RFC3339Nano, v[0])
+		case dlqOriginalMsgIDHeader:
+			dl.Headers[nc.MsgIdHdr] = v[0]
+		default:
+			dl.Headers[k] = v[0]
+		}
+	}
+
+	if dl.OriginalSubject == "" {
+		dl.OriginalSubject = strings.TrimPrefix(raw.Subject, deadLetterSubjectPrefix)
+	}
+	return dl
+}
+
+// ListDeadLetters returns up to limit dead letters for a domain, oldest first.
+// A limit of zero or less returns every entry.
+func (eb *eventBus) ListDeadLetters(ctx context.Context, domain string, limit int) ([]DeadLetter, error) {
+	stream, err := eb.js.Stream(ctx, DeadLetterStreamName(domain))
+	if err != nil {
+		if errors.Is(err, jetstream.ErrStreamNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to access dead letter stream for %s: %w", domain, err)
+	}
+
+	info, err := stream.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead letter stream info for %s: %w", domain, err)
+	}
+
+	var out []DeadLetter
+	for seq := info.State.FirstSeq; seq != 0 && seq <= info.State.LastSeq; seq++ {
+		if limit > 0 && len(out) >= limit {
+			break
+		}
+		raw, err := stream.GetMsg(ctx, seq)
+		if err != nil {
+			if errors.Is(err, jetstream.ErrMsgNotFound) {
+				continue // replayed or deleted
+			}
+			return nil, fmt.Errorf("failed to read dead letter %d for %s: %w", seq, domain, err)
+		}
+		out = append(out, *toDeadLetter(domain, raw))
+	}
+	return out, nil
+}
+
+// GetDeadLetter returns a single dead letter by its DLQ stream sequence.
+func (eb *eventBus) GetDeadLetter(ctx context.Context, domain string, seq uint64) (*DeadLetter, error) {
+	stream, err := eb.js.Stream(ctx, DeadLetterStreamName(domain))
+	if err != nil {
+		return nil, fmt.Errorf("failed to access dead letter stream for %s: %w", domain, err)
+	}
+
+	raw, err := stream.GetMsg(ctx, seq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead letter %d for %s: %w", seq, domain, err)
+	}
+	return toDeadLetter(domain, raw), nil
+}
+
+// ReplayDeadLetter republishes a dead letter onto its original subject and removes it from the DLQ.
+// The replayed message keeps its original headers and is flagged with dead_letter=true.
+func (eb *eventBus) ReplayDeadLetter(ctx context.Context, domain string, seq uint64) error {
+	ctxLogger := eb.logger.With(
+		attr.String("operation", "replay_dead_letter"),
+		attr.String("domain", domain),
+		attr.Uint64("dlq_seq", seq),
+	)
+
+	dl, err := eb.GetDeadLetter(ctx, domain, seq)
+	if err != nil {
+		return err
+	}
+
+	hdr := nc.Header{}
+	for k, v := range dl.Headers {
+		hdr.Set(k, v)
+	}
+	unmarshaler, ok := eb.marshaler.(nats.Unmarshaler)
+	if !ok {
+		unmarshaler = &nats.NATSMarshaler{}
+	}
+	msg, err := unmarshaler.Unmarshal(&nc.Msg{Subject: dl.OriginalSubject, Header: hdr, Data: dl.Payload})
+	if err != nil {
+		return fmt.Errorf("failed to decode dead letter %d: %w", seq, err)
+	}
+	msg.Metadata.Set(DeadLetterMetadataKey, "true")
+	// A fresh dedupe ID so the replay is not swallowed by the original Nats-Msg-Id.
+	msg.Metadata.Set("Nats-Msg-Id", fmt.Sprintf("replay-%s-%d", DeadLetterStreamName(domain), seq))
+	msg.SetContext(ctx)
+
+	if err := eb.publishToTopic(dl.OriginalSubject, []*message.Message{msg}); err != nil {
+		ctxLogger.ErrorContext(ctx, "Failed to replay dead letter", attr.Error(err))
+		return fmt.Errorf("failed to replay dead letter %d: %w", seq, err)
+	}
+
+	stream, err := eb.js.Stream(ctx, DeadLetterStreamName(domain))
+	if err != nil {
+		return fmt.Errorf("failed to access dead letter stream for %s: %w", domain, err)
+	}
+	if err := stream.DeleteMsg(ctx, seq); err != nil {
+		ctxLogger.WarnContext(ctx, "Replayed dead letter could not be removed", attr.Error(err))
+		return fmt.Errorf("replayed dead letter %d but failed to remove it: %w", seq, err)
+	}
+
+	ctxLogger.InfoContext(ctx, "Dead letter replayed", attr.String("original_subject", dl.OriginalSubject))
+	return nil
+}
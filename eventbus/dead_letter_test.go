@@ -0,0 +1,91 @@
+package eventbus
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	nc "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func TestIsFinalDelivery(t *testing.T) {
+	tests := []struct {
+		name         string
+		numDelivered uint64
+		maxDeliver   int
+		withMeta     bool
+		want         bool
+	}{
+		{name: "below max", numDelivered: 2, maxDeliver: 5, withMeta: true, want: false},
+		{name: "at max", numDelivered: 5, maxDeliver: 5, withMeta: true, want: true},
+		{name: "unlimited deliveries", numDelivered: 50, maxDeliver: -1, withMeta: true, want: false},
+		{name: "missing metadata", maxDeliver: 5, withMeta: false, want: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			msg := &fakeJSMsg{}
+			if tc.withMeta {
+				msg.meta = &jetstream.MsgMetadata{NumDelivered: tc.numDelivered}
+			}
+			if got := isFinalDelivery(msg, tc.maxDeliver); got != tc.want {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestToDeadLetter_SeparatesBookkeepingHeaders(t *testing.T) {
+	failedAt := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	raw := &jetstream.RawStreamMsg{
+		Subject:  "dlq.round.created.v1",
+		Sequence: 42,
+		Data:     []byte(`{}`),
+		Header: nc.Header{
+			dlqOriginalSubjectHeader: {"round.created.v1"},
+			dlqOriginalStreamHeader:  {"round"},
+			dlqOriginalSeqHeader:     {"1001"},
+			dlqOriginalMsgIDHeader:   {"idem-abc"},
+			dlqConsumerHeader:        {"backend-round-created-v1"},
+			dlqDeliveryCountHeader:   {"5"},
+			dlqReasonHeader:          {DeadLetterReasonMaxDeliver},
+			dlqLastErrorHeader:       {"db unavailable"},
+			dlqFailedAtHeader:        {failedAt.Format(time.RFC3339Nano)},
+			"correlation_id":         {"corr-1"},
+		},
+	}
+
+	dl := toDeadLetter("round", raw)
+
+	if dl.Sequence != 42 || dl.OriginalSubject != "round.created.v1" || dl.OriginalSequence != 1001 {
+		t.Fatalf("unexpected identity fields: %+v", dl)
+	}
+	if dl.DeliveryCount != 5 || dl.Reason != DeadLetterReasonMaxDeliver || dl.LastError != "db unavailable" {
+		t.Fatalf("unexpected failure fields: %+v", dl)
+	}
+	if !dl.FailedAt.Equal(failedAt) {
+		t.Fatalf("expected failed at %s, got %s", failedAt, dl.FailedAt)
+	}
+	if dl.Headers["correlation_id"] != "corr-1" || dl.Headers[nc.MsgIdHdr] != "idem-abc" {
+		t.Fatalf("expected original headers to be restored, got %v", dl.Headers)
+	}
+	if _, ok := dl.Headers[dlqReasonHeader]; ok {
+		t.Fatal("expected DLQ bookkeeping headers to be removed from Headers")
+	}
+}
+
+func TestRecordLastError_SetsMetadataOnFailure(t *testing.T) {
+	handler := RecordLastError()(func(msg *message.Message) ([]*message.Message, error) {
+		return nil, errors.New("boom")
+	})
+
+	msg := message.NewMessage("id", nil)
+	if _, err := handler(msg); err == nil {
+		t.Fatal("expected handler error to be returned")
+	}
+	if got := msg.Metadata.Get(LastErrorMetadataKey); got != "boom" {
+		t.Fatalf("expected last error metadata %q, got %q", "boom", got)
+	}
+}
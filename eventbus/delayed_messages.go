@@ -1,471 +1,399 @@
 package eventbus
 
-// import (
-// 	"context"
-// 	"errors"
-// 	"fmt"
-// 	"log/slog"
-// 	"time"
-
-// 	"github.com/Black-And-White-Club/frolf-bot-shared/observability/attr"
-// 	sharedtypes "github.com/Black-And-White-Club/frolf-bot-shared/types/shared"
-// 	"github.com/ThreeDotsLabs/watermill"
-// 	"github.com/ThreeDotsLabs/watermill/message"
-// 	"github.com/nats-io/nats.go/jetstream"
-// )
-
-// const (
-// 	GlobalDelayedConsumerName = "global_delayed_processor"
-// )
-
-// // Just delete and recreate the consumer, not the stream
-// func (eb *eventBus) StartGlobalDelayedMessageProcessor(ctx context.Context) error {
-// 	fmt.Printf("[DELAYED DEBUG] Starting efficient delayed message processor\n")
-
-// 	ctxLogger := eb.logger.With(
-// 		attr.String("operation", "start_global_delayed_processor"),
-// 		attr.String("consumer_name", GlobalDelayedConsumerName),
-// 	)
-
-// 	// Delete just the consumer (not the stream!)
-// 	if err := eb.js.DeleteConsumer(ctx, DelayedMessagesStream, GlobalDelayedConsumerName); err != nil {
-// 		fmt.Printf("[DELAYED DEBUG] Delete consumer result (expected if not exists): %v\n", err)
-// 	}
-// 	time.Sleep(1 * time.Second)
-
-// 	// Corrected consumer config with only valid fields
-// 	consumerConfig := jetstream.ConsumerConfig{
-// 		Durable:       GlobalDelayedConsumerName,
-// 		FilterSubject: DelayedMessagesSubject + ".>",
-// 		AckPolicy:     jetstream.AckExplicitPolicy,
-// 		DeliverPolicy: jetstream.DeliverAllPolicy,
-// 		MaxAckPending: 1,               // Process one at a time for timing accuracy
-// 		AckWait:       2 * time.Minute, // Reasonable timeout for processing
-// 		MaxDeliver:    3,               // Limited retries
-// 		BackOff: []time.Duration{ // Reasonable backoff
-// 			30 * time.Second,
-// 			2 * time.Minute,
-// 			5 * time.Minute,
-// 		},
-// 		InactiveThreshold: 5 * time.Minute,               // Clean up inactive consumers
-// 		ReplayPolicy:      jetstream.ReplayInstantPolicy, // Send messages as fast as possible
-// 		HeadersOnly:       false,                         // We need the full message
-// 	}
-
-// 	consumer, err := eb.js.CreateConsumer(ctx, DelayedMessagesStream, consumerConfig)
-// 	if err != nil {
-// 		ctxLogger.Error("Failed to create consumer", attr.Error(err))
-// 		return fmt.Errorf("failed to create consumer: %w", err)
-// 	}
-
-// 	// Start subscription
-// 	sub, err := consumer.Messages()
-// 	if err != nil {
-// 		ctxLogger.Error("Failed to create subscription", attr.Error(err))
-// 		return fmt.Errorf("failed to create subscription: %w", err)
-// 	}
-
-// 	// Start processor
-// 	go eb.runGlobalDelayedProcessor(ctx, sub, ctxLogger)
-
-// 	ctxLogger.Info("Efficient delayed message processor started")
-// 	fmt.Printf("[DELAYED DEBUG] Efficient processor started\n")
-// 	return nil
-// }
-
-// // Add this method to reset pending messages
-// func (eb *eventBus) ResetDelayedConsumer(ctx context.Context) error {
-// 	ctxLogger := eb.logger.With(attr.String("operation", "reset_delayed_consumer"))
-
-// 	fmt.Printf("[RESET] Resetting delayed consumer to clear pending messages\n")
-// 	ctxLogger.Info("Resetting delayed consumer")
-
-// 	// Delete the existing consumer
-// 	if err := eb.js.DeleteConsumer(ctx, DelayedMessagesStream, GlobalDelayedConsumerName); err != nil {
-// 		// It's okay if the consumer doesn't exist
-// 		fmt.Printf("[RESET] Consumer deletion result: %v\n", err)
-// 	}
-
-// 	// Wait a moment
-// 	time.Sleep(2 * time.Second)
-
-// 	// Restart the processor (which will recreate the consumer)
-// 	return eb.StartGlobalDelayedMessageProcessor(ctx)
-// }
-
-// // Much simpler and more efficient delayed message processor
-// func (eb *eventBus) runGlobalDelayedProcessor(ctx context.Context, sub jetstream.MessagesContext, ctxLogger *slog.Logger) {
-// 	fmt.Printf("[DELAYED GOROUTINE] Efficient delayed processor started\n")
-
-// 	routineLogger := ctxLogger.With(attr.String("goroutine", "global_delayed_processor"))
-// 	routineLogger.Info("Efficient delayed processor started - will block until messages are ready")
-
-// 	defer func() {
-// 		if r := recover(); r != nil {
-// 			fmt.Printf("[DELAYED GOROUTINE] PANIC recovered: %v\n", r)
-// 			routineLogger.Error("Recovered from panic in delayed processor",
-// 				attr.String("panic", fmt.Sprintf("%v", r)),
-// 			)
-// 		}
-// 		fmt.Printf("[DELAYED GOROUTINE] Processor stopping\n")
-// 		sub.Stop()
-// 	}()
-
-// 	messageCount := 0
-// 	consecutiveErrors := 0
-
-// 	for {
-// 		select {
-// 		case <-ctx.Done():
-// 			routineLogger.Info("Context canceled, stopping delayed processor")
-// 			return
-// 		default:
-// 			messageCount++
-
-// 			// EFFICIENT: Block until JetStream delivers a ready message
-// 			// No polling, no timeouts, no waste!
-// 			fmt.Printf("[DELAYED GOROUTINE] Waiting for ready message #%d...\n", messageCount)
-// 			jetStreamMsg, err := sub.Next()
-// 			if err != nil {
-// 				consecutiveErrors++
-// 				fmt.Printf("[DELAYED GOROUTINE] Error: %v (consecutive: %d)\n", err, consecutiveErrors)
-
-// 				// Handle iterator closure - restart processor
-// 				if errors.Is(err, jetstream.ErrMsgIteratorClosed) {
-// 					fmt.Printf("[DELAYED GOROUTINE] Iterator closed - restarting\n")
-// 					go func() {
-// 						time.Sleep(2 * time.Second)
-// 						eb.StartGlobalDelayedMessageProcessor(ctx)
-// 					}()
-// 					return
-// 				}
-
-// 				if errors.Is(err, context.Canceled) {
-// 					return
-// 				}
-
-// 				// Too many errors - restart
-// 				if consecutiveErrors >= 5 {
-// 					fmt.Printf("[DELAYED GOROUTINE] Too many errors - restarting\n")
-// 					go func() {
-// 						time.Sleep(5 * time.Second)
-// 						eb.StartGlobalDelayedMessageProcessor(ctx)
-// 					}()
-// 					return
-// 				}
-
-// 				// Simple backoff and continue
-// 				time.Sleep(time.Duration(consecutiveErrors) * time.Second)
-// 				continue
-// 			}
-
-// 			// Success - reset error counter
-// 			consecutiveErrors = 0
-
-// 			// Process the message (JetStream already handled the timing!)
-// 			fmt.Printf("[DELAYED GOROUTINE] Processing ready message #%d\n", messageCount)
-// 			if err := eb.processDelayedMessage(ctx, jetStreamMsg, routineLogger); err != nil {
-// 				routineLogger.Error("Failed to process message", attr.Error(err))
-// 			}
-// 		}
-// 	}
-// }
-
-// // Update processDelayedMessage to validate round still exists and is current
-// func (eb *eventBus) processDelayedMessage(ctx context.Context, jetStreamMsg jetstream.Msg, routineLogger *slog.Logger) error {
-// 	msgMetadata, err := jetStreamMsg.Metadata()
-// 	if err != nil {
-// 		jetStreamMsg.Term()
-// 		return fmt.Errorf("metadata error: %w", err)
-// 	}
-
-// 	fmt.Printf("[DELAYED MESSAGE] Processing delivery #%d of %s\n",
-// 		msgMetadata.NumDelivered, jetStreamMsg.Subject())
-
-// 	// Convert to Watermill message
-// 	wmMsg, err := eb.toWatermillMessage(ctx, jetStreamMsg)
-// 	if err != nil {
-// 		jetStreamMsg.Term()
-// 		return fmt.Errorf("conversion error: %w", err)
-// 	}
-
-// 	// Extract metadata
-// 	roundID := wmMsg.Metadata.Get("Round-ID")
-// 	executeAtStr := wmMsg.Metadata.Get("Execute-At")
-// 	originalSubject := wmMsg.Metadata.Get("Original-Subject")
-
-// 	if roundID == "" || executeAtStr == "" || originalSubject == "" {
-// 		fmt.Printf("[DELAYED MESSAGE] Missing required metadata - terminating\n")
-// 		jetStreamMsg.Term()
-// 		return fmt.Errorf("missing metadata")
-// 	}
-
-// 	// Parse execution time
-// 	executeAt, err := time.Parse(time.RFC3339, executeAtStr)
-// 	if err != nil {
-// 		fmt.Printf("[TIME DEBUG] Failed to parse time '%s': %v\n", executeAtStr, err)
-// 		jetStreamMsg.Term()
-// 		return fmt.Errorf("invalid timestamp: %w", err)
-// 	}
-
-// 	now := time.Now().UTC()
-// 	executeAtUTC := executeAt.UTC()
-// 	timeSinceExecute := now.Sub(executeAtUTC)
-// 	messageAge := now.Sub(msgMetadata.Timestamp)
-
-// 	// Debug logging
-// 	fmt.Printf("[TIME DEBUG] Raw Execute-At: '%s'\n", executeAtStr)
-// 	fmt.Printf("[TIME DEBUG] Current time: %s\n", now.Format(time.RFC3339))
-// 	fmt.Printf("[TIME DEBUG] Execute time: %s\n", executeAtUTC.Format(time.RFC3339))
-// 	fmt.Printf("[TIME DEBUG] Message age: %v\n", messageAge)
-// 	fmt.Printf("[TIME DEBUG] Time since execute: %v\n", timeSinceExecute)
-
-// 	// VALIDATION: Check if the round still exists (for very overdue messages)
-// 	if timeSinceExecute > 30*time.Minute {
-// 		fmt.Printf("[DELAYED MESSAGE] Message is %v overdue - checking if round still exists\n", timeSinceExecute)
-
-// 		// You'd need to inject your round service/DB here to check if round exists
-// 		// For now, we'll just log a warning and continue processing
-// 		routineLogger.Warn("Processing significantly overdue delayed message",
-// 			attr.String("round_id", roundID),
-// 			attr.Duration("overdue_by", timeSinceExecute),
-// 			attr.Duration("message_age", messageAge),
-// 			attr.String("original_subject", originalSubject))
-
-// 		// TODO: Add actual round existence check here if needed:
-// 		// if eb.roundDB != nil {
-// 		//     round, err := eb.roundDB.GetRound(ctx, roundID)
-// 		//     if err != nil {
-// 		//         fmt.Printf("[DELAYED MESSAGE] Round %s no longer exists - terminating message\n", roundID)
-// 		//         jetStreamMsg.Term()
-// 		//         return nil
-// 		//     }
-// 		//
-// 		//     // Check if start time changed significantly (indicating an update occurred)
-// 		//     if !round.StartTime.AsTime().Equal(executeAtUTC) {
-// 		//         fmt.Printf("[DELAYED MESSAGE] Round start time changed - terminating outdated message\n")
-// 		//         jetStreamMsg.Term()
-// 		//         return nil
-// 		//     }
-// 		// }
-// 	}
-
-// 	// Check if ready to execute
-// 	if now.Before(executeAtUTC) {
-// 		delay := executeAtUTC.Sub(now)
-// 		fmt.Printf("[DELAYED MESSAGE] Not ready - delaying for: %v\n", delay)
-
-// 		// Sanity check for unreasonably long delays
-// 		if delay > 365*24*time.Hour {
-// 			fmt.Printf("[DELAYED MESSAGE] WARNING: Extremely long delay (%v) - possible time zone issue\n", delay)
-// 			routineLogger.Warn("Extremely long delay detected",
-// 				attr.String("round_id", roundID),
-// 				attr.Duration("delay", delay),
-// 				attr.String("execute_at", executeAtStr))
-// 		}
-
-// 		if err := jetStreamMsg.NakWithDelay(delay); err != nil {
-// 			return fmt.Errorf("delay failed: %w", err)
-// 		}
-
-// 		fmt.Printf("[DELAYED MESSAGE] Delayed successfully - JetStream will redeliver in %v\n", delay)
-// 		return nil
-// 	}
-
-// 	// Ready to execute
-// 	fmt.Printf("[DELAYED MESSAGE] Executing: %s -> %s", roundID, originalSubject)
-// 	if timeSinceExecute > 0 {
-// 		fmt.Printf(" (overdue by %v)", timeSinceExecute)
-// 	}
-// 	fmt.Printf("\n")
-
-// 	msgLogger := routineLogger.With(
-// 		attr.String("round_id", roundID),
-// 		attr.String("original_subject", originalSubject),
-// 		attr.Time("execute_at", executeAtUTC),
-// 		attr.Duration("overdue_by", timeSinceExecute),
-// 	)
-
-// 	// Add execution metadata
-// 	wmMsg.Metadata.Set("Delayed-Executed-At", now.Format(time.RFC3339))
-// 	if timeSinceExecute > 0 {
-// 		wmMsg.Metadata.Set("Delayed-Overdue-By", timeSinceExecute.String())
-// 	}
-
-// 	// Ensure correlation ID exists
-// 	if wmMsg.Metadata.Get("correlation_id") == "" {
-// 		wmMsg.Metadata.Set("correlation_id", wmMsg.UUID)
-// 	}
-
-// 	// Republish to original subject
-// 	if err := eb.publisher.Publish(originalSubject, wmMsg); err != nil {
-// 		msgLogger.Error("Failed to republish delayed message", attr.Error(err))
-// 		fmt.Printf("[DELAYED MESSAGE] Failed to republish: %v\n", err)
-
-// 		// Nack for retry
-// 		if nakErr := jetStreamMsg.Nak(); nakErr != nil {
-// 			msgLogger.Error("Failed to nack message for retry", attr.Error(nakErr))
-// 		}
-// 		return fmt.Errorf("republish failed: %w", err)
-// 	}
-
-// 	// Success - ACK the message
-// 	if err := jetStreamMsg.Ack(); err != nil {
-// 		msgLogger.Error("Failed to ack processed message", attr.Error(err))
-// 		return fmt.Errorf("ack failed: %w", err)
-// 	}
-
-// 	msgLogger.Info("Delayed message executed successfully")
-// 	fmt.Printf("[DELAYED MESSAGE] Successfully executed and ACKed\n")
-// 	return nil
-// }
-
-// // RecoverScheduledRounds handles recovery after restart - now just ensures global processor is running
-// func (eb *eventBus) RecoverScheduledRounds(ctx context.Context) {
-// 	ctxLogger := eb.logger.With(attr.String("operation", "recover_scheduled_rounds"))
-// 	ctxLogger.Info("Recovery called - global processor handles all delayed messages automatically")
-
-// 	// The global processor with DeliverAllPolicy automatically handles recovery
-// 	// by processing all existing delayed messages in the stream
-// 	// No additional recovery logic needed
-// }
-
-// // Add this method for debugging
-// func (eb *eventBus) DebugDelayedMessages(ctx context.Context) error {
-// 	stream, err := eb.js.Stream(ctx, DelayedMessagesStream)
-// 	if err != nil {
-// 		return fmt.Errorf("failed to get stream: %w", err)
-// 	}
-
-// 	info, err := stream.Info(ctx)
-// 	if err != nil {
-// 		return fmt.Errorf("failed to get stream info: %w", err)
-// 	}
-
-// 	fmt.Printf("=== DELAYED STREAM DEBUG ===\n")
-// 	fmt.Printf("Total messages: %d\n", info.State.Msgs)
-// 	fmt.Printf("Total bytes: %d\n", info.State.Bytes)
-// 	fmt.Printf("First sequence: %d\n", info.State.FirstSeq)
-// 	fmt.Printf("Last sequence: %d\n", info.State.LastSeq)
-
-// 	// Check the global consumer
-// 	cons, err := eb.js.Consumer(ctx, DelayedMessagesStream, GlobalDelayedConsumerName)
-// 	if err != nil {
-// 		fmt.Printf("Global consumer not found: %v\n", err)
-// 		return err
-// 	}
-
-// 	consInfo, err := cons.Info(ctx)
-// 	if err != nil {
-// 		fmt.Printf("Failed to get consumer info: %v\n", err)
-// 		return err
-// 	}
-
-// 	fmt.Printf("\n=== GLOBAL CONSUMER DEBUG ===\n")
-// 	fmt.Printf("Consumer name: %s\n", consInfo.Name)
-// 	fmt.Printf("Delivered messages: %d\n", consInfo.Delivered.Consumer)
-// 	fmt.Printf("Acknowledged: %d\n", consInfo.AckFloor.Consumer)
-// 	fmt.Printf("Pending: %d\n", consInfo.NumPending)
-// 	fmt.Printf("Redelivered: %d\n", consInfo.NumRedelivered)
-// 	fmt.Printf("Waiting: %d\n", consInfo.NumWaiting)
-// 	fmt.Printf("Active: %v\n", consInfo.PushBound)
-
-// 	return nil
-// }
-
-// // Updated CancelScheduledMessage with more thorough cleanup
-// func (eb *eventBus) CancelScheduledMessage(ctx context.Context, roundID sharedtypes.RoundID) error {
-// 	ctxLogger := eb.logger.With(
-// 		attr.String("operation", "cancel_scheduled_message"),
-// 		attr.RoundID("round_id", roundID),
-// 		attr.String("stream", DelayedMessagesStream),
-// 	)
-
-// 	fmt.Printf("[CANCEL] Starting cancellation for round: %s\n", roundID)
-// 	ctxLogger.Info("Cancelling scheduled messages for round")
-
-// 	// Get the stream
-// 	stream, err := eb.js.Stream(ctx, DelayedMessagesStream)
-// 	if err != nil {
-// 		ctxLogger.Error("Failed to access stream", attr.Error(err))
-// 		return fmt.Errorf("failed to access stream %s: %w", DelayedMessagesStream, err)
-// 	}
-
-// 	// Subject pattern for this round's messages
-// 	subjectPattern := fmt.Sprintf("%s.%v", DelayedMessagesSubject, roundID)
-// 	fmt.Printf("[CANCEL] Purging messages with subject pattern: %s\n", subjectPattern)
-
-// 	// Purge messages for this specific round from the stream
-// 	err = stream.Purge(ctx, jetstream.WithPurgeSubject(subjectPattern))
-// 	if err != nil {
-// 		ctxLogger.Error("Failed to purge messages from stream", attr.Error(err))
-// 		return fmt.Errorf("failed to purge messages for round %v: %w", roundID, err)
-// 	}
-
-// 	fmt.Printf("[CANCEL] Successfully purged messages from stream\n")
-// 	ctxLogger.Info("Messages purged from stream",
-// 		attr.String("subject_pattern", subjectPattern))
-
-// 	// The consumer will continue processing other messages normally
-// 	// Purged messages will no longer be delivered
-
-// 	ctxLogger.Info("Cancellation completed successfully")
-// 	return nil
-// }
-
-// // ScheduleDelayedMessage schedules a message to be delivered at a future time
-// func (eb *eventBus) ScheduleDelayedMessage(ctx context.Context, originalSubject string, roundID sharedtypes.RoundID, scheduledTime sharedtypes.StartTime, payload []byte, additionalMetadata map[string]string) error {
-// 	ctxLogger := eb.logger.With(
-// 		attr.String("operation", "schedule_delayed_message"),
-// 		attr.String("original_subject", originalSubject),
-// 		attr.RoundID("round_id", roundID),
-// 		attr.Time("scheduled_time", scheduledTime.AsTime()),
-// 	)
-
-// 	ctxLogger.Info("Scheduling delayed message")
-
-// 	// Validate timing with buffer
-// 	now := time.Now().UTC()
-// 	scheduledTimeUTC := scheduledTime.AsTime().UTC()
-// 	minBuffer := 5 * time.Second
-
-// 	if !scheduledTimeUTC.After(now.Add(minBuffer)) {
-// 		ctxLogger.Warn("Scheduled time too close to current time",
-// 			attr.Time("current_time", now),
-// 			attr.Time("scheduled_time", scheduledTimeUTC),
-// 			attr.Duration("minimum_buffer", minBuffer),
-// 		)
-// 		return fmt.Errorf("scheduled time must be at least %v in the future", minBuffer)
-// 	}
-
-// 	// Create message with metadata
-// 	msg := message.NewMessage(watermill.NewUUID(), payload)
-// 	msg.Metadata.Set("Original-Subject", originalSubject)
-// 	msg.Metadata.Set("Round-ID", roundID.String())
-// 	msg.Metadata.Set("Execute-At", scheduledTimeUTC.Format(time.RFC3339))
-
-// 	// Add additional metadata
-// 	for key, value := range additionalMetadata {
-// 		msg.Metadata.Set(key, value)
-// 	}
-
-// 	// Publish to delayed messages subject
-// 	delayedSubject := fmt.Sprintf("%s.%v", DelayedMessagesSubject, roundID)
-
-// 	ctxLogger.Info("Publishing delayed message",
-// 		attr.String("message_id", msg.UUID),
-// 		attr.String("delayed_subject", delayedSubject),
-// 	)
-
-// 	if err := eb.Publish(delayedSubject, msg); err != nil {
-// 		ctxLogger.Error("Failed to publish delayed message", attr.Error(err))
-// 		return fmt.Errorf("failed to publish delayed message: %w", err)
-// 	}
-
-// 	ctxLogger.Info("Successfully scheduled delayed message",
-// 		attr.String("message_id", msg.UUID),
-// 		attr.String("delayed_subject", delayedSubject),
-// 	)
-
-// 	return nil
-// }
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/observability/attr"
+	"github.com/ThreeDotsLabs/watermill-nats/v2/pkg/nats"
+	"github.com/ThreeDotsLabs/watermill/message"
+	nc "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	// DelayedMessagesStream is the JetStream stream that holds scheduled messages until they are due.
+	DelayedMessagesStream = "delayed"
+
+	// DelayedMessagesSubject is the subject prefix for scheduled messages.
+	// Each schedule key is published on its own subject: delayed.messages.v1.<key>.
+	DelayedMessagesSubject = "delayed.messages.v1"
+
+	// GlobalDelayedConsumerName is the durable consumer shared by all scheduler replicas.
+	GlobalDelayedConsumerName = "global_delayed_processor"
+
+	// scheduledMessagesMaxAge bounds how far in the future a message can be scheduled.
+	// Rounds are routinely created weeks ahead, so this outlives the default stream retention.
+	scheduledMessagesMaxAge = 90 * 24 * time.Hour
+
+	// scheduledRepublishRetryDelay is how long to wait before retrying a failed republish.
+	scheduledRepublishRetryDelay = 5 * time.Second
+
+	// DefaultScheduledMaxAckPending is the default ack-pending ceiling of the delayed consumer.
+	DefaultScheduledMaxAckPending = 10000
+
+	// scheduledBacklogWarnRatio is the share of the ack-pending ceiling at which the
+	// processor starts warning that scheduled delivery is close to stalling.
+	scheduledBacklogWarnRatio = 0.9
+)
+
+// WithScheduledMaxAckPending sets the ack-pending ceiling of the delayed consumer.
+//
+// A scheduled message that is not yet due is parked with NakWithDelay and stays
+// ack-pending until it fires, so this bounds how many schedules can be outstanding.
+// Once that many are pending, JetStream delivers no further scheduled messages, due or
+// not, until earlier ones fire or are cancelled. Size it well above the peak number of
+// outstanding schedules; the delayed consumer's ack-pending count and ceiling are
+// exported as consumer metrics on the DelayedMessagesSubject topic.
+func WithScheduledMaxAckPending(n int) EventBusOption {
+	return func(eb *eventBus) {
+		if n > 0 {
+			eb.scheduledMaxAckPending = n
+		}
+	}
+}
+
+// Metadata keys carried by scheduled messages while they sit in the delayed stream.
+// They are stripped before the message is republished to its original subject.
+const (
+	ScheduleKeyMetadataKey     = "Schedule-Key"
+	OriginalSubjectMetadataKey = "Original-Subject"
+	ExecuteAtMetadataKey       = "Execute-At"
+	originalMsgIDMetadataKey   = "Original-Msg-Id"
+)
+
+// ScheduleAt schedules msg to be published on topic at executeAt.
+//
+// The schedule key is read from the Schedule-Key metadata and defaults to msg.UUID;
+// after the call it is always present on msg so callers can later pass it to
+// CancelScheduled. Scheduling again with the same key replaces the previous entry.
+// A past executeAt is delivered as soon as the processor picks it up.
+func (eb *eventBus) ScheduleAt(ctx context.Context, topic string, msg *message.Message, executeAt time.Time) error {
+	if msg == nil {
+		return errors.New("scheduled message is nil")
+	}
+	if topic == "" {
+		return errors.New("scheduled message topic is required")
+	}
+	if strings.HasPrefix(topic, "_INBOX.") {
+		return fmt.Errorf("scheduling to inbox topics is not supported: %s", topic)
+	}
+	if strings.HasPrefix(topic, DelayedMessagesStream+".") {
+		return fmt.Errorf("scheduling to delayed topics is not supported: %s", topic)
+	}
+	// Boundary guard for discord topics, enforced at schedule time because the
+	// processor republishes on behalf of the scheduling app.
+	if strings.HasPrefix(topic, "discord.") && eb.appType != "discord" {
+		return fmt.Errorf("scheduling to discord topics forbidden for app %q: %w", eb.appType, ErrPublishForbidden)
+	}
+
+	// Validate against the target topic now; the processor republishes without checks.
+	if err := eb.checkPublishPayload(topic, msg, eb.logger); err != nil {
+		return err
+	}
+
+	key := msg.Metadata.Get(ScheduleKeyMetadataKey)
+	if key == "" {
+		key = msg.UUID
+		msg.Metadata.Set(ScheduleKeyMetadataKey, key)
+	}
+	subject, err := scheduledSubject(key)
+	if err != nil {
+		return err
+	}
+
+	executeAtUTC := executeAt.UTC()
+	ctxLogger := eb.logger.With(
+		attr.String("operation", "schedule_at"),
+		attr.String("original_subject", topic),
+		attr.String("schedule_key", key),
+		attr.Time("execute_at", executeAtUTC),
+	)
+
+	scheduled := message.NewMessage(msg.UUID, msg.Payload)
+	for k, v := range msg.Metadata {
+		scheduled.Metadata.Set(k, v)
+	}
+	if id := scheduled.Metadata.Get("Nats-Msg-Id"); id != "" {
+		scheduled.Metadata.Set(originalMsgIDMetadataKey, id)
+	}
+	scheduled.Metadata.Set(OriginalSubjectMetadataKey, topic)
+	scheduled.Metadata.Set(ExecuteAtMetadataKey, executeAtUTC.Format(time.RFC3339Nano))
+	// Dedupe on key + execution time so a reschedule to a new time is never swallowed.
+	scheduled.Metadata.Set("Nats-Msg-Id", DedupeMsgID(key+"|"+executeAtUTC.Format(time.RFC3339Nano), subject))
+	scheduled.SetContext(ctx)
+
+	if err := eb.publishToTopic(subject, []*message.Message{scheduled}); err != nil {
+		ctxLogger.ErrorContext(ctx, "Failed to schedule message", attr.Error(err))
+		return fmt.Errorf("failed to schedule message %s: %w", key, err)
+	}
+
+	ctxLogger.InfoContext(ctx, "Message scheduled", attr.String("delayed_subject", subject))
+	return nil
+}
+
+// CancelScheduled removes a pending scheduled message by key.
+// Cancelling a key that has already fired or was never scheduled is not an error.
+func (eb *eventBus) CancelScheduled(ctx context.Context, key string) error {
+	subject, err := scheduledSubject(key)
+	if err != nil {
+		return err
+	}
+
+	ctxLogger := eb.logger.With(
+		attr.String("operation", "cancel_scheduled"),
+		attr.String("schedule_key", key),
+		attr.String("delayed_subject", subject),
+	)
+
+	stream, err := eb.js.Stream(ctx, DelayedMessagesStream)
+	if err != nil {
+		ctxLogger.ErrorContext(ctx, "Failed to access delayed stream", attr.Error(err))
+		return fmt.Errorf("failed to access stream %s: %w", DelayedMessagesStream, err)
+	}
+
+	if err := stream.Purge(ctx, jetstream.WithPurgeSubject(subject)); err != nil {
+		ctxLogger.ErrorContext(ctx, "Failed to purge scheduled message", attr.Error(err))
+		return fmt.Errorf("failed to cancel scheduled message %s: %w", key, err)
+	}
+
+	ctxLogger.InfoContext(ctx, "Scheduled message cancelled")
+	return nil
+}
+
+// ScheduleKeyToken returns the subject token a schedule key is stored under. Keys that
+// sanitize to the same token (e.g. "round.1" and "round-1") address the same entry.
+func ScheduleKeyToken(key string) (string, error) {
+	token := sanitizeForNATS(key)
+	if token == "" {
+		return "", fmt.Errorf("invalid schedule key %q", key)
+	}
+	return token, nil
+}
+
+// scheduledSubject returns the delayed stream subject for a schedule key.
+func scheduledSubject(key string) (string, error) {
+	token, err := ScheduleKeyToken(key)
+	if err != nil {
+		return "", err
+	}
+	return DelayedMessagesSubject + "." + token, nil
+}
+
+// startScheduledMessageProcessor starts the background loop that republishes due messages.
+// The loop is tied to the EventBus lifetime and stops when Close is called.
+func (eb *eventBus) startScheduledMessageProcessor(ctx context.Context) error {
+	ctxLogger := eb.logger.With(
+		attr.String("operation", "start_scheduled_message_processor"),
+		attr.String("consumer_name", GlobalDelayedConsumerName),
+	)
+
+	cons, err := eb.js.CreateOrUpdateConsumer(ctx, DelayedMessagesStream, jetstream.ConsumerConfig{
+		Durable:       GlobalDelayedConsumerName,
+		FilterSubject: DelayedMessagesSubject + ".>",
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+		AckWait:       30 * time.Second,
+		// Every "not yet due" NAK counts as a delivery, so deliveries must be unbounded.
+		MaxDeliver: -1,
+		// Waiting messages stay ack-pending until due; see WithScheduledMaxAckPending.
+		MaxAckPending: eb.scheduledMaxAckPending,
+		ReplayPolicy:  jetstream.ReplayInstantPolicy,
+	})
+	if err != nil {
+		ctxLogger.ErrorContext(ctx, "Failed to create delayed consumer", attr.Error(err))
+		return fmt.Errorf("failed to create delayed consumer: %w", err)
+	}
+
+	procCtx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	eb.schedulerCancel = cancel
+	eb.schedulerDone = done
+
+	go func() {
+		defer close(done)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			eb.reportScheduledBacklog(procCtx, cons, ctxLogger)
+		}()
+		eb.runScheduledMessageProcessor(procCtx, cons, ctxLogger)
+		wg.Wait()
+	}()
+
+	ctxLogger.InfoContext(ctx, "Scheduled message processor started")
+	return nil
+}
+
+// stopScheduledMessageProcessor stops the processor loop and waits for it to exit.
+func (eb *eventBus) stopScheduledMessageProcessor() {
+	if eb.schedulerCancel == nil {
+		return
+	}
+	eb.schedulerCancel()
+	<-eb.schedulerDone
+}
+
+func (eb *eventBus) runScheduledMessageProcessor(ctx context.Context, cons jetstream.Consumer, logger *slog.Logger) {
+	defer logger.Info("Scheduled message processor stopped")
+
+	consecutiveFetchErrors := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		fetchCtx, cancelFetch := context.WithTimeout(ctx, pullExpiry)
+		msgs, err := cons.Fetch(pullBatchSize, jetstream.FetchContext(fetchCtx))
+		if err != nil {
+			cancelFetch()
+			if ctx.Err() != nil {
+				return
+			}
+			consecutiveFetchErrors++
+			logger.WarnContext(ctx, "Delayed fetch failed", attr.Error(err))
+
+			timer := time.NewTimer(fetchErrorBackoff(consecutiveFetchErrors))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			continue
+		}
+		consecutiveFetchErrors = 0
+
+		for jsMsg := range msgs.Messages() {
+			if err := eb.processScheduledMessage(ctx, jsMsg, time.Now().UTC(), logger); err != nil {
+				logger.ErrorContext(ctx, "Failed to process scheduled message", attr.Error(err))
+			}
+		}
+		cancelFetch()
+	}
+}
+
+// reportScheduledBacklog polls the delayed consumer for its ack-pending count and
+// ceiling until ctx ends, warning as the count nears the ceiling.
+func (eb *eventBus) reportScheduledBacklog(ctx context.Context, cons jetstream.Consumer, logger *slog.Logger) {
+	ticker := time.NewTicker(consumerLagPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := cons.Info(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					logger.DebugContext(ctx, "Failed to poll delayed consumer info", attr.Error(err))
+				}
+				continue
+			}
+			limit := info.Config.MaxAckPending
+			if eb.metrics != nil {
+				eb.metrics.RecordConsumerLag(ctx, DelayedMessagesSubject, GlobalDelayedConsumerName, info.NumPending, uint64(info.NumAckPending))
+				eb.metrics.RecordConsumerMaxAckPending(ctx, DelayedMessagesSubject, GlobalDelayedConsumerName, limit)
+			}
+			if limit > 0 && float64(info.NumAckPending) >= float64(limit)*scheduledBacklogWarnRatio {
+				logger.WarnContext(ctx, "Scheduled messages near the delayed consumer's ack-pending ceiling; delivery stalls once it is reached",
+					attr.Int("ack_pending", info.NumAckPending),
+					attr.Int("max_ack_pending", limit),
+				)
+			}
+		}
+	}
+}
+
+// processScheduledMessage delays a message that is not yet due, or republishes it to its
+// original subject and acks it. Malformed messages are terminated.
+func (eb *eventBus) processScheduledMessage(ctx context.Context, jsMsg jetstream.Msg, now time.Time, logger *slog.Logger) error {
+	headers := jsMsg.Headers()
+	originalSubject := headers.Get(OriginalSubjectMetadataKey)
+	executeAtStr := headers.Get(ExecuteAtMetadataKey)
+	if originalSubject == "" || executeAtStr == "" {
+		_ = jsMsg.TermWithReason("missing schedule metadata")
+		return fmt.Errorf("scheduled message on %s is missing schedule metadata", jsMsg.Subject())
+	}
+
+	executeAt, err := time.Parse(time.RFC3339Nano, executeAtStr)
+	if err != nil {
+		_ = jsMsg.TermWithReason("invalid execute-at")
+		return fmt.Errorf("invalid execute-at %q: %w", executeAtStr, err)
+	}
+
+	if now.Before(executeAt) {
+		// Not due yet: JetStream redelivers once the delay elapses, including after restarts.
+		if err := jsMsg.NakWithDelay(executeAt.Sub(now)); err != nil {
+			return fmt.Errorf("failed to delay scheduled message: %w", err)
+		}
+		return nil
+	}
+
+	msgLogger := logger.With(
+		attr.String("original_subject", originalSubject),
+		attr.String("schedule_key", headers.Get(ScheduleKeyMetadataKey)),
+		attr.Time("execute_at", executeAt),
+		attr.Duration("overdue_by", now.Sub(executeAt)),
+	)
+
+	unmarshaler, ok := eb.marshaler.(nats.Unmarshaler)
+	if !ok {
+		unmarshaler = &nats.NATSMarshaler{}
+	}
+	wmMsg, err := unmarshaler.Unmarshal(&nc.Msg{
+		Subject: jsMsg.Subject(),
+		Header:  headers,
+		Data:    jsMsg.Data(),
+	})
+	if err != nil {
+		_ = jsMsg.TermWithReason("unmarshal failed")
+		return fmt.Errorf("failed to unmarshal scheduled message: %w", err)
+	}
+
+	originalMsgID := wmMsg.Metadata.Get(originalMsgIDMetadataKey)
+	for _, k := range []string{ScheduleKeyMetadataKey, OriginalSubjectMetadataKey, ExecuteAtMetadataKey, originalMsgIDMetadataKey, "Nats-Msg-Id"} {
+		delete(wmMsg.Metadata, k)
+	}
+	switch {
+	case originalMsgID != "":
+		wmMsg.Metadata.Set("Nats-Msg-Id", originalMsgID)
+	case wmMsg.Metadata.Get("idempotency_key") != "":
+		wmMsg.Metadata.Set("Nats-Msg-Id", DedupeMsgID(wmMsg.Metadata.Get("idempotency_key"), originalSubject))
+	default:
+		wmMsg.Metadata.Set("Nats-Msg-Id", wmMsg.UUID)
+	}
+	wmMsg.SetContext(ctx)
+
+	if err := eb.publisher.Publish(originalSubject, wmMsg); err != nil {
+		msgLogger.ErrorContext(ctx, "Failed to republish scheduled message", attr.Error(err))
+		if nakErr := jsMsg.NakWithDelay(scheduledRepublishRetryDelay); nakErr != nil {
+			msgLogger.ErrorContext(ctx, "Failed to nak scheduled message for retry", attr.Error(nakErr))
+		}
+		if eb.metrics != nil {
+			eb.metrics.RecordMessagePublishError(ctx, originalSubject)
+		}
+		return fmt.Errorf("republish to %s failed: %w", originalSubject, err)
+	}
+	if eb.metrics != nil {
+		eb.metrics.RecordMessagePublish(ctx, originalSubject)
+	}
+
+	if err := jsMsg.Ack(); err != nil {
+		// The republish is deduplicated by Nats-Msg-Id if this message is redelivered.
+		msgLogger.ErrorContext(ctx, "Failed to ack scheduled message", attr.Error(err))
+		return fmt.Errorf("failed to ack scheduled message: %w", err)
+	}
+
+	msgLogger.InfoContext(ctx, "Scheduled message published")
+	return nil
+}
@@ -0,0 +1,157 @@
+package eventbus
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill-nats/v2/pkg/nats"
+	nc "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// fakeJSMsg is a minimal jetstream.Msg that records ack outcomes.
+type fakeJSMsg struct {
+	subject string
+	headers nc.Header
+	data    []byte
+	meta    *jetstream.MsgMetadata
+
+	acked      bool
+	naked      bool
+	nakDelay   time.Duration
+	terminated bool
+}
+
+func (m *fakeJSMsg) Metadata() (*jetstream.MsgMetadata, error) {
+	if m.meta == nil {
+		return nil, jetstream.ErrNotJSMessage
+	}
+	return m.meta, nil
+}
+func (m *fakeJSMsg) Data() []byte                       { return m.data }
+func (m *fakeJSMsg) Headers() nc.Header                 { return m.headers }
+func (m *fakeJSMsg) Subject() string                    { return m.subject }
+func (m *fakeJSMsg) Reply() string                      { return "" }
+func (m *fakeJSMsg) Ack() error                         { m.acked = true; return nil }
+func (m *fakeJSMsg) DoubleAck(context.Context) error    { m.acked = true; return nil }
+func (m *fakeJSMsg) Nak() error                         { m.naked = true; return nil }
+func (m *fakeJSMsg) InProgress() error                  { return nil }
+func (m *fakeJSMsg) Term() error                        { m.terminated = true; return nil }
+func (m *fakeJSMsg) TermWithReason(reason string) error { m.terminated = true; return nil }
+func (m *fakeJSMsg) NakWithDelay(delay time.Duration) error {
+	m.naked = true
+	m.nakDelay = delay
+	return nil
+}
+
+func newScheduledFakeMsg(executeAt time.Time) *fakeJSMsg {
+	h := nc.Header{}
+	h.Set(nats.WatermillUUIDHdr, "msg-uuid")
+	h.Set(ScheduleKeyMetadataKey, "round-1-start")
+	h.Set(OriginalSubjectMetadataKey, "round.started.v1")
+	h.Set(ExecuteAtMetadataKey, executeAt.Format(time.RFC3339Nano))
+	h.Set("Nats-Msg-Id", "sched-id")
+	h.Set("correlation_id", "corr-1")
+	return &fakeJSMsg{subject: "delayed.messages.v1.round-1-start", headers: h, data: []byte(`{"round_id":"1"}`)}
+}
+
+func TestScheduledSubject_SanitizesKey(t *testing.T) {
+	got, err := scheduledSubject("round.1 start")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "delayed.messages.v1.round-1start" {
+		t.Fatalf("unexpected subject %q", got)
+	}
+
+	if _, err := scheduledSubject("*&>"); err == nil {
+		t.Fatal("expected error for key that sanitizes to empty")
+	}
+}
+
+func TestResolveStreamFromTopic_DelayedSubject(t *testing.T) {
+	stream, err := ResolveStreamFromTopic(DelayedMessagesSubject + ".round-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stream != DelayedMessagesStream {
+		t.Fatalf("expected %q stream, got %q", DelayedMessagesStream, stream)
+	}
+}
+
+func TestProcessScheduledMessage_DelaysWhenNotDue(t *testing.T) {
+	fp := &fakePublisher{}
+	eb := &eventBus{
+		publisher: fp,
+		logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		marshaler: &nats.NATSMarshaler{},
+	}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	jsMsg := newScheduledFakeMsg(now.Add(90 * time.Second))
+
+	if err := eb.processScheduledMessage(context.Background(), jsMsg, now, eb.logger); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !jsMsg.naked || jsMsg.nakDelay != 90*time.Second {
+		t.Fatalf("expected nak with 90s delay, got naked=%v delay=%s", jsMsg.naked, jsMsg.nakDelay)
+	}
+	if len(fp.calls) != 0 {
+		t.Fatalf("expected no republish before due time, got %d", len(fp.calls))
+	}
+}
+
+func TestProcessScheduledMessage_RepublishesWhenDue(t *testing.T) {
+	fp := &fakePublisher{}
+	eb := &eventBus{
+		publisher: fp,
+		logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		marshaler: &nats.NATSMarshaler{},
+	}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	jsMsg := newScheduledFakeMsg(now.Add(-time.Second))
+
+	if err := eb.processScheduledMessage(context.Background(), jsMsg, now, eb.logger); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !jsMsg.acked {
+		t.Fatal("expected scheduled message to be acked after republish")
+	}
+	if len(fp.calls) != 1 || fp.calls[0].topic != "round.started.v1" {
+		t.Fatalf("expected one republish to round.started.v1, got %+v", fp.calls)
+	}
+
+	out := fp.calls[0].msgs[0]
+	if out.UUID != "msg-uuid" {
+		t.Fatalf("expected original UUID to be preserved, got %q", out.UUID)
+	}
+	if out.Metadata.Get("Nats-Msg-Id") != "msg-uuid" {
+		t.Fatalf("expected Nats-Msg-Id derived from UUID, got %q", out.Metadata.Get("Nats-Msg-Id"))
+	}
+	if out.Metadata.Get("correlation_id") != "corr-1" {
+		t.Fatal("expected caller metadata to be preserved")
+	}
+	for _, k := range []string{ScheduleKeyMetadataKey, OriginalSubjectMetadataKey, ExecuteAtMetadataKey} {
+		if out.Metadata.Get(k) != "" {
+			t.Fatalf("expected schedule metadata %q to be stripped", k)
+		}
+	}
+}
+
+func TestProcessScheduledMessage_TerminatesWithoutMetadata(t *testing.T) {
+	eb := &eventBus{
+		publisher: &fakePublisher{},
+		logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		marshaler: &nats.NATSMarshaler{},
+	}
+	jsMsg := &fakeJSMsg{subject: "delayed.messages.v1.x", headers: nc.Header{}}
+
+	if err := eb.processScheduledMessage(context.Background(), jsMsg, time.Now(), eb.logger); err == nil {
+		t.Fatal("expected error for message without schedule metadata")
+	}
+	if !jsMsg.terminated {
+		t.Fatal("expected malformed scheduled message to be terminated")
+	}
+}
@@ -0,0 +1,43 @@
+package eventbus
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/eventbus/versioning"
+	"github.com/Black-And-White-Club/frolf-bot-shared/observability/attr"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+// WithDualPublish publishes a downcast copy of every message to the older subject
+// versions registered in registry while their migration window is open, so consumers
+// still on the old version keep working during a rollout.
+func WithDualPublish(registry *versioning.Registry) EventBusOption {
+	return func(eb *eventBus) {
+		eb.versions = registry
+	}
+}
+
+// publishDowncasts publishes old-version copies of messages that were just published to
+// topic. The new version is already on the wire, so a failure here is returned for the
+// caller to retry; the JetStream dedupe window absorbs the repeated new-version publish.
+func (eb *eventBus) publishDowncasts(topic string, messages []*message.Message, ctxLogger *slog.Logger) error {
+	byTopic := make(map[string][]*message.Message)
+	for _, msg := range messages {
+		downcasts, err := eb.versions.Downcasts(topic, msg)
+		if err != nil {
+			ctxLogger.Error("Failed to downcast message", attr.String("message_uuid", msg.UUID), attr.Error(err))
+			return err
+		}
+		for oldTopic, oldMsg := range downcasts {
+			byTopic[oldTopic] = append(byTopic[oldTopic], oldMsg)
+		}
+	}
+
+	for oldTopic, oldMessages := range byTopic {
+		if err := eb.publishToTopic(oldTopic, oldMessages); err != nil {
+			return fmt.Errorf("dual-publish %s to %s: %w", topic, oldTopic, err)
+		}
+	}
+	return nil
+}
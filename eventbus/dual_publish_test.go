@@ -0,0 +1,50 @@
+package eventbus
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/eventbus/versioning"
+	"github.com/ThreeDotsLabs/watermill-nats/v2/pkg/nats"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+func TestPublish_DualPublishesDowncastDuringMigrationWindow(t *testing.T) {
+	registry := versioning.NewRegistry()
+	if err := registry.RegisterDowncaster("round.created.v2", "round.created.v1", func(p []byte) ([]byte, error) {
+		return []byte(`{"title":"Doubles"}`), nil
+	}, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fp := &fakePublisher{}
+	eb := &eventBus{
+		appType:   "backend",
+		publisher: fp,
+		logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		marshaler: &nats.NATSMarshaler{},
+	}
+	WithDualPublish(registry)(eb)
+
+	msg := message.NewMessage("id", []byte(`{"name":"Doubles"}`))
+	msg.Metadata.Set("idempotency_key", "round-1")
+	if err := eb.Publish("round.created.v2", msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(fp.calls) != 2 {
+		t.Fatalf("expected 2 publish calls, got %d", len(fp.calls))
+	}
+	if fp.calls[1].topic != "round.created.v1" {
+		t.Fatalf("expected downcast to round.created.v1, got %s", fp.calls[1].topic)
+	}
+	old := fp.calls[1].msgs[0]
+	if string(old.Payload) != `{"title":"Doubles"}` {
+		t.Fatalf("unexpected downcast payload %s", old.Payload)
+	}
+	if got, want := old.Metadata.Get("Nats-Msg-Id"), versioning.DowncastMsgID(msg, "round.created.v1"); got != want {
+		t.Fatalf("expected downcast dedupe id %s, got %s", want, got)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/Black-And-White-Club/frolf-bot-shared/eventbus/versioning"
 	"github.com/Black-And-White-Club/frolf-bot-shared/observability/attr"
 	lokifrolfbot "github.com/Black-And-White-Club/frolf-bot-shared/observability/otel/logging"
 	eventbusmetrics "github.com/Black-And-White-Club/frolf-bot-shared/observability/otel/metrics/eventbus"
@@ -39,18 +40,61 @@ type eventBus struct {
 	marshaler         nats.Marshaler
 	metrics           eventbusmetrics.EventBusMetrics
 	tracer            trace.Tracer
+	topology          *StreamTopology
+	payloadRegistry   PayloadRegistry
+	payloadValidation PayloadValidationMode
+	versions          *versioning.Registry
+	subscribeOptions  map[string]SubscribeOptions
+
+	// breaker fails publishes fast while NATS is unreachable; spool, when configured,
+	// buffers them on disk until it recovers.
+	circuitConfig CircuitBreakerConfig
+	breaker       *circuitBreaker
+	spoolConfig   *SpoolConfig
+	spool         *diskSpool
+	spoolWake     chan struct{}
+	spoolCancel   context.CancelFunc
+	spoolDone     chan struct{}
+
+	// asyncJS is a separate JetStream context for PublishAsync, so its max-pending window
+	// is set independently of the one used for consumers.
+	asyncJS         jetstream.JetStream
+	asyncMaxPending int
+
+	// consumerConfigs and consumerManagerOpts configure the consumer manager built by NewEventBus.
+	consumerConfigs     *ConsumerConfigRegistry
+	consumerManagerOpts []ConsumerManagerOption
+
+	// Scheduled message processor lifecycle (only runs for the app that owns the delayed stream).
+	schedulerCancel context.CancelFunc
+	schedulerDone   chan struct{}
+
+	// scheduledMaxAckPending caps how many scheduled messages may wait on the delayed consumer.
+	scheduledMaxAckPending int
 }
 
 // EventBus interface
 type EventBus interface {
 	Publish(topic string, messages ...*message.Message) error
+	PublishAsync(ctx context.Context, topic string, messages ...*message.Message) ([]*PublishFuture, error)
+	PublishBatch(ctx context.Context, topic string, messages ...*message.Message) ([]PublishAck, error)
 	Subscribe(ctx context.Context, topic string) (<-chan *message.Message, error)
+	SubscribeWithOptions(ctx context.Context, topic string, opts SubscribeOptions) (<-chan *message.Message, error)
+	PauseSubscription(topic string) error
+	ResumeSubscription(topic string) error
+	UpdateConsumerConfig(ctx context.Context, topic string, cfg ConsumerConfig) error
 	Close() error
+	Shutdown(ctx context.Context) error
 	GetNATSConnection() *nc.Conn
 	GetJetStream() jetstream.JetStream
 	GetHealthCheckers() []HealthChecker
 	CreateStream(ctx context.Context, streamName string) error
 	SubscribeForTest(ctx context.Context, topic string) (<-chan *message.Message, error)
+	ScheduleAt(ctx context.Context, topic string, msg *message.Message, executeAt time.Time) error
+	CancelScheduled(ctx context.Context, key string) error
+	ListDeadLetters(ctx context.Context, domain string, limit int) ([]DeadLetter, error)
+	GetDeadLetter(ctx context.Context, domain string, seq uint64) (*DeadLetter, error)
+	ReplayDeadLetter(ctx context.Context, domain string, seq uint64) error
 }
 
 // HealthChecker interface for components that can be health-checked
@@ -59,7 +103,49 @@ type HealthChecker interface {
 	Name() string
 }
 
-func NewEventBus(ctx context.Context, natsURL string, logger *slog.Logger, appType string, metrics eventbusmetrics.EventBusMetrics, tracer trace.Tracer) (EventBus, error) {
+// EventBusOption configures optional EventBus behavior.
+type EventBusOption func(*eventBus)
+
+// WithStreamTopology overrides the stream topology used for stream creation and
+// topic → stream resolution. Defaults to DefaultStreamTopology().
+func WithStreamTopology(topology *StreamTopology) EventBusOption {
+	return func(eb *eventBus) {
+		if topology != nil {
+			eb.topology = topology
+		}
+	}
+}
+
+// WithSubscribeOptions sets the SubscribeOptions that Subscribe uses for topic, so
+// routers that only call Subscribe can still tune batching, concurrency and ordering.
+func WithSubscribeOptions(topic string, opts SubscribeOptions) EventBusOption {
+	return func(eb *eventBus) {
+		if eb.subscribeOptions == nil {
+			eb.subscribeOptions = make(map[string]SubscribeOptions)
+		}
+		eb.subscribeOptions[topic] = opts
+	}
+}
+
+// WithConsumerConfigRegistry sets the registry that durable consumers are created and
+// reconciled from. Defaults to NewConsumerConfigRegistry().
+func WithConsumerConfigRegistry(registry *ConsumerConfigRegistry) EventBusOption {
+	return func(eb *eventBus) {
+		if registry != nil {
+			eb.consumerConfigs = registry
+		}
+	}
+}
+
+// WithConsumerMigrationStrategy sets how durable consumers whose live config has drifted
+// from the registry are reconciled at subscribe time. Defaults to MigrationUpdateInPlace.
+func WithConsumerMigrationStrategy(strategy MigrationStrategy) EventBusOption {
+	return func(eb *eventBus) {
+		eb.consumerManagerOpts = append(eb.consumerManagerOpts, WithMigrationStrategy(strategy))
+	}
+}
+
+func NewEventBus(ctx context.Context, natsURL string, logger *slog.Logger, appType string, metrics eventbusmetrics.EventBusMetrics, tracer trace.Tracer, opts ...EventBusOption) (EventBus, error) {
 	ctxLogger := logger.With(
 		"operation", "new_event_bus",
 		"nats_url", natsURL,
@@ -95,7 +181,9 @@ func NewEventBus(ctx context.Context, natsURL string, logger *slog.Logger, appTy
 		JetStream: nats.JetStreamConfig{
 			Disabled:      false,
 			AutoProvision: false,
-			TrackMsgId:    true,
+			// publishToTopic sets Nats-Msg-Id itself (derived from idempotency_key when
+			// present). TrackMsgId would overwrite it with the watermill UUID and defeat dedupe.
+			TrackMsgId:    false,
 			DurablePrefix: "durable",
 			DurableCalculator: func(durablePrefix, topic string) string {
 				sanitizedTopic := strings.ReplaceAll(topic, ".", "_")
@@ -115,37 +203,62 @@ func NewEventBus(ctx context.Context, natsURL string, logger *slog.Logger, appTy
 		return nil, fmt.Errorf("failed to create Watermill publisher: %w", err)
 	}
 
-	// Create consumer config registry with production defaults
-	registry := NewConsumerConfigRegistry()
+	eventBus := &eventBus{
+		appType:         appType,
+		publisher:       publisher,
+		sharedPublisher: true,
+		subscriber:      nil, // No longer using Watermill subscriber
+		js:              js,
+		natsConn:        natsConn,
+		logger:          logger,
+		createdStreams:  make(map[string]bool),
+		streamMutex:     sync.Mutex{},
+		marshaler:       marshaller,
+		metrics:         metrics,
+		tracer:          tracer,
+		topology:        DefaultStreamTopology(),
+		consumerConfigs: NewConsumerConfigRegistry(),
+		asyncMaxPending: defaultPublishAsyncMaxPending,
+
+		scheduledMaxAckPending: DefaultScheduledMaxAckPending,
+	}
+	for _, opt := range opts {
+		opt(eventBus)
+	}
+
+	eventBus.asyncJS, err = jetstream.New(natsConn,
+		jetstream.WithPublishAsyncMaxPending(eventBus.asyncMaxPending),
+		jetstream.WithPublishAsyncTimeout(publishAsyncAckTimeout),
+	)
+	if err != nil {
+		natsConn.Close()
+		ctxLogger.ErrorContext(ctx, "Failed to initialize async JetStream publisher", "error", err)
+		return nil, fmt.Errorf("failed to initialize async JetStream publisher: %w", err)
+	}
+
+	eventBus.breaker = newCircuitBreaker(eventBus.circuitConfig, eventBus.onCircuitStateChange)
+	if eventBus.spoolConfig != nil {
+		if err := eventBus.startSpool(); err != nil {
+			natsConn.Close()
+			ctxLogger.ErrorContext(ctx, "Failed to open publish spool", "error", err)
+			return nil, fmt.Errorf("failed to open publish spool: %w", err)
+		}
+	}
 
 	// Create consumer manager for managing JetStream consumers
-	consumerManager := NewConsumerManager(js, logger, registry)
+	eventBus.consumerManager = NewConsumerManager(js, logger, eventBus.consumerConfigs, eventBus.consumerManagerOpts...)
 
 	// Create the native JetStream subscriber adapter
-	subscriberAdapter := NewJetStreamSubscriberAdapter(
-		js,
-		consumerManager,
-		appType,
-		logger,
+	adapterOpts := []JetStreamSubscriberOption{
 		WithMaxConcurrentAcks(50),
-	)
-
-	eventBus := &eventBus{
-		appType:           appType,
-		publisher:         publisher,
-		sharedPublisher:   true,
-		subscriber:        nil, // No longer using Watermill subscriber
-		subscriberAdapter: subscriberAdapter,
-		consumerManager:   consumerManager,
-		js:                js,
-		natsConn:          natsConn,
-		logger:            logger,
-		createdStreams:    make(map[string]bool),
-		streamMutex:       sync.Mutex{},
-		marshaler:         marshaller,
-		metrics:           metrics,
-		tracer:            tracer,
+		WithSubscriberStreamTopology(eventBus.topology),
+		WithSubscriberTracer(tracer),
+		WithSubscriberMetrics(metrics),
 	}
+	for topic, subOpts := range eventBus.subscribeOptions {
+		adapterOpts = append(adapterOpts, WithTopicSubscribeOptions(topic, subOpts))
+	}
+	eventBus.subscriberAdapter = NewJetStreamSubscriberAdapter(js, eventBus.consumerManager, appType, logger, adapterOpts...)
 
 	if err := eventBus.createStreamsForApp(ctx, appType); err != nil {
 		natsConn.Close()
@@ -153,6 +266,14 @@ func NewEventBus(ctx context.Context, natsURL string, logger *slog.Logger, appTy
 		return nil, fmt.Errorf("failed to create streams for app: %w", err)
 	}
 
+	if eventBus.createdStreams[DelayedMessagesStream] {
+		if err := eventBus.startScheduledMessageProcessor(ctx); err != nil {
+			natsConn.Close()
+			ctxLogger.ErrorContext(ctx, "Failed to start scheduled message processor", "error", err)
+			return nil, fmt.Errorf("failed to start scheduled message processor: %w", err)
+		}
+	}
+
 	ctxLogger.InfoContext(ctx, "EventBus created successfully")
 	return eventBus, nil
 }
@@ -176,7 +297,7 @@ func (eb *eventBus) Publish(topic string, messages ...*message.Message) error {
 	// Group messages by their metadata topic for correct routing
 	topicGroups := make(map[string][]*message.Message)
 	for _, msg := range messages {
-		msgTopic := resolveMessageTopic(msg)
+		msgTopic := ResolveMessageTopic(msg)
 		if msgTopic == "" {
 			return fmt.Errorf("message %s has no topic in metadata", msg.UUID)
 		}
@@ -197,8 +318,9 @@ func (eb *eventBus) Publish(topic string, messages ...*message.Message) error {
 	return nil
 }
 
-// resolveMessageTopic extracts the topic from message metadata.
-func resolveMessageTopic(msg *message.Message) string {
+// ResolveMessageTopic extracts the topic from message metadata, as used by Publish
+// when no explicit topic is given.
+func ResolveMessageTopic(msg *message.Message) string {
 	for _, key := range []string{"topic", "Topic", "event_name", "topic_hint"} {
 		if t := msg.Metadata.Get(key); t != "" {
 			return t
@@ -213,9 +335,8 @@ func (eb *eventBus) publishToTopic(topic string, messages []*message.Message) er
 		return nil
 	}
 
-	// Boundary guard for discord topics
-	if strings.HasPrefix(topic, "discord.") && eb.appType != "discord" {
-		return fmt.Errorf("publishing to discord topics forbidden for app %q", eb.appType)
+	if err := eb.checkPublishBoundary(topic); err != nil {
+		return err
 	}
 
 	ctxLogger := eb.logger.With(
@@ -231,45 +352,33 @@ func (eb *eventBus) publishToTopic(topic string, messages []*message.Message) er
 			ctxLogger.Error("Inbox publish forbidden", "error", err, "app_type", eb.appType)
 			return err
 		}
-		return eb.publishToInbox(topic, messages, ctxLogger)
+		endSpans := eb.startPublishSpans(topic, messages)
+		err := eb.publishToInbox(topic, messages, ctxLogger)
+		endSpans(err)
+		return err
 	}
 
-	// Set deduplication IDs
-	for _, msg := range messages {
-		if msg.Metadata.Get("Nats-Msg-Id") == "" {
-			if key := msg.Metadata.Get("idempotency_key"); key != "" {
-				msg.Metadata.Set("Nats-Msg-Id", dedupeMsgID(key, topic))
-			} else {
-				msg.Metadata.Set("Nats-Msg-Id", msg.UUID)
-			}
-		}
-		ctxLogger.Debug("Publishing message",
-			attr.String("message_uuid", msg.UUID),
-			attr.String("nats_msg_id", msg.Metadata.Get("Nats-Msg-Id")),
-		)
+	if err := eb.preparePublish(topic, messages, ctxLogger); err != nil {
+		return err
 	}
 
 	if eb.metrics != nil {
 		eb.metrics.RecordMessagePublish(messages[0].Context(), topic)
 	}
 
-	// Publish with retry
-	const maxRetries = 3
-	var lastErr error
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		if err := eb.publisher.Publish(topic, messages...); err != nil {
-			lastErr = err
-			if attempt < maxRetries && eb.isRetryableError(err) {
-				time.Sleep(time.Duration(attempt) * 100 * time.Millisecond)
-				continue
-			}
-			if eb.metrics != nil {
-				eb.metrics.RecordMessagePublishError(messages[0].Context(), topic)
-			}
-			ctxLogger.Error("Publish failed", "error", err, "attempts", attempt)
-			return fmt.Errorf("publish to %s failed after %d attempts: %w", topic, attempt, lastErr)
-		}
-		break
+	endSpans := eb.startPublishSpans(topic, messages)
+
+	// Queue behind already spooled messages to keep publish order; otherwise publish
+	// with retry and spool if JetStream turns out to be unreachable.
+	var err error
+	if eb.shouldSpool() {
+		err = eb.spoolMessages(topic, messages, ctxLogger)
+	} else if err = eb.publishWithRetry(topic, messages, ctxLogger); err != nil && eb.canSpool(err) {
+		err = eb.spoolMessages(topic, messages, ctxLogger)
+	}
+	endSpans(err)
+	if err != nil {
+		return err
 	}
 
 	for _, msg := range messages {
@@ -279,6 +388,50 @@ func (eb *eventBus) publishToTopic(topic string, messages []*message.Message) er
 		)
 	}
 
+	if eb.versions != nil {
+		return eb.publishDowncasts(topic, messages, ctxLogger)
+	}
+
+	return nil
+}
+
+// ErrPublishForbidden is wrapped by errors for topics the app may not publish or
+// schedule to. Retrying such a publish cannot succeed.
+var ErrPublishForbidden = errors.New("topic outside the app's publish boundary")
+
+// checkPublishBoundary rejects topics this app may not publish to.
+func (eb *eventBus) checkPublishBoundary(topic string) error {
+	// Boundary guard for discord topics
+	if strings.HasPrefix(topic, "discord.") && eb.appType != "discord" {
+		return fmt.Errorf("publishing to discord topics forbidden for app %q: %w", eb.appType, ErrPublishForbidden)
+	}
+	return nil
+}
+
+// preparePublish validates payloads and sets the Nats-Msg-Id JetStream dedupes on.
+func (eb *eventBus) preparePublish(topic string, messages []*message.Message, ctxLogger *slog.Logger) error {
+	if eb.payloadRegistry != nil {
+		for _, msg := range messages {
+			if err := eb.checkPublishPayload(topic, msg, ctxLogger); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Set deduplication IDs
+	for _, msg := range messages {
+		if msg.Metadata.Get("Nats-Msg-Id") == "" {
+			if key := msg.Metadata.Get("idempotency_key"); key != "" {
+				msg.Metadata.Set("Nats-Msg-Id", DedupeMsgID(key, topic))
+			} else {
+				msg.Metadata.Set("Nats-Msg-Id", msg.UUID)
+			}
+		}
+		ctxLogger.Debug("Publishing message",
+			attr.String("message_uuid", msg.UUID),
+			attr.String("nats_msg_id", msg.Metadata.Get("Nats-Msg-Id")),
+		)
+	}
 	return nil
 }
 
@@ -291,8 +444,14 @@ func (eb *eventBus) publishToInbox(topic string, messages []*message.Message, ct
 			attr.String("inbox", topic),
 		)
 
-		// Publish directly via core NATS connection
-		if err := eb.natsConn.Publish(topic, msg.Payload); err != nil {
+		// Publish directly via core NATS connection, keeping metadata as headers so
+		// requesters can read reply_topic and correlation metadata.
+		natsMsg, err := eb.marshaler.Marshal(topic, msg)
+		if err != nil {
+			ctxLogger.Error("Failed to marshal inbox message", "error", err)
+			return fmt.Errorf("failed to marshal inbox message for %s: %w", topic, err)
+		}
+		if err := eb.natsConn.PublishMsg(natsMsg); err != nil {
 			ctxLogger.Error("Failed to publish to inbox", "error", err)
 			return fmt.Errorf("failed to publish to inbox %s: %w", topic, err)
 		}
@@ -308,7 +467,7 @@ func (eb *eventBus) publishToInbox(topic string, messages []*message.Message, ct
 
 func (eb *eventBus) validateInboxPublish(topic string, messages []*message.Message) error {
 	if eb.appType != "backend" {
-		return fmt.Errorf("publishing to inbox topics forbidden for app %q", eb.appType)
+		return fmt.Errorf("publishing to inbox topics forbidden for app %q: %w", eb.appType, ErrPublishForbidden)
 	}
 	if !strings.HasPrefix(topic, "_INBOX.") {
 		return fmt.Errorf("invalid inbox topic %q", topic)
@@ -325,7 +484,8 @@ func (eb *eventBus) validateInboxPublish(topic string, messages []*message.Messa
 	return nil
 }
 
-func dedupeMsgID(idempotencyKey, topic string) string {
+// DedupeMsgID derives the Nats-Msg-Id for a message carrying an idempotency_key.
+func DedupeMsgID(idempotencyKey, topic string) string {
 	// Include topic to avoid collisions across subjects within the same stream.
 	seed := topic + "|" + idempotencyKey
 	sum := sha256.Sum256([]byte(seed))
@@ -333,8 +493,26 @@ func dedupeMsgID(idempotencyKey, topic string) string {
 }
 
 // Subscribe subscribes to a topic using the native JetStream subscriber adapter.
-// It provides bounded ACK concurrency and graceful shutdown support.
+// It provides bounded ACK concurrency and graceful shutdown support, tuned by any
+// options registered for the topic with WithSubscribeOptions.
 func (eb *eventBus) Subscribe(ctx context.Context, topic string) (<-chan *message.Message, error) {
+	if err := eb.prepareSubscribe(ctx, topic); err != nil {
+		return nil, err
+	}
+	return eb.subscriberAdapter.Subscribe(ctx, topic)
+}
+
+// SubscribeWithOptions subscribes to a topic like Subscribe, using opts instead of the
+// options registered for the topic.
+func (eb *eventBus) SubscribeWithOptions(ctx context.Context, topic string, opts SubscribeOptions) (<-chan *message.Message, error) {
+	if err := eb.prepareSubscribe(ctx, topic); err != nil {
+		return nil, err
+	}
+	return eb.subscriberAdapter.SubscribeWithOptions(ctx, topic, opts)
+}
+
+// prepareSubscribe enforces subscription boundaries and ensures the topic's stream exists.
+func (eb *eventBus) prepareSubscribe(ctx context.Context, topic string) error {
 	ctxLogger := eb.logger.With(
 		"operation", "subscribe",
 		"topic", topic,
@@ -350,23 +528,22 @@ func (eb *eventBus) Subscribe(ctx context.Context, topic string) (<-chan *messag
 			"app_type", eb.appType,
 			"topic", topic,
 		)
-		return nil, fmt.Errorf("subscription to discord topics forbidden for app %q", eb.appType)
+		return fmt.Errorf("subscription to discord topics forbidden for app %q", eb.appType)
 	}
 
 	// Resolve stream from topic and ensure it exists
-	streamName, err := ResolveStreamFromTopic(topic)
+	streamName, err := eb.streamTopology().ResolveStream(topic)
 	if err != nil {
 		ctxLogger.ErrorContext(ctx, "Failed to resolve stream from topic", "error", err)
-		return nil, err
+		return err
 	}
 
 	if err := eb.CreateStream(ctx, streamName); err != nil {
 		ctxLogger.ErrorContext(ctx, "Failed to create stream", "error", err, "stream", streamName)
-		return nil, err
+		return err
 	}
 
-	// Delegate to the subscriber adapter
-	return eb.subscriberAdapter.Subscribe(ctx, topic)
+	return nil
 }
 
 // SubscribeForTest creates an ephemeral (non-durable) subscription for testing.
@@ -378,7 +555,7 @@ func (eb *eventBus) SubscribeForTest(ctx context.Context, topic string) (<-chan
 		return nil, fmt.Errorf("subscription to discord topics forbidden for app %q", eb.appType)
 	}
 
-	streamName, err := ResolveStreamFromTopic(topic)
+	streamName, err := eb.streamTopology().ResolveStream(topic)
 	if err != nil {
 		return nil, err
 	}
@@ -475,57 +652,11 @@ func sanitizeForNATS(s string) string {
 	return reg.ReplaceAllString(strings.ReplaceAll(s, ".", "-"), "")
 }
 
-// Close closes the EventBus and its components.
-// It closes the subscriber adapter first to allow graceful drain of in-flight messages,
-// then the publisher, and finally drains the NATS connection.
+// Close shuts the EventBus down, giving in-flight handlers up to defaultShutdownTimeout.
 func (eb *eventBus) Close() error {
-	ctxLogger := eb.logger.With(attr.String("operation", "close"))
-
-	ctxLogger.Info("Closing EventBus")
-
-	// Close subscriber adapter first to gracefully drain in-flight messages
-	if eb.subscriberAdapter != nil {
-		if err := eb.subscriberAdapter.Close(); err != nil {
-			ctxLogger.Error("Error closing subscriber adapter", "component", "subscriber_adapter", "error", err)
-		} else {
-			ctxLogger.Info("Subscriber adapter closed successfully", "component", "subscriber_adapter")
-		}
-	}
-
-	// Close legacy subscriber if present (for backward compatibility)
-	if eb.subscriber != nil {
-		if err := eb.subscriber.Close(); err != nil {
-			ctxLogger.Error("Error closing subscriber", "component", "subscriber", "error", err)
-		} else {
-			ctxLogger.Info("Subscriber closed successfully", "component", "subscriber")
-		}
-	}
-
-	// Close publisher only when it owns a separate NATS connection.
-	if eb.publisher != nil {
-		if eb.sharedPublisher {
-			ctxLogger.Debug("Skipping publisher close; using shared NATS connection", "component", "publisher")
-		} else {
-			if err := eb.publisher.Close(); err != nil {
-				ctxLogger.Error("Error closing publisher", "component", "publisher", "error", err)
-			} else {
-				ctxLogger.Info("Publisher closed successfully", "component", "publisher")
-			}
-		}
-	}
-
-	// Drain and close NATS connection
-	if eb.natsConn != nil {
-		// Drain allows in-flight messages to complete
-		if err := eb.natsConn.Drain(); err != nil {
-			ctxLogger.Warn("Error draining NATS connection, forcing close", "error", err)
-			eb.natsConn.Close()
-		}
-		ctxLogger.Info("NATS connection closed", "component", "nats_connection")
-	}
-
-	ctxLogger.Info("EventBus closed successfully")
-	return nil
+	ctx, cancel := context.WithTimeout(context.Background(), defaultShutdownTimeout)
+	defer cancel()
+	return eb.Shutdown(ctx)
 }
 
 // CreateStream creates or updates a single JetStream stream (helper function).
@@ -544,47 +675,16 @@ func (eb *eventBus) CreateStream(ctx context.Context, streamName string) error {
 	}
 	eb.streamMutex.Unlock()
 
-	var subjects []string
-	switch streamName {
-	case "user":
-		subjects = []string{"user.>"}
-	case "leaderboard":
-		subjects = []string{"leaderboard.>"}
-	case "round":
-		subjects = []string{"round.>"}
-	case "score":
-		subjects = []string{"score.>"}
-	case "discord":
-		subjects = []string{"discord.>"}
-	case "guild":
-		subjects = []string{"guild.>"}
-	case "auth":
-		subjects = []string{"auth.>"}
-	case "club":
-		subjects = []string{"club.>"}
-	default:
+	def, ok := eb.streamTopology().Get(streamName)
+	if !ok {
 		ctxLogger.Error("Failed to create stream", "error", "unknown stream name")
 		return fmt.Errorf("unknown stream name: %s", streamName)
 	}
 
-	ctxLogger = ctxLogger.With(attr.Any("subjects", subjects))
+	ctxLogger = ctxLogger.With(attr.Any("subjects", def.Subjects))
 
-	// Define stream configuration with better durability and deduplication
-	streamCfg := jetstream.StreamConfig{
-		Name:         streamName,
-		Subjects:     subjects,
-		Retention:    jetstream.LimitsPolicy,
-		Duplicates:   10 * time.Minute,      // Extended deduplication window
-		MaxAge:       24 * time.Hour,        // Keep messages for 24 hours
-		MaxBytes:     1024 * 1024 * 100,     // 100MB max stream size
-		MaxMsgs:      100000,                // Max 100k messages
-		NoAck:        false,                 // Require acknowledgments
-		Discard:      jetstream.DiscardOld,  // Discard old messages when limits hit
-		Storage:      jetstream.FileStorage, // Persistent storage
-		Replicas:     1,                     // Single replica for now
-		MaxConsumers: 100,                   // Prevent unbounded consumer growth
-		MaxMsgSize:   1024 * 1024,           // 1MB max message size
-	}
+	// Stream limits and durability come from the topology definition.
+	streamCfg := def.StreamConfig()
 
 	ctxLogger = ctxLogger.With(attr.Duration("duplicates_window", streamCfg.Duplicates))
 
@@ -612,15 +712,10 @@ func (eb *eventBus) createStreamsForApp(ctx context.Context, appType string) err
 	ctxLogger.Info("Creating streams for application type")
 
 	var streams []string
-	switch appType {
-	case "backend":
-		streams = []string{"user", "leaderboard", "round", "score", "guild", "auth", "club"}
-	case "discord":
-		// Discord creates its own internal stream.
-		// It will subscribe to backend streams (user, guild, auth, etc) which backend creates.
-		// We avoid creating shared streams here to prevent ambiguity and ensure Backend owns the domain streams.
-		streams = []string{"discord"}
-	default:
+	for _, def := range eb.streamTopology().StreamsForApp(appType) {
+		streams = append(streams, def.Name)
+	}
+	if len(streams) == 0 {
 		ctxLogger.Error("Failed to create streams for app", "error", "unknown app type")
 		return fmt.Errorf("unknown app type: %s", appType)
 	}
@@ -642,6 +737,14 @@ func (eb *eventBus) createStreamsForApp(ctx context.Context, appType string) err
 	return nil
 }
 
+// streamTopology returns the configured topology, falling back to the default.
+func (eb *eventBus) streamTopology() *StreamTopology {
+	if eb.topology != nil {
+		return eb.topology
+	}
+	return DefaultStreamTopology()
+}
+
 // GetNATSConnection returns the underlying NATS connection.
 func (eb *eventBus) GetNATSConnection() *nc.Conn {
 	return eb.natsConn
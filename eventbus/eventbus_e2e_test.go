@@ -0,0 +1,819 @@
+package eventbus_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/eventbus"
+	"github.com/Black-And-White-Club/frolf-bot-shared/eventbus/natstest"
+	"github.com/Black-And-White-Club/frolf-bot-shared/utils"
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const e2eTimeout = 15 * time.Second
+
+func TestEventBus_PublishSubscribeAck(t *testing.T) {
+	srv := natstest.RunServer(t)
+	bus := srv.NewEventBus(eventbus.AppTypeBackend)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := bus.Subscribe(ctx, "round.created.v1")
+	if err != nil {
+		t.Fatalf("unexpected subscribe error: %v", err)
+	}
+
+	msg := message.NewMessage(watermill.NewUUID(), []byte(`{"round_id":"r"}`))
+	msg.Metadata.Set("idempotency_key", "round-r")
+	if err := bus.Publish("round.created.v1", msg); err != nil {
+		t.Fatalf("unexpected publish error: %v", err)
+	}
+	// Same idempotency key is dropped by the stream's duplicate window.
+	dup := message.NewMessage(watermill.NewUUID(), []byte(`{"round_id":"r"}`))
+	dup.Metadata.Set("idempotency_key", "round-r")
+	if err := bus.Publish("round.created.v1", dup); err != nil {
+		t.Fatalf("unexpected publish error: %v", err)
+	}
+
+	got := natstest.AwaitMessage(t, ch, e2eTimeout)
+	if string(got.Payload) != `{"round_id":"r"}` || got.Metadata.Get("_js_stream") != "round" {
+		t.Fatalf("unexpected message %s %v", got.Payload, got.Metadata)
+	}
+	got.Ack()
+	natstest.ExpectNoMessage(t, ch, 500*time.Millisecond)
+
+	if info := srv.StreamInfo("round"); info.State.Msgs != 1 {
+		t.Fatalf("expected 1 message in round stream, got %d", info.State.Msgs)
+	}
+
+	consumer := got.Metadata.Get("_js_consumer")
+	if consumer != "backend-round-created-v1" {
+		t.Fatalf("unexpected consumer name %q", consumer)
+	}
+	deadline := time.Now().Add(e2eTimeout)
+	for {
+		info := srv.ConsumerInfo("round", consumer)
+		if info.NumAckPending == 0 && info.Delivered.Consumer == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected message to be acked, consumer state %+v", info)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// Regression test for TrackMsgId: with it on, the NATS publisher overwrote the
+// Nats-Msg-Id derived from idempotency_key with each message's UUID, so retried
+// publishes of the same event were all stored.
+func TestEventBus_PublishDedupesOnIdempotencyKey(t *testing.T) {
+	srv := natstest.RunServer(t)
+	bus := srv.NewEventBus(eventbus.AppTypeBackend)
+
+	for i := 0; i < 2; i++ {
+		msg := message.NewMessage(watermill.NewUUID(), []byte(`{"round_id":"r"}`))
+		msg.Metadata.Set("idempotency_key", "round-r")
+		if err := bus.Publish("round.created.v1", msg); err != nil {
+			t.Fatalf("unexpected publish error: %v", err)
+		}
+	}
+
+	if info := srv.StreamInfo("round"); info.State.Msgs != 1 {
+		t.Fatalf("expected the duplicate to be dropped, got %d messages in round stream", info.State.Msgs)
+	}
+
+	stream, err := srv.JetStream().Stream(context.Background(), "round")
+	if err != nil {
+		t.Fatalf("unexpected stream error: %v", err)
+	}
+	stored, err := stream.GetLastMsgForSubject(context.Background(), "round.created.v1")
+	if err != nil {
+		t.Fatalf("unexpected get error: %v", err)
+	}
+	if got, want := stored.Header.Get("Nats-Msg-Id"), eventbus.DedupeMsgID("round-r", "round.created.v1"); got != want {
+		t.Fatalf("expected Nats-Msg-Id %s derived from the idempotency key, got %s", want, got)
+	}
+}
+
+func TestEventBus_SubscriptionSurvivesServerRestart(t *testing.T) {
+	srv := natstest.RunServer(t)
+	bus := srv.NewEventBus(eventbus.AppTypeBackend)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := bus.Subscribe(ctx, "user.created.v1")
+	if err != nil {
+		t.Fatalf("unexpected subscribe error: %v", err)
+	}
+	if err := bus.Publish("user.created.v1", message.NewMessage(watermill.NewUUID(), []byte(`{"n":1}`))); err != nil {
+		t.Fatalf("unexpected publish error: %v", err)
+	}
+	natstest.AwaitMessage(t, ch, e2eTimeout).Ack()
+
+	srv.Restart()
+
+	deadline := time.Now().Add(e2eTimeout)
+	for {
+		err := bus.Publish("user.created.v1", message.NewMessage(watermill.NewUUID(), []byte(`{"n":2}`)))
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("publish did not recover after restart: %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	got := natstest.AwaitMessage(t, ch, e2eTimeout)
+	if string(got.Payload) != `{"n":2}` {
+		t.Fatalf("unexpected payload after restart %s", got.Payload)
+	}
+	got.Ack()
+}
+
+func TestEventBus_ScheduleAtRepublishesWhenDue(t *testing.T) {
+	srv := natstest.RunServer(t)
+	bus := srv.NewEventBus(eventbus.AppTypeBackend)
+	watch := srv.Watch("round.reminder.v1")
+
+	msg := message.NewMessage(watermill.NewUUID(), []byte(`{"round_id":"r"}`))
+	if err := bus.ScheduleAt(context.Background(), "round.reminder.v1", msg, time.Now().Add(200*time.Millisecond)); err != nil {
+		t.Fatalf("unexpected schedule error: %v", err)
+	}
+
+	got := watch.Next(e2eTimeout)
+	if string(got.Data) != `{"round_id":"r"}` {
+		t.Fatalf("unexpected scheduled payload %s", got.Data)
+	}
+	if got.Header.Get(eventbus.OriginalSubjectMetadataKey) != "" {
+		t.Fatalf("expected scheduling headers to be stripped, got %v", got.Header)
+	}
+}
+
+func TestEventBus_ScheduledMaxAckPendingIsConfigurable(t *testing.T) {
+	srv := natstest.RunServer(t)
+	bus := srv.NewEventBus(eventbus.AppTypeBackend, eventbus.WithScheduledMaxAckPending(50))
+
+	// Two schedules far in the future stay ack-pending on the delayed consumer.
+	for i := 0; i < 2; i++ {
+		msg := message.NewMessage(watermill.NewUUID(), []byte(`{"round_id":"r"}`))
+		if err := bus.ScheduleAt(context.Background(), "round.reminder.v1", msg, time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("unexpected schedule error: %v", err)
+		}
+	}
+
+	deadline := time.Now().Add(e2eTimeout)
+	for {
+		info := srv.ConsumerInfo(eventbus.DelayedMessagesStream, eventbus.GlobalDelayedConsumerName)
+		if info.Config.MaxAckPending != 50 {
+			t.Fatalf("expected max ack pending 50, got %d", info.Config.MaxAckPending)
+		}
+		if info.NumAckPending == 2 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 2 parked schedules, got %d ack pending", info.NumAckPending)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestEventBus_ShutdownWaitsForInFlightHandlers(t *testing.T) {
+	srv := natstest.RunServer(t)
+	bus := srv.NewEventBus(eventbus.AppTypeBackend)
+
+	ch, err := bus.Subscribe(context.Background(), "score.processed.v1")
+	if err != nil {
+		t.Fatalf("unexpected subscribe error: %v", err)
+	}
+	if err := bus.Publish("score.processed.v1", message.NewMessage(watermill.NewUUID(), []byte(`{}`))); err != nil {
+		t.Fatalf("unexpected publish error: %v", err)
+	}
+	msg := natstest.AwaitMessage(t, ch, e2eTimeout)
+
+	handlerCtxErr := make(chan error, 1)
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		handlerCtxErr <- msg.Context().Err()
+		msg.Ack()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), e2eTimeout)
+	defer cancel()
+	if err := bus.Shutdown(ctx); err != nil {
+		t.Fatalf("expected clean shutdown, got %v", err)
+	}
+	if err := <-handlerCtxErr; err != nil {
+		t.Fatalf("expected the handler context to stay live while shutting down, got %v", err)
+	}
+	if info := srv.ConsumerInfo("score", "backend-score-processed-v1"); info.NumAckPending != 0 || info.NumRedelivered != 0 {
+		t.Fatalf("expected the in-flight message to be acked, got %+v", info)
+	}
+}
+
+func TestEventBus_ShutdownDeadlineHandsOffInFlightMessages(t *testing.T) {
+	srv := natstest.RunServer(t)
+	bus := srv.NewEventBus(eventbus.AppTypeBackend)
+
+	ch, err := bus.Subscribe(context.Background(), "score.processed.v1")
+	if err != nil {
+		t.Fatalf("unexpected subscribe error: %v", err)
+	}
+	if err := bus.Publish("score.processed.v1", message.NewMessage(watermill.NewUUID(), []byte(`{}`))); err != nil {
+		t.Fatalf("unexpected publish error: %v", err)
+	}
+	stuck := natstest.AwaitMessage(t, ch, e2eTimeout)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	err = bus.Shutdown(ctx)
+
+	var shutdownErr *eventbus.ShutdownError
+	if !errors.As(err, &shutdownErr) || len(shutdownErr.Abandoned) != 1 {
+		t.Fatalf("expected one abandoned message, got %v", err)
+	}
+	if shutdownErr.Abandoned[0].MessageID != stuck.UUID {
+		t.Fatalf("unexpected abandoned message %+v", shutdownErr.Abandoned[0])
+	}
+
+	// Another replica on the same durable consumer picks the message up promptly.
+	replica := srv.NewEventBus(eventbus.AppTypeBackend)
+	replicaCh, err := replica.Subscribe(context.Background(), "score.processed.v1")
+	if err != nil {
+		t.Fatalf("unexpected subscribe error: %v", err)
+	}
+	redelivered := natstest.AwaitMessage(t, replicaCh, e2eTimeout)
+	if redelivered.UUID != stuck.UUID || redelivered.Metadata.Get("_js_num_delivered") != "2" {
+		t.Fatalf("expected the abandoned message to be redelivered, got %s (%v)", redelivered.UUID, redelivered.Metadata)
+	}
+	redelivered.Ack()
+}
+
+func TestEventBus_PartitionedOrderingSerializesPerKey(t *testing.T) {
+	srv := natstest.RunServer(t)
+	bus := srv.NewEventBus(eventbus.AppTypeBackend)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := bus.SubscribeWithOptions(ctx, "round.score.updated.v1", eventbus.SubscribeOptions{
+		Ordering:     eventbus.OrderingPartitioned,
+		PartitionKey: "round_id",
+	})
+	if err != nil {
+		t.Fatalf("unexpected subscribe error: %v", err)
+	}
+
+	for _, p := range []struct{ round, payload string }{
+		{"r1", "r1-first"},
+		{"r1", "r1-second"},
+		{"r2", "r2-first"},
+	} {
+		msg := message.NewMessage(watermill.NewUUID(), []byte(p.payload))
+		msg.Metadata.Set("round_id", p.round)
+		if err := bus.Publish("round.score.updated.v1", msg); err != nil {
+			t.Fatalf("unexpected publish error: %v", err)
+		}
+	}
+
+	// The first message of each round is handled in parallel; r1's second waits.
+	received := map[string]*message.Message{}
+	for range 2 {
+		msg := natstest.AwaitMessage(t, ch, e2eTimeout)
+		received[string(msg.Payload)] = msg
+	}
+	if received["r1-first"] == nil || received["r2-first"] == nil {
+		t.Fatalf("expected the first message of each round, got %v", received)
+	}
+	natstest.ExpectNoMessage(t, ch, 500*time.Millisecond)
+
+	received["r2-first"].Ack()
+	natstest.ExpectNoMessage(t, ch, 500*time.Millisecond)
+
+	received["r1-first"].Ack()
+	next := natstest.AwaitMessage(t, ch, e2eTimeout)
+	if string(next.Payload) != "r1-second" {
+		t.Fatalf("expected r1-second after r1-first settled, got %s", next.Payload)
+	}
+	next.Ack()
+}
+
+func TestEventBus_SubscribeWithOptionsRejectsMissingPartitionKey(t *testing.T) {
+	srv := natstest.RunServer(t)
+	bus := srv.NewEventBus(eventbus.AppTypeBackend)
+
+	_, err := bus.SubscribeWithOptions(context.Background(), "round.created.v1", eventbus.SubscribeOptions{
+		Ordering: eventbus.OrderingPartitioned,
+	})
+	if err == nil {
+		t.Fatal("expected error for partitioned ordering without a partition key")
+	}
+}
+
+func TestEventBus_PauseAndResumeSubscription(t *testing.T) {
+	srv := natstest.RunServer(t)
+	bus := srv.NewEventBus(eventbus.AppTypeBackend)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := bus.PauseSubscription("user.created.v1"); !errors.Is(err, eventbus.ErrSubscriptionNotFound) {
+		t.Fatalf("expected ErrSubscriptionNotFound, got %v", err)
+	}
+
+	ch, err := bus.SubscribeWithOptions(ctx, "user.created.v1", eventbus.SubscribeOptions{PullExpiry: 200 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("unexpected subscribe error: %v", err)
+	}
+	if err := bus.PauseSubscription("user.created.v1"); err != nil {
+		t.Fatalf("unexpected pause error: %v", err)
+	}
+	// Let the pull that was in progress when we paused expire.
+	time.Sleep(400 * time.Millisecond)
+
+	if err := bus.Publish("user.created.v1", message.NewMessage(watermill.NewUUID(), []byte(`{}`))); err != nil {
+		t.Fatalf("unexpected publish error: %v", err)
+	}
+	natstest.ExpectNoMessage(t, ch, time.Second)
+
+	if err := bus.ResumeSubscription("user.created.v1"); err != nil {
+		t.Fatalf("unexpected resume error: %v", err)
+	}
+	natstest.AwaitMessage(t, ch, e2eTimeout).Ack()
+}
+
+func TestEventBus_UpdateConsumerConfigAppliesInPlace(t *testing.T) {
+	srv := natstest.RunServer(t)
+	bus := srv.NewEventBus(eventbus.AppTypeBackend)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if _, err := bus.Subscribe(ctx, "round.created.v1"); err != nil {
+		t.Fatalf("unexpected subscribe error: %v", err)
+	}
+	before := srv.ConsumerInfo("round", "backend-round-created-v1")
+
+	cfg := eventbus.DefaultConsumerConfig()
+	cfg.AckWait = 20 * time.Second
+	cfg.BackOff = []time.Duration{2 * time.Second, 4 * time.Second}
+	cfg.MaxAckPending = 7
+	if err := bus.UpdateConsumerConfig(ctx, "round.created.v1", cfg); err != nil {
+		t.Fatalf("unexpected update error: %v", err)
+	}
+
+	after := srv.ConsumerInfo("round", "backend-round-created-v1")
+	if after.Created != before.Created {
+		t.Fatal("expected the consumer to be updated, not recreated")
+	}
+	if after.Config.MaxAckPending != 7 || len(after.Config.BackOff) != 2 || after.Config.BackOff[1] != 4*time.Second {
+		t.Fatalf("expected updated config, got %+v", after.Config)
+	}
+	if after.Config.AckWait != 2*time.Second {
+		t.Fatalf("expected server to report the first backoff as ack wait, got %s", after.Config.AckWait)
+	}
+
+	// Applying the same config again is a no-op.
+	if err := bus.UpdateConsumerConfig(ctx, "round.created.v1", cfg); err != nil {
+		t.Fatalf("unexpected error re-applying config: %v", err)
+	}
+}
+
+func TestEventBus_ConsumerDriftMigration(t *testing.T) {
+	srv := natstest.RunServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The first deploy acks one of two messages and stops.
+	bus := srv.NewEventBus(eventbus.AppTypeBackend)
+	ch, err := bus.Subscribe(ctx, "round.created.v1")
+	if err != nil {
+		t.Fatalf("unexpected subscribe error: %v", err)
+	}
+	for _, payload := range []string{"first", "second"} {
+		if err := bus.Publish("round.created.v1", message.NewMessage(watermill.NewUUID(), []byte(payload))); err != nil {
+			t.Fatalf("unexpected publish error: %v", err)
+		}
+	}
+	natstest.AwaitMessage(t, ch, e2eTimeout).Ack()
+	shutdownCtx, cancelShutdown := context.WithTimeout(ctx, 2*time.Second)
+	defer cancelShutdown()
+	_ = bus.Shutdown(shutdownCtx)
+
+	// The next deploy changes the immutable deliver policy.
+	registry := eventbus.NewConsumerConfigRegistry()
+	cfg := eventbus.DefaultConsumerConfig()
+	cfg.DeliverPolicy = jetstream.DeliverNewPolicy
+	registry.SetForTopic("round.created.v1", cfg)
+
+	manager := eventbus.NewConsumerManager(srv.JetStream(), slog.New(slog.NewTextHandler(io.Discard, nil)), registry,
+		eventbus.WithMigrationStrategy(eventbus.MigrationRecreate))
+	report, err := manager.DriftReport(ctx, eventbus.AppTypeBackend, eventbus.DefaultStreamTopology())
+	if err != nil {
+		t.Fatalf("unexpected drift report error: %v", err)
+	}
+	if len(report) != 1 || report[0].Consumer != "backend-round-created-v1" || report[0].Action != eventbus.MigrationActionRecreate {
+		t.Fatalf("unexpected drift report %+v", report)
+	}
+
+	strict := srv.NewEventBus(eventbus.AppTypeBackend, eventbus.WithConsumerConfigRegistry(registry))
+	if _, err := strict.Subscribe(ctx, "round.created.v1"); !errors.Is(err, eventbus.ErrConsumerConfigDrift) {
+		t.Fatalf("expected ErrConsumerConfigDrift, got %v", err)
+	}
+
+	migrating := srv.NewEventBus(eventbus.AppTypeBackend,
+		eventbus.WithConsumerConfigRegistry(registry),
+		eventbus.WithConsumerMigrationStrategy(eventbus.MigrationRecreate))
+	ch, err = migrating.Subscribe(ctx, "round.created.v1")
+	if err != nil {
+		t.Fatalf("unexpected subscribe error after migration: %v", err)
+	}
+
+	// The recreated consumer resumes after the ack floor rather than skipping to new messages.
+	got := natstest.AwaitMessage(t, ch, e2eTimeout)
+	if string(got.Payload) != "second" {
+		t.Fatalf("expected the unacked message, got %s", got.Payload)
+	}
+	got.Ack()
+
+	info := srv.ConsumerInfo("round", "backend-round-created-v1")
+	if info.Config.DeliverPolicy != jetstream.DeliverByStartSequencePolicy || info.Config.OptStartSeq != 2 {
+		t.Fatalf("unexpected recreated consumer config %+v", info.Config)
+	}
+	report, err = manager.DriftReport(ctx, eventbus.AppTypeBackend, eventbus.DefaultStreamTopology())
+	if err != nil || len(report) != 0 {
+		t.Fatalf("expected no drift after migration, got %+v, %v", report, err)
+	}
+}
+
+func TestEventBus_PublishBatchReturnsAcks(t *testing.T) {
+	srv := natstest.RunServer(t)
+	bus := srv.NewEventBus(eventbus.AppTypeBackend, eventbus.WithPublishAsyncMaxPending(4))
+
+	ctx, cancel := context.WithTimeout(context.Background(), e2eTimeout)
+	defer cancel()
+
+	// More messages than the max-pending window, plus a duplicate of the first.
+	var msgs []*message.Message
+	for i := 0; i < 10; i++ {
+		msg := message.NewMessage(watermill.NewUUID(), []byte(`{}`))
+		msg.Metadata.Set("idempotency_key", "score-"+strconv.Itoa(i))
+		msgs = append(msgs, msg)
+	}
+	dup := message.NewMessage(watermill.NewUUID(), []byte(`{}`))
+	dup.Metadata.Set("idempotency_key", "score-0")
+	msgs = append(msgs, dup)
+
+	acks, err := bus.PublishBatch(ctx, "score.update.requested.v1", msgs...)
+	if err != nil {
+		t.Fatalf("unexpected publish error: %v", err)
+	}
+	for i, ack := range acks[:10] {
+		if ack.Stream != "score" || ack.Sequence != uint64(i+1) || ack.Duplicate || ack.MessageID != msgs[i].UUID {
+			t.Fatalf("unexpected ack %d: %+v", i, ack)
+		}
+	}
+	if last := acks[10]; !last.Duplicate || last.Sequence != 1 {
+		t.Fatalf("expected duplicate ack for the first message, got %+v", last)
+	}
+	if info := srv.StreamInfo("score"); info.State.Msgs != 10 {
+		t.Fatalf("expected 10 messages in score stream, got %d", info.State.Msgs)
+	}
+
+	futures, err := bus.PublishAsync(ctx, "score.update.requested.v1", message.NewMessage(watermill.NewUUID(), []byte(`{}`)))
+	if err != nil {
+		t.Fatalf("unexpected publish error: %v", err)
+	}
+	ack, err := futures[0].Wait(ctx)
+	if err != nil || ack.Sequence != 11 {
+		t.Fatalf("unexpected async ack %+v, err %v", ack, err)
+	}
+}
+
+func TestEventBus_PublishAsyncFailsForUnknownSubject(t *testing.T) {
+	srv := natstest.RunServer(t)
+	bus := srv.NewEventBus(eventbus.AppTypeBackend)
+
+	ctx, cancel := context.WithTimeout(context.Background(), e2eTimeout)
+	defer cancel()
+
+	futures, err := bus.PublishAsync(ctx, "nostream.created.v1", message.NewMessage(watermill.NewUUID(), []byte(`{}`)))
+	if err != nil {
+		t.Fatalf("unexpected publish error: %v", err)
+	}
+	if _, err := futures[0].Wait(ctx); err == nil {
+		t.Fatal("expected the future to fail without a stream for the subject")
+	}
+
+	if _, err := bus.PublishBatch(ctx, "", message.NewMessage(watermill.NewUUID(), []byte(`{}`))); err == nil {
+		t.Fatal("expected an error for an empty topic")
+	}
+}
+
+func TestEventBus_JetStreamHealthCheckers(t *testing.T) {
+	srv := natstest.RunServer(t)
+	bus := srv.NewEventBus(eventbus.AppTypeBackend)
+
+	ctx, cancel := context.WithTimeout(context.Background(), e2eTimeout)
+	defer cancel()
+
+	for _, checker := range bus.GetHealthCheckers() {
+		if err := checker.Check(ctx); err != nil {
+			t.Fatalf("expected %s to pass, got %v", checker.Name(), err)
+		}
+	}
+	if err := eventbus.NewStreamHealthChecker(bus.GetJetStream(), "missing").Check(ctx); err == nil {
+		t.Fatal("expected the missing stream to fail its check")
+	}
+
+	// A durable nobody pulls from, so every published message stays pending.
+	if _, err := srv.JetStream().CreateOrUpdateConsumer(ctx, "round", jetstream.ConsumerConfig{
+		Durable:       "lag-probe",
+		FilterSubject: "round.created.v1",
+		AckPolicy:     jetstream.AckExplicitPolicy,
+	}); err != nil {
+		t.Fatalf("failed to create consumer: %v", err)
+	}
+
+	lag := eventbus.NewConsumerLagHealthChecker(bus.GetJetStream(), "round", "lag-probe", 2)
+	if err := lag.Check(ctx); err != nil {
+		t.Fatalf("expected no lag, got %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := bus.Publish("round.created.v1", message.NewMessage(watermill.NewUUID(), []byte(`{}`))); err != nil {
+			t.Fatalf("unexpected publish error: %v", err)
+		}
+	}
+	if err := lag.Check(ctx); err == nil {
+		t.Fatal("expected lag above the threshold to fail the check")
+	}
+}
+
+type e2eRoundDeletedPayload struct {
+	RoundID string `json:"round_id"`
+}
+
+type e2ePayloadRegistry map[string]any
+
+func (r e2ePayloadRegistry) PayloadFor(topic string) (any, bool) {
+	p, ok := r[topic]
+	return p, ok
+}
+
+func TestEventBus_ValidatePayloadsUsesDeliveredSubject(t *testing.T) {
+	srv := natstest.RunServer(t)
+	bus := srv.NewEventBus(eventbus.AppTypeBackend)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := bus.Subscribe(ctx, "round.deleted.v1")
+	if err != nil {
+		t.Fatalf("unexpected subscribe error: %v", err)
+	}
+	// Published without a topic header; validation must use the delivered subject.
+	if err := bus.Publish("round.deleted.v1", message.NewMessage(watermill.NewUUID(), []byte(`{}`))); err != nil {
+		t.Fatalf("unexpected publish error: %v", err)
+	}
+
+	got := natstest.AwaitMessage(t, ch, e2eTimeout)
+	defer got.Ack()
+	if subject := got.Metadata.Get(eventbus.SubjectMetadataKey); subject != "round.deleted.v1" {
+		t.Fatalf("expected delivered subject in metadata, got %q", subject)
+	}
+
+	registry := e2ePayloadRegistry{"round.deleted.v1": &e2eRoundDeletedPayload{}}
+	handler := eventbus.ValidatePayloads(registry, eventbus.PayloadValidationStrict, slog.New(slog.NewTextHandler(io.Discard, nil)), nil)(
+		func(*message.Message) ([]*message.Message, error) { return nil, nil },
+	)
+	if _, err := handler(got); !errors.Is(err, eventbus.ErrPayloadValidation) {
+		t.Fatalf("expected payload validation error, got %v", err)
+	}
+}
+
+// awaitDeadLetters polls the domain's DLQ until it holds n entries.
+func awaitDeadLetters(t *testing.T, bus eventbus.EventBus, domain string, n int) []eventbus.DeadLetter {
+	t.Helper()
+	deadline := time.Now().Add(e2eTimeout)
+	for {
+		dls, err := bus.ListDeadLetters(context.Background(), domain, 0)
+		if err != nil {
+			t.Fatalf("unexpected list error: %v", err)
+		}
+		if len(dls) == n {
+			return dls
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d dead letters in %s, got %d", n, domain, len(dls))
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestEventBus_TerminatedMessageIsDeadLetteredAndReplayed(t *testing.T) {
+	srv := natstest.RunServer(t)
+	bus := srv.NewEventBus(eventbus.AppTypeBackend)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := bus.Subscribe(ctx, "round.created.v1")
+	if err != nil {
+		t.Fatalf("unexpected subscribe error: %v", err)
+	}
+	msg := message.NewMessage(watermill.NewUUID(), []byte(`{"round_id":"r"}`))
+	msg.Metadata.Set("correlation_id", "corr-1")
+	if err := bus.Publish("round.created.v1", msg); err != nil {
+		t.Fatalf("unexpected publish error: %v", err)
+	}
+
+	got := natstest.AwaitMessage(t, ch, e2eTimeout)
+	eventbus.TerminateMessage(got, "unprocessable round")
+	got.Nack()
+
+	dls := awaitDeadLetters(t, bus, "round", 1)
+	dl := dls[0]
+	if dl.OriginalSubject != "round.created.v1" || dl.Reason != eventbus.DeadLetterReasonTerminated || dl.LastError != "unprocessable round" {
+		t.Fatalf("unexpected dead letter %+v", dl)
+	}
+	if dl.Headers["correlation_id"] != "corr-1" || string(dl.Payload) != `{"round_id":"r"}` {
+		t.Fatalf("expected original message to be preserved, got %+v", dl)
+	}
+
+	if err := bus.ReplayDeadLetter(ctx, "round", dl.Sequence); err != nil {
+		t.Fatalf("unexpected replay error: %v", err)
+	}
+	replayed := natstest.AwaitMessage(t, ch, e2eTimeout)
+	replayed.Ack()
+	if string(replayed.Payload) != `{"round_id":"r"}` || replayed.Metadata.Get(eventbus.DeadLetterMetadataKey) != "true" {
+		t.Fatalf("unexpected replayed message %s %v", replayed.Payload, replayed.Metadata)
+	}
+	awaitDeadLetters(t, bus, "round", 0)
+}
+
+func TestEventBus_AckWaitExhaustedMessageIsDeadLettered(t *testing.T) {
+	srv := natstest.RunServer(t)
+
+	registry := eventbus.NewConsumerConfigRegistry()
+	cfg := eventbus.DefaultConsumerConfig()
+	cfg.AckWait = time.Second
+	cfg.MaxDeliver = 1
+	cfg.BackOff = nil
+	registry.SetForTopic("round.created.v1", cfg)
+	bus := srv.NewEventBus(eventbus.AppTypeBackend, eventbus.WithConsumerConfigRegistry(registry))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := bus.Subscribe(ctx, "round.created.v1")
+	if err != nil {
+		t.Fatalf("unexpected subscribe error: %v", err)
+	}
+	if err := bus.Publish("round.created.v1", message.NewMessage(watermill.NewUUID(), []byte(`{"round_id":"r"}`))); err != nil {
+		t.Fatalf("unexpected publish error: %v", err)
+	}
+
+	// The handler never settles the message, so its only delivery expires.
+	natstest.AwaitMessage(t, ch, e2eTimeout)
+
+	dls := awaitDeadLetters(t, bus, "round", 1)
+	if dls[0].Reason != eventbus.DeadLetterReasonMaxDeliver || dls[0].OriginalSubject != "round.created.v1" || dls[0].OriginalSequence != 1 {
+		t.Fatalf("unexpected dead letter %+v", dls[0])
+	}
+}
+
+func TestEventBus_TerminatedMessageIsKeptWhenDeadLetterFails(t *testing.T) {
+	srv := natstest.RunServer(t)
+
+	// A stream already owning the DLQ subjects makes creating round-dlq fail.
+	if _, err := srv.JetStream().CreateStream(context.Background(), jetstream.StreamConfig{
+		Name:     "dlq-blocker",
+		Subjects: []string{"dlq.round.>"},
+	}); err != nil {
+		t.Fatalf("unexpected stream error: %v", err)
+	}
+
+	registry := eventbus.NewConsumerConfigRegistry()
+	cfg := eventbus.DefaultConsumerConfig()
+	cfg.BackOff = nil
+	registry.SetForTopic("round.created.v1", cfg)
+	bus := srv.NewEventBus(eventbus.AppTypeBackend, eventbus.WithConsumerConfigRegistry(registry))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := bus.Subscribe(ctx, "round.created.v1")
+	if err != nil {
+		t.Fatalf("unexpected subscribe error: %v", err)
+	}
+	if err := bus.Publish("round.created.v1", message.NewMessage(watermill.NewUUID(), []byte(`{"round_id":"r"}`))); err != nil {
+		t.Fatalf("unexpected publish error: %v", err)
+	}
+
+	got := natstest.AwaitMessage(t, ch, e2eTimeout)
+	eventbus.TerminateMessage(got, "unprocessable round")
+	got.Nack()
+
+	// The message is nacked instead of terminated, so it comes back.
+	redelivered := natstest.AwaitMessage(t, ch, e2eTimeout)
+	defer redelivered.Ack()
+	if redelivered.Metadata.Get("_js_num_delivered") != "2" {
+		t.Fatalf("expected a redelivery, got %v", redelivered.Metadata)
+	}
+}
+
+type e2eUserRequest struct {
+	UserID string `json:"user_id"`
+}
+
+type e2eUserResponse struct {
+	UserID      string `json:"user_id"`
+	DisplayName string `json:"display_name"`
+}
+
+type e2eUserFailure struct {
+	UserID string `json:"user_id"`
+	Reason string `json:"reason"`
+}
+
+func TestEventBus_RequestClassifiesHelperBuiltRepliesByReplyTopic(t *testing.T) {
+	srv := natstest.RunServer(t)
+	bus := srv.NewEventBus(eventbus.AppTypeBackend)
+	helpers := utils.NewHelper(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	requests, err := bus.Subscribe(ctx, "user.get.requested.v1")
+	if err != nil {
+		t.Fatalf("unexpected subscribe error: %v", err)
+	}
+
+	// The responder answers on reply_to without handlerwrapper.ReplyResult, so it sets
+	// reply_topic itself.
+	go func() {
+		for msg := range requests {
+			var req e2eUserRequest
+			_ = json.Unmarshal(msg.Payload, &req)
+			var payload any = e2eUserResponse{UserID: req.UserID, DisplayName: "Ace"}
+			replyTopic := "user.get.succeeded.v1"
+			if req.UserID == "missing" {
+				payload = e2eUserFailure{UserID: req.UserID, Reason: "user not found"}
+				replyTopic = "user.get.failed.v1"
+			}
+			replyTo := msg.Metadata.Get(utils.MetadataReplyTo)
+			reply, err := helpers.CreateResultMessage(msg, payload, replyTo)
+			if err == nil {
+				reply.Metadata.Set(utils.MetadataReplyTopic, replyTopic)
+				err = bus.Publish(replyTo, reply)
+			}
+			if err != nil {
+				t.Errorf("unexpected reply error: %v", err)
+			}
+			msg.Ack()
+		}
+	}()
+
+	resp, err := eventbus.Request[e2eUserRequest, e2eUserResponse](ctx, bus, "user.get.requested.v1", e2eUserRequest{UserID: "u1"}, e2eTimeout)
+	if err != nil || resp.DisplayName != "Ace" {
+		t.Fatalf("expected a successful reply, got %+v err=%v", resp, err)
+	}
+
+	resp, err = eventbus.Request[e2eUserRequest, e2eUserResponse](ctx, bus, "user.get.requested.v1", e2eUserRequest{UserID: "missing"}, e2eTimeout)
+	var failed *eventbus.RequestFailedError
+	if !errors.As(err, &failed) {
+		t.Fatalf("expected RequestFailedError, got %+v err=%v", resp, err)
+	}
+	if failed.Topic != "user.get.failed.v1" || failed.Reason != "user not found" {
+		t.Fatalf("unexpected failure %+v", failed)
+	}
+}
+
+func TestEventBus_RequestTimeoutReportsOnlyASetTimeout(t *testing.T) {
+	srv := natstest.RunServer(t)
+	bus := srv.NewEventBus(eventbus.AppTypeBackend)
+
+	_, err := eventbus.Request[e2eUserRequest, e2eUserResponse](context.Background(), bus, "user.get.requested.v1", e2eUserRequest{UserID: "u1"}, 100*time.Millisecond)
+	if !errors.Is(err, eventbus.ErrRequestTimeout) || !strings.HasSuffix(err.Error(), "after 100ms") {
+		t.Fatalf("expected a timeout after 100ms, got %v", err)
+	}
+
+	// Without a timeout the caller's deadline ends the wait, and there is no duration to report.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = eventbus.Request[e2eUserRequest, e2eUserResponse](ctx, bus, "user.get.requested.v1", e2eUserRequest{UserID: "u1"}, 0)
+	if !errors.Is(err, eventbus.ErrRequestTimeout) || strings.Contains(err.Error(), "after") {
+		t.Fatalf("expected a timeout without a duration, got %v", err)
+	}
+}
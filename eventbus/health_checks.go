@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"sort"
 	"strings"
 	"time"
 
@@ -12,15 +13,83 @@ import (
 	"github.com/nats-io/nats.go/jetstream"
 )
 
-// GetHealthCheckers returns health checkers for the EventBus components
+// GetHealthCheckers returns health checkers for the EventBus components: the NATS
+// connection and every stream this app created.
 func (eb *eventBus) GetHealthCheckers() []HealthChecker {
-	return []HealthChecker{
+	checkers := []HealthChecker{
 		&natsHealthChecker{
 			natsConn: eb.natsConn,
 			js:       eb.js,
 			logger:   eb.logger,
 		},
 	}
+
+	eb.streamMutex.Lock()
+	streams := make([]string, 0, len(eb.createdStreams))
+	for name := range eb.createdStreams {
+		streams = append(streams, name)
+	}
+	eb.streamMutex.Unlock()
+	sort.Strings(streams)
+
+	for _, name := range streams {
+		checkers = append(checkers, NewStreamHealthChecker(eb.js, name))
+	}
+	return checkers
+}
+
+// NewStreamHealthChecker fails when stream does not exist on the JetStream server.
+func NewStreamHealthChecker(js jetstream.JetStream, stream string) HealthChecker {
+	return &streamHealthChecker{js: js, stream: stream}
+}
+
+type streamHealthChecker struct {
+	js     jetstream.JetStream
+	stream string
+}
+
+func (c *streamHealthChecker) Name() string {
+	return "JetStream stream " + c.stream
+}
+
+func (c *streamHealthChecker) Check(ctx context.Context) error {
+	if _, err := c.js.Stream(ctx, c.stream); err != nil {
+		return fmt.Errorf("stream %s unavailable: %w", c.stream, err)
+	}
+	return nil
+}
+
+// NewConsumerLagHealthChecker fails when the durable consumer has more than maxPending
+// messages waiting to be delivered. Register it as degraded-only when a backlog should
+// be reported without taking the service out of rotation.
+func NewConsumerLagHealthChecker(js jetstream.JetStream, stream, consumer string, maxPending uint64) HealthChecker {
+	return &consumerLagHealthChecker{js: js, stream: stream, consumer: consumer, maxPending: maxPending}
+}
+
+type consumerLagHealthChecker struct {
+	js         jetstream.JetStream
+	stream     string
+	consumer   string
+	maxPending uint64
+}
+
+func (c *consumerLagHealthChecker) Name() string {
+	return "JetStream consumer lag " + c.stream + "/" + c.consumer
+}
+
+func (c *consumerLagHealthChecker) Check(ctx context.Context) error {
+	cons, err := c.js.Consumer(ctx, c.stream, c.consumer)
+	if err != nil {
+		return fmt.Errorf("consumer %s on stream %s unavailable: %w", c.consumer, c.stream, err)
+	}
+	info, err := cons.Info(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch consumer info %s: %w", c.consumer, err)
+	}
+	if info.NumPending > c.maxPending {
+		return fmt.Errorf("consumer %s has %d pending messages (threshold %d)", c.consumer, info.NumPending, c.maxPending)
+	}
+	return nil
 }
 
 // natsHealthChecker implements health checking for NATS/JetStream
@@ -0,0 +1,123 @@
+// Package idempotency guards handlers against JetStream redeliveries.
+//
+// JetStream deduplicates publishes by Nats-Msg-Id, but a message that is nacked
+// (or whose ack is lost) after its handler already ran will be delivered again.
+// Middleware records the Nats-Msg-Id of every handled message per consumer once it
+// is acked, and acks later deliveries of the same ID without running the handler.
+//
+// Usage:
+//
+//	store, _ := idempotency.NewKVStore(ctx, js, "processed-messages", 24*time.Hour)
+//	router.AddMiddleware(idempotency.Middleware(store, logger, registry.EventBusMetrics))
+package idempotency
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/eventbus"
+	"github.com/Black-And-White-Club/frolf-bot-shared/observability/attr"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+// MessageIDMetadataKey is the metadata key carrying the JetStream dedupe ID.
+const MessageIDMetadataKey = "Nats-Msg-Id"
+
+// Store records which message IDs a consumer has processed.
+type Store interface {
+	// Processed reports whether consumer already handled msgID.
+	Processed(ctx context.Context, consumer, msgID string) (bool, error)
+	// MarkProcessed records that consumer handled msgID.
+	MarkProcessed(ctx context.Context, consumer, msgID string) error
+}
+
+// Metrics receives duplicate notifications. eventbusmetrics.EventBusMetrics implements it.
+type Metrics interface {
+	RecordDuplicateMessage(ctx context.Context, topic string, consumer string)
+}
+
+// Middleware skips messages whose Nats-Msg-Id was already processed by the same consumer.
+// Duplicates are acked (the handler returns no error and no messages). Messages without
+// a Nats-Msg-Id pass through unchanged. Store errors fail open: the handler runs.
+//
+// A message is recorded only after it is acked, which the router does once the
+// handler's output messages are published. A message nacked because an output
+// failed to publish is therefore handled again on redelivery.
+//
+// The consumer is the JetStream durable consumer name (_js_consumer metadata), falling
+// back to the Watermill handler name. metrics may be nil.
+func Middleware(store Store, logger *slog.Logger, metrics Metrics) message.HandlerMiddleware {
+	return func(next message.HandlerFunc) message.HandlerFunc {
+		return func(msg *message.Message) ([]*message.Message, error) {
+			msgID := msg.Metadata.Get(MessageIDMetadataKey)
+			consumer := consumerName(msg)
+			if msgID == "" || consumer == "" {
+				return next(msg)
+			}
+
+			ctx := msg.Context()
+			topic := eventbus.MessageTopic(msg)
+
+			processed, err := store.Processed(ctx, consumer, msgID)
+			if err != nil {
+				logger.WarnContext(ctx, "Idempotency lookup failed, processing message",
+					attr.String("consumer", consumer),
+					attr.String("nats_msg_id", msgID),
+					attr.Error(err),
+				)
+			} else if processed {
+				logger.InfoContext(ctx, "Skipping already processed message",
+					attr.String("consumer", consumer),
+					attr.String("nats_msg_id", msgID),
+					attr.Topic(topic),
+				)
+				if metrics != nil {
+					metrics.RecordDuplicateMessage(ctx, topic, consumer)
+				}
+				return nil, nil
+			}
+
+			msgs, err := next(msg)
+			if err != nil {
+				return msgs, err
+			}
+
+			go markOnAck(msg, store, logger, consumer, msgID)
+			return msgs, nil
+		}
+	}
+}
+
+// markOnAck records msgID once msg is acked. Nacked messages, and messages
+// dropped when the subscriber closes, are left unrecorded.
+func markOnAck(msg *message.Message, store Store, logger *slog.Logger, consumer, msgID string) {
+	ctx := msg.Context()
+	select {
+	case <-msg.Acked():
+	case <-msg.Nacked():
+		return
+	case <-ctx.Done():
+		select {
+		case <-msg.Acked():
+		default:
+			return
+		}
+	}
+
+	// The subscriber may cancel the message context as soon as the ack is sent.
+	ctx = context.WithoutCancel(ctx)
+	if err := store.MarkProcessed(ctx, consumer, msgID); err != nil {
+		logger.WarnContext(ctx, "Failed to record processed message",
+			attr.String("consumer", consumer),
+			attr.String("nats_msg_id", msgID),
+			attr.Error(err),
+		)
+	}
+}
+
+func consumerName(msg *message.Message) string {
+	if consumer := msg.Metadata.Get("_js_consumer"); consumer != "" {
+		return consumer
+	}
+	return message.HandlerNameFromCtx(msg.Context())
+}
@@ -0,0 +1,161 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/eventbus"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+type countingMetrics struct {
+	duplicates int
+	topic      string
+}
+
+func (m *countingMetrics) RecordDuplicateMessage(ctx context.Context, topic string, consumer string) {
+	m.duplicates++
+	m.topic = topic
+}
+
+func newMessage(msgID string) *message.Message {
+	msg := message.NewMessage("uuid", nil)
+	msg.Metadata.Set(MessageIDMetadataKey, msgID)
+	msg.Metadata.Set("_js_consumer", "backend-round-created-v1")
+	msg.Metadata.Set("topic", "round.created.v1")
+	return msg
+}
+
+// awaitProcessed waits for the middleware to record msgID after its ack.
+func awaitProcessed(t *testing.T, store Store, msgID string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if ok, _ := store.Processed(context.Background(), "backend-round-created-v1", msgID); ok {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %s to be recorded as processed", msgID)
+}
+
+func TestMiddleware_SkipsProcessedMessages(t *testing.T) {
+	store := NewMemoryStore(10, time.Hour)
+	metrics := &countingMetrics{}
+	calls := 0
+	handler := Middleware(store, slog.New(slog.NewTextHandler(io.Discard, nil)), metrics)(
+		func(msg *message.Message) ([]*message.Message, error) {
+			calls++
+			return nil, nil
+		},
+	)
+
+	first := newMessage("idem-1")
+	if _, err := handler(first); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first.Ack()
+	awaitProcessed(t, store, "idem-1")
+
+	duplicate := newMessage("idem-1")
+	duplicate.Metadata.Set(eventbus.SubjectMetadataKey, "round.created.v1.guild-1")
+	if _, err := handler(duplicate); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected handler to run once, ran %d times", calls)
+	}
+	if metrics.duplicates != 1 || metrics.topic != "round.created.v1.guild-1" {
+		t.Fatalf("expected 1 duplicate recorded on the delivered subject, got %d on %q", metrics.duplicates, metrics.topic)
+	}
+}
+
+func TestMiddleware_DoesNotRecordFailedMessages(t *testing.T) {
+	store := NewMemoryStore(10, time.Hour)
+	calls := 0
+	handler := Middleware(store, slog.New(slog.NewTextHandler(io.Discard, nil)), nil)(
+		func(msg *message.Message) ([]*message.Message, error) {
+			calls++
+			return nil, errors.New("db unavailable")
+		},
+	)
+
+	for i := 0; i < 2; i++ {
+		if _, err := handler(newMessage("idem-1")); err == nil {
+			t.Fatal("expected handler error")
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("expected failed message to be retried, ran %d times", calls)
+	}
+}
+
+// The router publishes a handler's outputs before acking its input, and nacks
+// the input when publishing fails. That message must be handled again.
+func TestMiddleware_DoesNotRecordNackedMessages(t *testing.T) {
+	store := NewMemoryStore(10, time.Hour)
+	calls := 0
+	handler := Middleware(store, slog.New(slog.NewTextHandler(io.Discard, nil)), nil)(
+		func(msg *message.Message) ([]*message.Message, error) {
+			calls++
+			return []*message.Message{message.NewMessage("out", nil)}, nil
+		},
+	)
+
+	first := newMessage("idem-1")
+	if _, err := handler(first); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ok, _ := store.Processed(context.Background(), "backend-round-created-v1", "idem-1"); ok {
+		t.Fatal("expected message not to be recorded before it is acked")
+	}
+	first.Nack()
+
+	second := newMessage("idem-1")
+	outputs, err := handler(second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 || len(outputs) != 1 {
+		t.Fatalf("expected redelivery to be handled again with its outputs, ran %d times, got %d outputs", calls, len(outputs))
+	}
+	second.Ack()
+	awaitProcessed(t, store, "idem-1")
+}
+
+func TestMemoryStore_EvictsAndExpires(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore(2, time.Minute)
+	store.now = func() time.Time { return now }
+
+	_ = store.MarkProcessed(ctx, "c", "a")
+	_ = store.MarkProcessed(ctx, "c", "b")
+	_ = store.MarkProcessed(ctx, "c", "c")
+
+	if ok, _ := store.Processed(ctx, "c", "a"); ok {
+		t.Fatal("expected least recently used entry to be evicted")
+	}
+	if ok, _ := store.Processed(ctx, "other", "b"); ok {
+		t.Fatal("expected IDs to be scoped per consumer")
+	}
+	if ok, _ := store.Processed(ctx, "c", "b"); !ok {
+		t.Fatal("expected entry to be tracked")
+	}
+
+	now = now.Add(time.Minute)
+	if ok, _ := store.Processed(ctx, "c", "b"); ok {
+		t.Fatal("expected entry to expire after ttl")
+	}
+}
+
+func TestKVKeyIsValid(t *testing.T) {
+	key := kvKey("backend round.created", "idem-abc/def")
+	if len(key) == 0 || key[:22] != "backend_round_created." {
+		t.Fatalf("unexpected key %q", key)
+	}
+}
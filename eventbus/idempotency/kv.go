@@ -0,0 +1,78 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// KVStore records processed message IDs in a JetStream key-value bucket, so every
+// replica of a consumer shares the same view. Entries expire with the bucket TTL.
+type KVStore struct {
+	kv jetstream.KeyValue
+}
+
+// NewKVStore creates or updates the bucket and returns a store on it.
+// The TTL should exceed the longest redelivery window of the guarded consumers.
+func NewKVStore(ctx context.Context, js jetstream.JetStream, bucket string, ttl time.Duration) (*KVStore, error) {
+	kv, err := js.CreateOrUpdateKeyValue(ctx, jetstream.KeyValueConfig{
+		Bucket:      bucket,
+		Description: "Processed message IDs per consumer",
+		TTL:         ttl,
+		Storage:     jetstream.FileStorage,
+		History:     1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create idempotency bucket %s: %w", bucket, err)
+	}
+	return NewKVStoreFromBucket(kv), nil
+}
+
+// NewKVStoreFromBucket wraps an existing key-value bucket.
+func NewKVStoreFromBucket(kv jetstream.KeyValue) *KVStore {
+	return &KVStore{kv: kv}
+}
+
+// Processed reports whether consumer already handled msgID.
+func (s *KVStore) Processed(ctx context.Context, consumer, msgID string) (bool, error) {
+	_, err := s.kv.Get(ctx, kvKey(consumer, msgID))
+	if errors.Is(err, jetstream.ErrKeyNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read idempotency key: %w", err)
+	}
+	return true, nil
+}
+
+// MarkProcessed records that consumer handled msgID.
+func (s *KVStore) MarkProcessed(ctx context.Context, consumer, msgID string) error {
+	if _, err := s.kv.Put(ctx, kvKey(consumer, msgID), []byte(time.Now().UTC().Format(time.RFC3339Nano))); err != nil {
+		return fmt.Errorf("failed to write idempotency key: %w", err)
+	}
+	return nil
+}
+
+// kvKey builds a valid KV key: the consumer name as the first token and a hash of the
+// message ID, since Nats-Msg-Id values may contain characters KV keys do not allow.
+func kvKey(consumer, msgID string) string {
+	sum := sha256.Sum256([]byte(msgID))
+	return sanitizeKeyToken(consumer) + "." + hex.EncodeToString(sum[:])
+}
+
+func sanitizeKeyToken(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
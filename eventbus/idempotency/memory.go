@@ -0,0 +1,103 @@
+package idempotency
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+const defaultMemoryCapacity = 10000
+
+// MemoryStore is an LRU of processed message IDs with a TTL. It only protects a
+// single process; use KVStore when several replicas share a consumer.
+type MemoryStore struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	entries  map[string]*list.Element
+	order    *list.List
+	now      func() time.Time
+}
+
+type memoryEntry struct {
+	key       string
+	expiresAt time.Time
+}
+
+// NewMemoryStore creates a store holding at most capacity IDs for ttl each.
+// A capacity of zero or less uses 10000; a ttl of zero keeps entries until evicted.
+func NewMemoryStore(capacity int, ttl time.Duration) *MemoryStore {
+	if capacity <= 0 {
+		capacity = defaultMemoryCapacity
+	}
+	return &MemoryStore{
+		capacity: capacity,
+		ttl:      ttl,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+// Processed reports whether consumer already handled msgID.
+func (s *MemoryStore) Processed(ctx context.Context, consumer, msgID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.entries[memoryKey(consumer, msgID)]
+	if !ok {
+		return false, nil
+	}
+	entry := el.Value.(*memoryEntry)
+	if s.expired(entry) {
+		s.remove(el)
+		return false, nil
+	}
+	s.order.MoveToFront(el)
+	return true, nil
+}
+
+// MarkProcessed records that consumer handled msgID, evicting the least recently used entry when full.
+func (s *MemoryStore) MarkProcessed(ctx context.Context, consumer, msgID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := memoryKey(consumer, msgID)
+	var expiresAt time.Time
+	if s.ttl > 0 {
+		expiresAt = s.now().Add(s.ttl)
+	}
+
+	if el, ok := s.entries[key]; ok {
+		el.Value.(*memoryEntry).expiresAt = expiresAt
+		s.order.MoveToFront(el)
+		return nil
+	}
+
+	s.entries[key] = s.order.PushFront(&memoryEntry{key: key, expiresAt: expiresAt})
+	for s.order.Len() > s.capacity {
+		s.remove(s.order.Back())
+	}
+	return nil
+}
+
+// Len returns the number of tracked IDs, including expired ones not yet evicted.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+func (s *MemoryStore) expired(entry *memoryEntry) bool {
+	return !entry.expiresAt.IsZero() && !s.now().Before(entry.expiresAt)
+}
+
+func (s *MemoryStore) remove(el *list.Element) {
+	s.order.Remove(el)
+	delete(s.entries, el.Value.(*memoryEntry).key)
+}
+
+func memoryKey(consumer, msgID string) string {
+	return consumer + "|" + msgID
+}
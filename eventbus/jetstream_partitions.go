@@ -0,0 +1,107 @@
+package eventbus
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// partitions queues deliveries per partition key for OrderingPartitioned subscriptions.
+// A key has a queue only while one of its messages is queued or being handled.
+type partitions struct {
+	mu     sync.Mutex
+	queues map[string][]*delivery
+}
+
+func newPartitions() *partitions {
+	return &partitions{queues: make(map[string][]*delivery)}
+}
+
+// enqueue appends d to key's queue. It returns true when the key was idle, in which case
+// the caller must start a worker for it.
+func (p *partitions) enqueue(key string, d *delivery) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	queue, active := p.queues[key]
+	p.queues[key] = append(queue, d)
+	return !active
+}
+
+// next pops the head of key's queue. When the queue is empty the key becomes idle and
+// next returns false.
+func (p *partitions) next(key string) (*delivery, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	queue := p.queues[key]
+	if len(queue) == 0 {
+		delete(p.queues, key)
+		return nil, false
+	}
+	d := queue[0]
+	queue[0] = nil
+	p.queues[key] = queue[1:]
+	return d, true
+}
+
+// waiting returns the deliveries queued behind key's current message.
+func (p *partitions) waiting(key string) []*delivery {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*delivery(nil), p.queues[key]...)
+}
+
+// runPartition emits key's messages one at a time, waiting for each to be settled before
+// emitting the next. Queued messages get in-progress heartbeats so they are not
+// redelivered while they wait their turn.
+func (s *JetStreamSubscriberAdapter) runPartition(
+	ctx context.Context,
+	settleCtx context.Context,
+	sub *subscription,
+	key string,
+	ackSem chan struct{},
+	cfg ConsumerConfig,
+	logger *slog.Logger,
+) {
+	heartbeatInterval, _ := ackHeartbeatAndDeadline(cfg.AckWait)
+	heartbeatTicker := time.NewTicker(heartbeatInterval)
+	defer heartbeatTicker.Stop()
+
+	for {
+		d, ok := sub.partitions.next(key)
+		if !ok {
+			return
+		}
+		if !s.emit(ctx, sub, d, ackSem, cfg.BackOff, logger) {
+			// Fetching stopped; hand the rest of the partition back to the server
+			for d, ok := sub.partitions.next(key); ok; d, ok = sub.partitions.next(key) {
+				s.releaseUnemitted(sub, d, ackSem, cfg.BackOff, logger)
+			}
+			return
+		}
+
+		settled := make(chan struct{})
+		go func() {
+			defer close(settled)
+			s.waitForAck(settleCtx, d.jsMsg, d.wmMsg, d.span, sub, ackSem, logger)
+		}()
+
+	wait:
+		for {
+			select {
+			case <-settled:
+				break wait
+			case <-heartbeatTicker.C:
+				for _, queued := range sub.partitions.waiting(key) {
+					if err := queued.jsMsg.InProgress(); err != nil {
+						logger.WarnContext(ctx, "Failed to extend ack deadline for queued message",
+							"error", err,
+							"message_id", queued.wmMsg.UUID,
+							"partition_key", key,
+						)
+					}
+				}
+			}
+		}
+	}
+}
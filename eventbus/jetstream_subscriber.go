@@ -2,7 +2,9 @@ package eventbus
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"math/rand"
 	"strconv"
@@ -10,26 +12,55 @@ import (
 	"sync/atomic"
 	"time"
 
+	eventbusmetrics "github.com/Black-And-White-Club/frolf-bot-shared/observability/otel/metrics/eventbus"
 	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/ThreeDotsLabs/watermill/message/router/middleware"
 	nc "github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
 	// defaultMaxConcurrentAcks is the default limit for concurrent ACK handling goroutines.
 	defaultMaxConcurrentAcks = 50
 
-	// pullBatchSize is the number of messages to fetch in each pull request.
+	// pullBatchSize is the default number of messages to fetch in each pull request.
 	pullBatchSize = 10
 
-	// pullExpiry is how long to wait for messages in each pull request.
+	// pullExpiry is the default wait for messages in each pull request.
 	pullExpiry = 5 * time.Second
 
+	// shutdownNakDelay is the redelivery delay for messages abandoned during shutdown.
+	shutdownNakDelay = time.Second
+
+	// consumerLagPollInterval is how often consumer info is polled for lag metrics.
+	consumerLagPollInterval = 15 * time.Second
+
 	// maxAckWaitExtensions bounds how long heartbeats can keep a message in-flight.
 	// After this window, we stop extending AckWait so JetStream can redeliver.
 	maxAckWaitExtensions = 3
+
+	// deadLetterAdvisoryTimeout bounds dead-lettering a message named by an advisory.
+	deadLetterAdvisoryTimeout = 10 * time.Second
 )
 
+// TerminateMetadataKey marks a single message for termination instead of redelivery
+// when it is nacked. Set it with TerminateMessage.
+const TerminateMetadataKey = "terminate_reason"
+
+// TerminateMessage marks msg so that a subsequent nack terminates it (and moves it to
+// the dead-letter queue) instead of scheduling a redelivery. Use it for failures that
+// retrying cannot fix, such as malformed payloads.
+func TerminateMessage(msg *message.Message, reason string) {
+	if reason == "" {
+		reason = "terminated by handler"
+	}
+	msg.Metadata.Set(TerminateMetadataKey, reason)
+	if msg.Metadata.Get(LastErrorMetadataKey) == "" {
+		msg.Metadata.Set(LastErrorMetadataKey, reason)
+	}
+}
+
 // JetStreamSubscriberAdapter implements message.Subscriber using native JetStream pull consumers.
 // It provides bounded concurrency for ACK handling and graceful shutdown support.
 type JetStreamSubscriberAdapter struct {
@@ -47,17 +78,52 @@ type JetStreamSubscriberAdapter struct {
 	// termination tracks topics that should terminate messages instead of retrying.
 	terminationMu sync.RWMutex
 	termination   map[string]bool
+
+	// deadLetters receives terminated and delivery-exhausted messages. Nil disables the DLQ.
+	deadLetters *deadLetterQueue
+
+	// topology resolves topics to streams.
+	topology *StreamTopology
+
+	// tracer starts a consumer span per delivery. Nil disables tracing.
+	tracer trace.Tracer
+
+	metrics eventbusmetrics.EventBusMetrics
+
+	// topicOptions holds per-topic SubscribeOptions applied by Subscribe.
+	topicOptions map[string]SubscribeOptions
+
+	// abandoned collects in-flight messages nacked because their subscription ended.
+	abandonedMu sync.Mutex
+	abandoned   []AbandonedMessage
+}
+
+// AbandonedMessage describes an in-flight message that was nacked for redelivery
+// because its handler had not finished when the subscription shut down.
+type AbandonedMessage struct {
+	Topic         string
+	Consumer      string
+	MessageID     string
+	CorrelationID string
 }
 
 // subscription tracks an active subscription for cleanup during Close.
 type subscription struct {
-	topic      string
-	consumer   jetstream.Consumer
+	topic        string
+	consumerName string
+	consumer     jetstream.Consumer
+	opts         SubscribeOptions
+	partitions   *partitions
+	gate         pauseGate
+	// cancel stops fetching; abandon also gives up on in-flight messages.
 	cancel     context.CancelFunc
+	abandon    context.CancelFunc
 	wg         *sync.WaitGroup
 	outputCh   chan *message.Message
 	closedOnce sync.Once
 	closedDone chan struct{}
+	// advisories receives the consumer's max-deliveries advisories. Nil without a DLQ.
+	advisories *nc.Subscription
 }
 
 // JetStreamSubscriberOption configures the JetStreamSubscriberAdapter.
@@ -73,6 +139,46 @@ func WithMaxConcurrentAcks(max int) JetStreamSubscriberOption {
 	}
 }
 
+// WithoutDeadLetterQueue disables copying terminated and exhausted messages to DLQ streams.
+func WithoutDeadLetterQueue() JetStreamSubscriberOption {
+	return func(s *JetStreamSubscriberAdapter) {
+		s.deadLetters = nil
+	}
+}
+
+// WithSubscriberStreamTopology sets the topology used to resolve topics to streams.
+func WithSubscriberStreamTopology(topology *StreamTopology) JetStreamSubscriberOption {
+	return func(s *JetStreamSubscriberAdapter) {
+		if topology != nil {
+			s.topology = topology
+		}
+	}
+}
+
+// WithSubscriberTracer starts a consumer span for every delivered message, continuing
+// the producer's trace from the message headers.
+func WithSubscriberTracer(tracer trace.Tracer) JetStreamSubscriberOption {
+	return func(s *JetStreamSubscriberAdapter) {
+		s.tracer = tracer
+	}
+}
+
+// WithSubscriberMetrics reports delivery, settlement, concurrency and consumer lag metrics.
+func WithSubscriberMetrics(metrics eventbusmetrics.EventBusMetrics) JetStreamSubscriberOption {
+	return func(s *JetStreamSubscriberAdapter) {
+		if metrics != nil {
+			s.metrics = metrics
+		}
+	}
+}
+
+// WithTopicSubscribeOptions sets the SubscribeOptions that Subscribe uses for topic.
+func WithTopicSubscribeOptions(topic string, opts SubscribeOptions) JetStreamSubscriberOption {
+	return func(s *JetStreamSubscriberAdapter) {
+		s.topicOptions[topic] = opts
+	}
+}
+
 // NewJetStreamSubscriberAdapter creates a new subscriber adapter.
 func NewJetStreamSubscriberAdapter(
 	js jetstream.JetStream,
@@ -88,25 +194,47 @@ func NewJetStreamSubscriberAdapter(
 		logger:            logger,
 		maxConcurrentAcks: defaultMaxConcurrentAcks,
 		termination:       make(map[string]bool),
+		topicOptions:      make(map[string]SubscribeOptions),
+		topology:          DefaultStreamTopology(),
+		metrics:           eventbusmetrics.NewNoop(),
 	}
+	s.deadLetters = newDeadLetterQueue(js, logger)
 
 	for _, opt := range opts {
 		opt(s)
 	}
+	if s.deadLetters != nil {
+		s.deadLetters.topology = s.topology
+	}
 
 	return s
 }
 
 // Subscribe creates a subscription to the given topic.
 // It returns a channel of Watermill messages that integrates with Watermill routers.
-// The subscription uses a native JetStream pull consumer with bounded ACK concurrency.
+// The subscription uses a native JetStream pull consumer with bounded ACK concurrency,
+// tuned by any options registered for the topic with WithTopicSubscribeOptions.
 func (s *JetStreamSubscriberAdapter) Subscribe(
 	ctx context.Context,
 	topic string,
+) (<-chan *message.Message, error) {
+	return s.SubscribeWithOptions(ctx, topic, s.topicOptions[topic])
+}
+
+// SubscribeWithOptions creates a subscription to the given topic using opts instead of
+// the options registered for the topic.
+func (s *JetStreamSubscriberAdapter) SubscribeWithOptions(
+	ctx context.Context,
+	topic string,
+	opts SubscribeOptions,
 ) (<-chan *message.Message, error) {
 	if s.closed.Load() {
 		return nil, errors.New("subscriber is closed")
 	}
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid subscribe options for %s: %w", topic, err)
+	}
+	opts = opts.withDefaults(s.maxConcurrentAcks)
 
 	ctxLogger := s.logger.With(
 		"operation", "subscribe",
@@ -115,7 +243,7 @@ func (s *JetStreamSubscriberAdapter) Subscribe(
 	)
 
 	// Resolve stream from topic
-	streamName, err := ResolveStreamFromTopic(topic)
+	streamName, err := s.topology.ResolveStream(topic)
 	if err != nil {
 		ctxLogger.ErrorContext(ctx, "Failed to resolve stream from topic", "error", err)
 		return nil, err
@@ -128,19 +256,27 @@ func (s *JetStreamSubscriberAdapter) Subscribe(
 		return nil, err
 	}
 
-	// Create subscription context we control
-	subCtx, cancel := context.WithCancel(ctx)
+	// settleCtx bounds in-flight messages; subCtx, derived from it, bounds fetching so
+	// Shutdown can stop pulling while handlers finish.
+	settleCtx, abandon := context.WithCancel(ctx)
+	subCtx, cancel := context.WithCancel(settleCtx)
 
 	outputCh := make(chan *message.Message)
 	wg := &sync.WaitGroup{}
 
 	sub := &subscription{
-		topic:      topic,
-		consumer:   cons,
-		cancel:     cancel,
-		wg:         wg,
-		outputCh:   outputCh,
-		closedDone: make(chan struct{}),
+		topic:        topic,
+		consumerName: cons.CachedInfo().Name,
+		consumer:     cons,
+		opts:         opts,
+		cancel:       cancel,
+		abandon:      abandon,
+		wg:           wg,
+		outputCh:     outputCh,
+		closedDone:   make(chan struct{}),
+	}
+	if opts.Ordering == OrderingPartitioned {
+		sub.partitions = newPartitions()
 	}
 
 	// Track subscription for cleanup on Close()
@@ -148,26 +284,42 @@ func (s *JetStreamSubscriberAdapter) Subscribe(
 	s.subscriptions = append(s.subscriptions, sub)
 	s.mu.Unlock()
 
-	// Start the pull loop
-	go s.messagePump(subCtx, sub, ctxLogger)
+	s.watchExhaustedMessages(sub, streamName, ctxLogger)
+
+	// Start the pull loop and lag reporting
+	wg.Add(1)
+	go s.reportConsumerLag(subCtx, sub, ctxLogger)
+	go s.messagePump(subCtx, settleCtx, sub, ctxLogger)
 
 	ctxLogger.InfoContext(ctx, "Subscription created",
 		"stream", streamName,
 		"consumer", cons.CachedInfo().Name,
-		"max_concurrent_acks", s.maxConcurrentAcks,
+		"batch_size", opts.BatchSize,
+		"concurrency", opts.Concurrency,
+		"ordering", opts.Ordering.String(),
+		"partition_key", opts.PartitionKey,
 	)
 
 	return outputCh, nil
 }
 
 // messagePump reads messages from JetStream and sends them to the output channel.
-// It uses a semaphore to bound the number of concurrent ACK handling goroutines.
+// It uses a semaphore to bound the number of fetched messages awaiting settlement, and
+// hands keyed messages to their partition in OrderingPartitioned mode.
+// ctx stops fetching; settleCtx, which outlives it during Shutdown, bounds the wait for
+// in-flight messages to be acked.
 func (s *JetStreamSubscriberAdapter) messagePump(
 	ctx context.Context,
+	settleCtx context.Context,
 	sub *subscription,
 	logger *slog.Logger,
 ) {
 	defer func() {
+		// Stop the lag reporter in case the pump exits on its own.
+		sub.cancel()
+		if sub.advisories != nil {
+			_ = sub.advisories.Unsubscribe()
+		}
 		// Wait for all in-flight ACK goroutines to complete
 		sub.wg.Wait()
 
@@ -181,10 +333,10 @@ func (s *JetStreamSubscriberAdapter) messagePump(
 	}()
 
 	// Semaphore for bounded ACK concurrency
-	ackSem := make(chan struct{}, s.maxConcurrentAcks)
+	ackSem := make(chan struct{}, sub.opts.Concurrency)
 
 	// Resolve stream & consumer once (cheap, avoids repeated lookups)
-	streamName, err := ResolveStreamFromTopic(sub.topic)
+	streamName, err := s.topology.ResolveStream(sub.topic)
 	if err != nil {
 		logger.ErrorContext(ctx, "Failed to resolve stream", "error", err)
 		return
@@ -195,7 +347,6 @@ func (s *JetStreamSubscriberAdapter) messagePump(
 		logger.ErrorContext(ctx, "Failed to ensure consumer", "error", err)
 		return
 	}
-	cfg := s.consumerManager.GetRegistry().Resolve(s.appType, sub.topic)
 	consecutiveFetchErrors := 0
 
 	for {
@@ -207,12 +358,23 @@ func (s *JetStreamSubscriberAdapter) messagePump(
 		default:
 		}
 
-		// Issue an explicit pull request
+		// Hold while paused
+		if !sub.gate.wait(ctx) {
+			logger.DebugContext(ctx, "Context cancelled while paused, stopping message pump")
+			return
+		}
+
+		// Resolve per pull so runtime config updates apply to the next batch
+		cfg := s.consumerManager.GetRegistry().Resolve(s.appType, sub.topic)
+
+		// Issue an explicit pull request, cut short when fetching stops
+		fetchCtx, cancelFetch := context.WithTimeout(ctx, sub.opts.PullExpiry)
 		msgs, err := cons.Fetch(
-			pullBatchSize,
-			jetstream.FetchMaxWait(pullExpiry),
+			sub.opts.BatchSize,
+			jetstream.FetchContext(fetchCtx),
 		)
 		if err != nil {
+			cancelFetch()
 			if ctx.Err() != nil {
 				return
 			}
@@ -226,8 +388,8 @@ func (s *JetStreamSubscriberAdapter) messagePump(
 
 		// Iterate over fetched messages
 		for jsMsg := range msgs.Messages() {
-			// Convert to Watermill message
-			wmMsg, err := s.toWatermillMessage(ctx, jsMsg)
+			// Convert to Watermill message; handlers keep their context until settleCtx ends
+			wmMsg, err := s.toWatermillMessage(settleCtx, jsMsg)
 			if err != nil {
 				logger.ErrorContext(ctx, "Failed to convert message", "error", err)
 
@@ -237,34 +399,90 @@ func (s *JetStreamSubscriberAdapter) messagePump(
 				}
 				continue
 			}
+			span := s.startConsumerSpan(jsMsg, wmMsg)
 
 			// Acquire semaphore slot before emitting
-			select {
-			case ackSem <- struct{}{}:
-				// proceed
-			case <-ctx.Done():
+			if !s.acquireAckSlot(ctx, ackSem, sub) {
+				endConsumerSpan(span, settlementNak, nil)
 				if err := s.nakWithConfiguredDelay(jsMsg, cfg.BackOff); err != nil {
 					logger.ErrorContext(ctx, "Failed to nak during shutdown", "error", err)
 				}
+				cancelFetch()
 				return
 			}
 
 			sub.wg.Add(1)
+			d := &delivery{jsMsg: jsMsg, wmMsg: wmMsg, span: span}
+
+			// Keyed messages wait behind earlier messages with the same key
+			if sub.partitions != nil {
+				if key := wmMsg.Metadata.Get(sub.opts.PartitionKey); key != "" {
+					if sub.partitions.enqueue(key, d) {
+						go s.runPartition(ctx, settleCtx, sub, key, ackSem, cfg, logger)
+					}
+					continue
+				}
+			}
 
 			// Emit to Watermill
-			select {
-			case sub.outputCh <- wmMsg:
-				go s.waitForAck(ctx, jsMsg, wmMsg, sub, ackSem, logger)
-
-			case <-ctx.Done():
-				<-ackSem
-				sub.wg.Done()
-				if err := s.nakWithConfiguredDelay(jsMsg, cfg.BackOff); err != nil {
-					logger.ErrorContext(ctx, "Failed to nak during shutdown", "error", err)
-				}
+			if !s.emit(ctx, sub, d, ackSem, cfg.BackOff, logger) {
+				cancelFetch()
 				return
 			}
+			go s.waitForAck(settleCtx, jsMsg, wmMsg, span, sub, ackSem, logger)
 		}
+		cancelFetch()
+	}
+}
+
+// delivery is a fetched message that holds a concurrency slot until it is settled.
+type delivery struct {
+	jsMsg jetstream.Msg
+	wmMsg *message.Message
+	span  trace.Span
+}
+
+// emit hands d to the output channel. If ctx ends first, d is nacked, its slot released,
+// and emit returns false.
+func (s *JetStreamSubscriberAdapter) emit(
+	ctx context.Context,
+	sub *subscription,
+	d *delivery,
+	ackSem chan struct{},
+	backoff []time.Duration,
+	logger *slog.Logger,
+) bool {
+	if ctx.Err() == nil {
+		select {
+		case sub.outputCh <- d.wmMsg:
+			s.metrics.RecordInFlightMessages(ctx, sub.topic, sub.consumerName, 1)
+			if meta, err := d.jsMsg.Metadata(); err == nil {
+				s.metrics.RecordRedelivery(ctx, sub.topic, sub.consumerName, meta.NumDelivered)
+			}
+			return true
+		case <-ctx.Done():
+		}
+	}
+
+	s.releaseUnemitted(sub, d, ackSem, backoff, logger)
+	return false
+}
+
+// releaseUnemitted nacks a delivery that never reached a handler and frees its slot.
+func (s *JetStreamSubscriberAdapter) releaseUnemitted(
+	sub *subscription,
+	d *delivery,
+	ackSem chan struct{},
+	backoff []time.Duration,
+	logger *slog.Logger,
+) {
+	defer func() {
+		<-ackSem
+		sub.wg.Done()
+	}()
+	endConsumerSpan(d.span, settlementNak, nil)
+	if err := s.nakWithConfiguredDelay(d.jsMsg, backoff); err != nil {
+		logger.Error("Failed to nak during shutdown", "error", err, "message_id", d.wmMsg.UUID)
 	}
 }
 
@@ -273,11 +491,20 @@ func (s *JetStreamSubscriberAdapter) waitForAck(
 	ctx context.Context,
 	jsMsg jetstream.Msg,
 	wmMsg *message.Message,
+	span trace.Span,
 	sub *subscription,
 	ackSem chan struct{},
 	logger *slog.Logger,
 ) {
+	settlement := settlementTimeout
+	var settleErr error
+	emittedAt := time.Now()
 	defer func() {
+		endConsumerSpan(span, settlement, settleErr)
+		s.metrics.RecordInFlightMessages(ctx, sub.topic, sub.consumerName, -1)
+		s.metrics.RecordMessageSettlement(ctx, sub.topic, sub.consumerName, settlement)
+		s.metrics.RecordMessageProcess(ctx, sub.topic, settlement == settlementAck)
+		s.metrics.RecordMessageProcessingTime(ctx, sub.topic, time.Since(emittedAt))
 		// Release semaphore slot
 		<-ackSem
 		// Mark goroutine as done
@@ -295,6 +522,10 @@ func (s *JetStreamSubscriberAdapter) waitForAck(
 	for {
 		select {
 		case <-wmMsg.Acked():
+			settlement = settlementAck
+			if meta, err := jsMsg.Metadata(); err == nil {
+				s.metrics.RecordAckLatency(ctx, sub.topic, sub.consumerName, time.Since(meta.Timestamp))
+			}
 			if err := jsMsg.Ack(); err != nil {
 				logger.ErrorContext(ctx, "Failed to ack message", "error", err, "message_id", wmMsg.UUID)
 			} else {
@@ -303,8 +534,15 @@ func (s *JetStreamSubscriberAdapter) waitForAck(
 			return
 
 		case <-wmMsg.Nacked():
+			settlement, settleErr = settlementNak, nackError(wmMsg)
 			// Check if we should terminate instead of retry
-			if s.shouldTerminate(sub.topic) {
+			if s.shouldTerminate(sub.topic) || wmMsg.Metadata.Get(TerminateMetadataKey) != "" {
+				if err := s.deadLetter(ctx, jsMsg, wmMsg, DeadLetterReasonTerminated, logger); err != nil {
+					// Keep the message rather than lose it; its next delivery retries the DLQ.
+					s.nakAfterDeadLetterFailure(jsMsg, wmMsg, cfg.BackOff, logger)
+					return
+				}
+				settlement = settlementTerm
 				if err := jsMsg.Term(); err != nil {
 					logger.ErrorContext(ctx, "Failed to terminate message", "error", err, "message_id", wmMsg.UUID)
 				} else {
@@ -313,6 +551,21 @@ func (s *JetStreamSubscriberAdapter) waitForAck(
 				return
 			}
 
+			// Final delivery: JetStream will not redeliver, so preserve the message in the DLQ.
+			if s.deadLetters != nil && isFinalDelivery(jsMsg, cfg.MaxDeliver) {
+				if err := s.deadLetter(ctx, jsMsg, wmMsg, DeadLetterReasonMaxDeliver, logger); err != nil {
+					// JetStream will not redeliver, but the nak raises a max-deliveries
+					// advisory and watchExhaustedMessages retries the DLQ from the stream.
+					s.nakAfterDeadLetterFailure(jsMsg, wmMsg, cfg.BackOff, logger)
+					return
+				}
+				settlement = settlementTerm
+				if err := jsMsg.Term(); err != nil {
+					logger.ErrorContext(ctx, "Failed to terminate exhausted message", "error", err, "message_id", wmMsg.UUID)
+				}
+				return
+			}
+
 			if err := s.nakWithConfiguredDelay(jsMsg, cfg.BackOff); err != nil {
 				logger.ErrorContext(ctx, "Failed to nak message", "error", err, "message_id", wmMsg.UUID)
 			} else {
@@ -321,6 +574,7 @@ func (s *JetStreamSubscriberAdapter) waitForAck(
 			return
 
 		case <-heartbeatTicker.C:
+			s.metrics.RecordAckHeartbeat(ctx, sub.topic, sub.consumerName)
 			if err := jsMsg.InProgress(); err != nil {
 				logger.WarnContext(ctx, "Failed to extend in-progress ack deadline", "error", err, "message_id", wmMsg.UUID)
 			}
@@ -335,8 +589,11 @@ func (s *JetStreamSubscriberAdapter) waitForAck(
 			return
 
 		case <-ctx.Done():
-			// Graceful shutdown - use delayed NAK based on retry policy.
-			if err := s.nakWithConfiguredDelay(jsMsg, cfg.BackOff); err != nil {
+			settlement = settlementNak
+			// The handler did not finish before shutdown. Nak with a short delay so another
+			// replica picks the message up instead of waiting out the retry backoff.
+			s.recordAbandoned(sub, wmMsg)
+			if err := jsMsg.NakWithDelay(shutdownNakDelay); err != nil {
 				logger.ErrorContext(ctx, "Failed to nak message during shutdown", "error", err, "message_id", wmMsg.UUID)
 			}
 			return
@@ -344,6 +601,61 @@ func (s *JetStreamSubscriberAdapter) waitForAck(
 	}
 }
 
+func (s *JetStreamSubscriberAdapter) recordAbandoned(sub *subscription, msg *message.Message) {
+	s.abandonedMu.Lock()
+	defer s.abandonedMu.Unlock()
+	s.abandoned = append(s.abandoned, AbandonedMessage{
+		Topic:         sub.topic,
+		Consumer:      sub.consumerName,
+		MessageID:     msg.UUID,
+		CorrelationID: middleware.MessageCorrelationID(msg),
+	})
+}
+
+// acquireAckSlot reserves one of the subscription's concurrency slots, recording saturation
+// when the subscriber has to wait. It returns false if ctx ends first.
+func (s *JetStreamSubscriberAdapter) acquireAckSlot(ctx context.Context, ackSem chan struct{}, sub *subscription) bool {
+	select {
+	case ackSem <- struct{}{}:
+		return true
+	default:
+	}
+
+	s.metrics.RecordAckSemaphoreSaturation(ctx, sub.topic, sub.consumerName)
+	select {
+	case ackSem <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// reportConsumerLag polls consumer info for pending and ack-pending counts, and the
+// ack-pending ceiling, until ctx ends.
+func (s *JetStreamSubscriberAdapter) reportConsumerLag(ctx context.Context, sub *subscription, logger *slog.Logger) {
+	defer sub.wg.Done()
+
+	ticker := time.NewTicker(consumerLagPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := sub.consumer.Info(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					logger.DebugContext(ctx, "Failed to poll consumer info", "error", err)
+				}
+				continue
+			}
+			s.metrics.RecordConsumerLag(ctx, sub.topic, sub.consumerName, info.NumPending, uint64(info.NumAckPending))
+			s.metrics.RecordConsumerMaxAckPending(ctx, sub.topic, sub.consumerName, info.Config.MaxAckPending)
+		}
+	}
+}
+
 func ackHeartbeatAndDeadline(ackWait time.Duration) (time.Duration, time.Duration) {
 	if ackWait <= 0 {
 		ackWait = DefaultConsumerConfig().AckWait
@@ -428,6 +740,68 @@ func (s *JetStreamSubscriberAdapter) nakWithConfiguredDelay(jsMsg jetstream.Msg,
 	return jsMsg.NakWithDelay(backoff[idx])
 }
 
+// deadLetter copies a failed message into its domain DLQ. It returns an error when the
+// DLQ publish fails, so the caller keeps the message instead of terminating it.
+func (s *JetStreamSubscriberAdapter) deadLetter(
+	ctx context.Context,
+	jsMsg jetstream.Msg,
+	wmMsg *message.Message,
+	reason string,
+	logger *slog.Logger,
+) error {
+	if s.deadLetters == nil {
+		return nil
+	}
+	if err := s.deadLetters.publish(ctx, jsMsg, reason, wmMsg.Metadata.Get(LastErrorMetadataKey)); err != nil {
+		logger.ErrorContext(ctx, "Failed to dead-letter message", "error", err, "message_id", wmMsg.UUID, "reason", reason)
+		return err
+	}
+	logger.WarnContext(ctx, "Message moved to dead letter queue", "message_id", wmMsg.UUID, "reason", reason)
+	return nil
+}
+
+// nakAfterDeadLetterFailure nacks a message that could not be dead-lettered.
+func (s *JetStreamSubscriberAdapter) nakAfterDeadLetterFailure(
+	jsMsg jetstream.Msg,
+	wmMsg *message.Message,
+	backoff []time.Duration,
+	logger *slog.Logger,
+) {
+	if err := s.nakWithConfiguredDelay(jsMsg, backoff); err != nil {
+		logger.Error("Failed to nak message after dead letter failure", "error", err, "message_id", wmMsg.UUID)
+	}
+}
+
+// watchExhaustedMessages dead-letters messages of sub's consumer that run out of
+// deliveries without a final nack reaching the DLQ, such as ones whose AckWait expires
+// on the last delivery. Replicas share a queue group so each advisory is handled once.
+func (s *JetStreamSubscriberAdapter) watchExhaustedMessages(sub *subscription, streamName string, logger *slog.Logger) {
+	if s.deadLetters == nil || s.js.Conn() == nil {
+		return
+	}
+
+	subject := maxDeliveriesAdvisorySubject(streamName, sub.consumerName)
+	advisories, err := s.js.Conn().QueueSubscribe(subject, sub.consumerName, func(m *nc.Msg) {
+		var adv maxDeliveriesAdvisory
+		if err := json.Unmarshal(m.Data, &adv); err != nil {
+			logger.Warn("Ignoring malformed max-deliveries advisory", "error", err)
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), deadLetterAdvisoryTimeout)
+		defer cancel()
+		if err := s.deadLetters.deadLetterExhausted(ctx, adv); err != nil {
+			logger.Error("Failed to dead-letter exhausted message", "error", err, "stream_seq", adv.StreamSeq)
+			return
+		}
+		logger.Warn("Message moved to dead letter queue", "stream_seq", adv.StreamSeq, "reason", DeadLetterReasonMaxDeliver)
+	})
+	if err != nil {
+		logger.Warn("Failed to watch max-deliveries advisories, exhausted messages will not be dead-lettered", "error", err)
+		return
+	}
+	sub.advisories = advisories
+}
+
 // toWatermillMessage converts a JetStream message to a Watermill message.
 func (s *JetStreamSubscriberAdapter) toWatermillMessage(ctx context.Context, jsMsg jetstream.Msg) (*message.Message, error) {
 	// Use Nats-Msg-Id header as message ID, or generate one
@@ -446,6 +820,7 @@ func (s *JetStreamSubscriberAdapter) toWatermillMessage(ctx context.Context, jsM
 	}
 
 	// Add JetStream-specific metadata with _js_ prefix
+	wmMsg.Metadata.Set(SubjectMetadataKey, jsMsg.Subject())
 	if meta, err := jsMsg.Metadata(); err == nil {
 		wmMsg.Metadata.Set("_js_stream", meta.Stream)
 		wmMsg.Metadata.Set("_js_consumer", meta.Consumer)
@@ -491,31 +866,61 @@ func (s *JetStreamSubscriberAdapter) MarkForTermination(topic string, terminate
 	}
 }
 
-// Close gracefully shuts down the subscriber.
-// It cancels all subscription contexts, waits for in-flight ACK goroutines,
-// and closes all output channels.
+// Close stops all subscriptions immediately. In-flight messages are nacked for
+// prompt redelivery; use Shutdown to give handlers time to finish.
 func (s *JetStreamSubscriberAdapter) Close() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.Shutdown(ctx)
+	return nil
+}
+
+// Shutdown stops fetching on every subscription, then waits for in-flight messages to be
+// settled until ctx is done. Messages still in flight at that point are nacked with a
+// short delay so another replica can process them, and are returned.
+func (s *JetStreamSubscriberAdapter) Shutdown(ctx context.Context) []AbandonedMessage {
 	if !s.closed.CompareAndSwap(false, true) {
 		return nil // Already closed
 	}
 
-	s.logger.Info("Closing JetStream subscriber adapter")
+	s.logger.Info("Shutting down JetStream subscriber adapter")
 
 	s.mu.Lock()
 	subs := s.subscriptions
 	s.subscriptions = nil
 	s.mu.Unlock()
 
-	// Cancel all subscription contexts
+	// Stop fetching; in-flight handlers keep running
 	for _, sub := range subs {
 		sub.cancel()
 	}
 
-	// Wait for all subscriptions to finish
+	done := make(chan struct{})
+	go func() {
+		for _, sub := range subs {
+			<-sub.closedDone
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.logger.Warn("Shutdown deadline reached, abandoning in-flight messages")
+		for _, sub := range subs {
+			sub.abandon()
+		}
+		<-done
+	}
 	for _, sub := range subs {
-		<-sub.closedDone
+		sub.abandon()
 	}
 
-	s.logger.Info("JetStream subscriber adapter closed")
-	return nil
+	s.abandonedMu.Lock()
+	abandoned := s.abandoned
+	s.abandoned = nil
+	s.abandonedMu.Unlock()
+
+	s.logger.Info("JetStream subscriber adapter shut down", "abandoned_messages", len(abandoned))
+	return abandoned
 }
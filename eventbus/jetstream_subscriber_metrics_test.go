@@ -0,0 +1,137 @@
+package eventbus
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	eventbusmetrics "github.com/Black-And-White-Club/frolf-bot-shared/observability/otel/metrics/eventbus"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordingMetrics captures the subscriber metrics under test.
+type recordingMetrics struct {
+	eventbusmetrics.NoOpMetrics
+
+	mu          sync.Mutex
+	inFlight    int64
+	settlements []string
+	processed   []bool
+	redelivered int
+	ackLatency  int
+	saturations int
+}
+
+func (r *recordingMetrics) RecordInFlightMessages(_ context.Context, _, _ string, delta int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inFlight += delta
+}
+
+func (r *recordingMetrics) RecordMessageSettlement(_ context.Context, _, _ string, outcome string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.settlements = append(r.settlements, outcome)
+}
+
+func (r *recordingMetrics) RecordMessageProcess(_ context.Context, _ string, success bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.processed = append(r.processed, success)
+}
+
+func (r *recordingMetrics) RecordAckLatency(context.Context, string, string, time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ackLatency++
+}
+
+func (r *recordingMetrics) RecordAckSemaphoreSaturation(context.Context, string, string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.saturations++
+}
+
+func newMetricsTestAdapter(metrics eventbusmetrics.EventBusMetrics) *JetStreamSubscriberAdapter {
+	return &JetStreamSubscriberAdapter{
+		consumerManager: NewConsumerManager(nil, slog.New(slog.NewTextHandler(io.Discard, nil)), nil),
+		appType:         "backend",
+		logger:          slog.New(slog.NewTextHandler(io.Discard, nil)),
+		termination:     make(map[string]bool),
+		metrics:         metrics,
+	}
+}
+
+func TestWaitForAck_RecordsSettlementMetrics(t *testing.T) {
+	tests := []struct {
+		name    string
+		settle  func(*message.Message)
+		outcome string
+		success bool
+	}{
+		{name: "ack", settle: func(m *message.Message) { m.Ack() }, outcome: settlementAck, success: true},
+		{name: "nak", settle: func(m *message.Message) { m.Nack() }, outcome: settlementNak},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			metrics := &recordingMetrics{}
+			s := newMetricsTestAdapter(metrics)
+
+			jsMsg := &fakeJSMsg{
+				subject: "round.created.v1",
+				meta:    &jetstream.MsgMetadata{NumDelivered: 1, Timestamp: time.Now().Add(-time.Second)},
+			}
+			wmMsg := message.NewMessage("id", nil)
+			sub := &subscription{topic: "round.created.v1", consumerName: "backend-round-created-v1", wg: &sync.WaitGroup{}}
+			ackSem := make(chan struct{}, 1)
+			ackSem <- struct{}{}
+			sub.wg.Add(1)
+
+			metrics.RecordInFlightMessages(context.Background(), sub.topic, sub.consumerName, 1)
+			tc.settle(wmMsg)
+			s.waitForAck(context.Background(), jsMsg, wmMsg, trace.SpanFromContext(context.Background()), sub, ackSem, s.logger)
+
+			if metrics.inFlight != 0 {
+				t.Fatalf("expected in-flight to return to 0, got %d", metrics.inFlight)
+			}
+			if len(metrics.settlements) != 1 || metrics.settlements[0] != tc.outcome {
+				t.Fatalf("expected settlement %q, got %v", tc.outcome, metrics.settlements)
+			}
+			if len(metrics.processed) != 1 || metrics.processed[0] != tc.success {
+				t.Fatalf("expected processed success=%v, got %v", tc.success, metrics.processed)
+			}
+			if tc.success && metrics.ackLatency != 1 {
+				t.Fatalf("expected ack latency to be recorded once, got %d", metrics.ackLatency)
+			}
+		})
+	}
+}
+
+func TestAcquireAckSlot_RecordsSaturation(t *testing.T) {
+	metrics := &recordingMetrics{}
+	s := newMetricsTestAdapter(metrics)
+	sub := &subscription{topic: "round.created.v1", consumerName: "backend-round-created-v1"}
+	ackSem := make(chan struct{}, 1)
+
+	if !s.acquireAckSlot(context.Background(), ackSem, sub) {
+		t.Fatal("expected a free slot to be acquired")
+	}
+	if metrics.saturations != 0 {
+		t.Fatalf("expected no saturation, got %d", metrics.saturations)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if s.acquireAckSlot(ctx, ackSem, sub) {
+		t.Fatal("expected acquisition to fail once ctx is done")
+	}
+	if metrics.saturations != 1 {
+		t.Fatalf("expected saturation to be recorded, got %d", metrics.saturations)
+	}
+}
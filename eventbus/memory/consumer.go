@@ -0,0 +1,227 @@
+package memory
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/eventbus"
+	"github.com/Black-And-White-Club/frolf-bot-shared/observability/attr"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+// delivery is a stream message waiting for (re)delivery on a consumer.
+type delivery struct {
+	msg          storedMessage
+	numDelivered uint64
+}
+
+// consumer mirrors a JetStream pull consumer: a queue shared by every subscription
+// attached to it.
+type consumer struct {
+	name   string
+	stream string
+	filter string
+	cfg    eventbus.ConsumerConfig
+
+	mu     sync.Mutex
+	queue  []*delivery
+	paused bool
+	ready  chan struct{}
+}
+
+func newConsumer(name, streamName, filter string, cfg eventbus.ConsumerConfig) *consumer {
+	return &consumer{
+		name:   name,
+		stream: streamName,
+		filter: filter,
+		cfg:    cfg,
+		ready:  make(chan struct{}, 1),
+	}
+}
+
+func (c *consumer) enqueue(d *delivery) {
+	c.mu.Lock()
+	c.queue = append(c.queue, d)
+	c.mu.Unlock()
+	c.signal()
+}
+
+func (c *consumer) signal() {
+	select {
+	case c.ready <- struct{}{}:
+	default:
+	}
+}
+
+// config returns the consumer's current configuration.
+func (c *consumer) config() eventbus.ConsumerConfig {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cfg
+}
+
+// setConfig replaces the consumer's configuration for subsequent deliveries.
+func (c *consumer) setConfig(cfg eventbus.ConsumerConfig) {
+	c.mu.Lock()
+	c.cfg = cfg
+	c.mu.Unlock()
+}
+
+// setPaused reports whether the paused state changed. Resuming wakes waiting pumps.
+func (c *consumer) setPaused(paused bool) bool {
+	c.mu.Lock()
+	changed := c.paused != paused
+	c.paused = paused
+	c.mu.Unlock()
+	if changed && !paused {
+		c.signal()
+	}
+	return changed
+}
+
+// next blocks until a delivery is available and the consumer is not paused, or ctx is done.
+func (c *consumer) next(ctx context.Context) (*delivery, bool) {
+	for {
+		c.mu.Lock()
+		if !c.paused && len(c.queue) > 0 {
+			d := c.queue[0]
+			c.queue = c.queue[1:]
+			more := len(c.queue) > 0
+			c.mu.Unlock()
+			if more {
+				c.signal()
+			}
+			return d, true
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-c.ready:
+		}
+	}
+}
+
+// redeliver requeues d after the configured backoff for its delivery count.
+func (c *consumer) redeliver(d *delivery) {
+	cfg := c.config()
+	delay := time.Duration(0)
+	if n := len(cfg.BackOff); n > 0 {
+		idx := int(d.numDelivered) - 1
+		if idx < 0 {
+			idx = 0
+		}
+		if idx >= n {
+			idx = n - 1
+		}
+		delay = cfg.BackOff[idx]
+	}
+	if delay <= 0 {
+		c.enqueue(d)
+		return
+	}
+	time.AfterFunc(delay, func() { c.enqueue(d) })
+}
+
+func (c *consumer) exhausted(d *delivery) bool {
+	cfg := c.config()
+	return cfg.MaxDeliver > 0 && d.numDelivered >= uint64(cfg.MaxDeliver)
+}
+
+// pump delivers one message at a time to out and settles it before fetching the next.
+func (b *EventBus) pump(ctx context.Context, c *consumer, out chan *message.Message) {
+	defer b.wg.Done()
+	defer close(out)
+
+	for {
+		d, ok := c.next(ctx)
+		if !ok {
+			return
+		}
+		d.numDelivered++
+		msg := toMessage(ctx, c, d)
+
+		select {
+		case out <- msg:
+		case <-ctx.Done():
+			d.numDelivered--
+			c.enqueue(d)
+			return
+		}
+
+		if !b.awaitAck(ctx, c, d, msg) {
+			return
+		}
+	}
+}
+
+// awaitAck translates the handler's ack/nack into redelivery or dead-lettering.
+// It returns false when ctx ended while the message was in flight.
+func (b *EventBus) awaitAck(ctx context.Context, c *consumer, d *delivery, msg *message.Message) bool {
+	var ackTimeout <-chan time.Time
+	if ackWait := c.config().AckWait; ackWait > 0 {
+		timer := time.NewTimer(ackWait)
+		defer timer.Stop()
+		ackTimeout = timer.C
+	}
+
+	select {
+	case <-msg.Acked():
+		return true
+
+	case <-msg.Nacked():
+		if msg.Metadata.Get(eventbus.TerminateMetadataKey) != "" {
+			b.deadLetter(c, d, msg, eventbus.DeadLetterReasonTerminated)
+			return true
+		}
+		if c.exhausted(d) {
+			b.deadLetter(c, d, msg, eventbus.DeadLetterReasonMaxDeliver)
+			return true
+		}
+		c.redeliver(d)
+		return true
+
+	case <-ackTimeout:
+		b.logger.Warn("Ack wait exceeded",
+			attr.Topic(d.msg.subject),
+			attr.String("consumer", c.name),
+			attr.Uint64("deliveries", d.numDelivered),
+		)
+		if c.exhausted(d) {
+			b.deadLetter(c, d, msg, eventbus.DeadLetterReasonMaxDeliver)
+			return true
+		}
+		c.enqueue(d)
+		return true
+
+	case <-ctx.Done():
+		c.enqueue(d)
+		return false
+	}
+}
+
+// toMessage builds the Watermill message for a delivery with the same metadata the
+// JetStream subscriber sets.
+func toMessage(ctx context.Context, c *consumer, d *delivery) *message.Message {
+	msg := message.NewMessage(d.msg.metadata.Get("Nats-Msg-Id"), append([]byte(nil), d.msg.payload...))
+	msg.Metadata = copyMetadata(d.msg.metadata)
+
+	seq := strconv.FormatUint(d.msg.seq, 10)
+	delivered := strconv.FormatUint(d.numDelivered, 10)
+	msg.Metadata.Set(eventbus.SubjectMetadataKey, d.msg.subject)
+	msg.Metadata.Set("_js_stream", d.msg.stream)
+	msg.Metadata.Set("_js_consumer", c.name)
+	msg.Metadata.Set("_js_num_delivered", delivered)
+	msg.Metadata.Set("_js_stream_seq", seq)
+	msg.Metadata.Set("_js_timestamp", d.msg.published.Format(time.RFC3339Nano))
+	msg.Metadata.Set("stream", d.msg.stream)
+	msg.Metadata.Set("consumer", c.name)
+	msg.Metadata.Set("deliveries", delivered)
+	msg.Metadata.Set("stream_seq", seq)
+
+	msg.SetContext(ctx)
+	return msg
+}
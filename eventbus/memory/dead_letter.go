@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/eventbus"
+	"github.com/Black-And-White-Club/frolf-bot-shared/observability/attr"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+// deadLetter records a failed delivery under its stream's domain.
+func (b *EventBus) deadLetter(c *consumer, d *delivery, msg *message.Message, reason string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	domain := d.msg.stream
+	b.deadLetterSeq[domain]++
+	b.deadLetters[domain] = append(b.deadLetters[domain], eventbus.DeadLetter{
+		Sequence:         b.deadLetterSeq[domain],
+		Domain:           domain,
+		OriginalSubject:  d.msg.subject,
+		OriginalStream:   d.msg.stream,
+		OriginalSequence: d.msg.seq,
+		Consumer:         c.name,
+		DeliveryCount:    d.numDelivered,
+		Reason:           reason,
+		LastError:        msg.Metadata.Get(eventbus.LastErrorMetadataKey),
+		FailedAt:         b.now().UTC(),
+		Headers:          copyMetadata(d.msg.metadata),
+		Payload:          d.msg.payload,
+	})
+
+	b.logger.Warn("Message moved to dead letter queue",
+		attr.String("message_id", msg.UUID),
+		attr.String("reason", reason),
+		attr.String("consumer", c.name),
+	)
+}
+
+// ListDeadLetters returns up to limit dead letters for domain, oldest first. limit <= 0 returns all.
+func (b *EventBus) ListDeadLetters(ctx context.Context, domain string, limit int) ([]eventbus.DeadLetter, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entries := b.deadLetters[domain]
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return append([]eventbus.DeadLetter(nil), entries...), nil
+}
+
+// GetDeadLetter returns a single dead letter by its DLQ sequence.
+func (b *EventBus) GetDeadLetter(ctx context.Context, domain string, seq uint64) (*eventbus.DeadLetter, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, dl := range b.deadLetters[domain] {
+		if dl.Sequence == seq {
+			return &dl, nil
+		}
+	}
+	return nil, fmt.Errorf("dead letter %d not found for %s", seq, domain)
+}
+
+// ReplayDeadLetter republishes a dead letter onto its original subject and removes it
+// from the DLQ. The replayed message is flagged with dead_letter=true.
+func (b *EventBus) ReplayDeadLetter(ctx context.Context, domain string, seq uint64) error {
+	dl, err := b.GetDeadLetter(ctx, domain, seq)
+	if err != nil {
+		return err
+	}
+
+	msg := message.NewMessage(dl.Headers["Nats-Msg-Id"], dl.Payload)
+	for k, v := range dl.Headers {
+		msg.Metadata.Set(k, v)
+	}
+	msg.Metadata.Set(eventbus.DeadLetterMetadataKey, "true")
+	msg.Metadata.Set("Nats-Msg-Id", fmt.Sprintf("replay-%s-%d", eventbus.DeadLetterStreamName(domain), seq))
+	msg.SetContext(ctx)
+
+	if _, err := b.publishToTopic(dl.OriginalSubject, []*message.Message{msg}); err != nil {
+		return fmt.Errorf("failed to replay dead letter %d: %w", seq, err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entries := b.deadLetters[domain]
+	for i := range entries {
+		if entries[i].Sequence == seq {
+			b.deadLetters[domain] = append(entries[:i:i], entries[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
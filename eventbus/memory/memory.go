@@ -0,0 +1,527 @@
+// Package memory provides an in-process eventbus.EventBus for unit and integration tests.
+//
+// It keeps the semantics services rely on from the NATS implementation: topics resolve
+// to streams through the stream topology, the discord and inbox boundary guards apply,
+// Nats-Msg-Id is deduplicated within the stream's duplicates window, and nacked messages
+// are redelivered until MaxDeliver, after which they land in the dead-letter list.
+// Multi-handler flows (round creation → scheduled → created) run without a NATS server:
+//
+//	bus := memory.New(eventbus.AppTypeBackend)
+//	defer bus.Close()
+//	router.AddHandler("create", roundevents.RoundCreateRequestedV1, bus, "", bus, handler)
+//
+// Inbox subjects are accepted but dropped, as core NATS does with no requester listening;
+// GetNATSConnection and GetJetStream return nil.
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/eventbus"
+	"github.com/Black-And-White-Club/frolf-bot-shared/observability/attr"
+	"github.com/ThreeDotsLabs/watermill/message"
+	nc "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// defaultDuplicatesWindow matches the JetStream default for streams without one.
+const defaultDuplicatesWindow = 2 * time.Minute
+
+var inboxSubjectPattern = regexp.MustCompile(`^_INBOX\.[A-Za-z0-9._-]+$`)
+
+// ErrClosed is returned by operations on a closed bus.
+var ErrClosed = errors.New("memory eventbus is closed")
+
+var _ eventbus.EventBus = (*EventBus)(nil)
+
+// DefaultConsumerConfig is the consumer configuration used when none is supplied:
+// five deliveries, immediate redelivery and no ack deadline, so tests run fast.
+func DefaultConsumerConfig() eventbus.ConsumerConfig {
+	return eventbus.ConsumerConfig{
+		MaxDeliver:    5,
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+	}
+}
+
+// Option configures an in-memory EventBus.
+type Option func(*EventBus)
+
+// WithLogger sets the logger. Logs are discarded by default.
+func WithLogger(logger *slog.Logger) Option {
+	return func(b *EventBus) {
+		b.logger = logger
+	}
+}
+
+// WithStreamTopology replaces the default stream topology used to route topics.
+func WithStreamTopology(topology *eventbus.StreamTopology) Option {
+	return func(b *EventBus) {
+		b.topology = topology
+	}
+}
+
+// WithConsumerConfigRegistry sets per-app and per-topic consumer configuration.
+// AckWait, MaxDeliver, BackOff and DeliverPolicy are honored.
+func WithConsumerConfigRegistry(registry *eventbus.ConsumerConfigRegistry) Option {
+	return func(b *EventBus) {
+		b.consumerConfigs = registry
+	}
+}
+
+// WithDuplicatesWindow overrides the Nats-Msg-Id dedupe window of every stream.
+func WithDuplicatesWindow(window time.Duration) Option {
+	return func(b *EventBus) {
+		b.duplicatesWindow = window
+	}
+}
+
+// EventBus is an in-memory eventbus.EventBus. Create it with New.
+type EventBus struct {
+	appType          string
+	topology         *eventbus.StreamTopology
+	consumerConfigs  *eventbus.ConsumerConfigRegistry
+	duplicatesWindow time.Duration
+	logger           *slog.Logger
+	now              func() time.Time
+
+	mu             sync.Mutex
+	closed         bool
+	createdStreams map[string]bool
+	streams        map[string]*stream
+	consumers      map[string]*consumer
+	ephemeralSeq   uint64
+	cancels        []context.CancelFunc
+	scheduled      map[string]*time.Timer
+	deadLetters    map[string][]eventbus.DeadLetter
+	deadLetterSeq  map[string]uint64
+	wg             sync.WaitGroup
+}
+
+// New creates an in-memory event bus acting as appType.
+func New(appType string, opts ...Option) *EventBus {
+	registry := eventbus.NewConsumerConfigRegistry()
+	registry.SetDefault(DefaultConsumerConfig())
+
+	b := &EventBus{
+		appType:         appType,
+		topology:        eventbus.DefaultStreamTopology(),
+		consumerConfigs: registry,
+		logger:          slog.New(slog.NewTextHandler(io.Discard, nil)),
+		now:             time.Now,
+		createdStreams:  make(map[string]bool),
+		streams:         make(map[string]*stream),
+		consumers:       make(map[string]*consumer),
+		scheduled:       make(map[string]*time.Timer),
+		deadLetters:     make(map[string][]eventbus.DeadLetter),
+		deadLetterSeq:   make(map[string]uint64),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// storedMessage is a message as persisted in a stream.
+type storedMessage struct {
+	seq       uint64
+	stream    string
+	subject   string
+	payload   []byte
+	metadata  message.Metadata
+	published time.Time
+}
+
+// dedupeEntry records when a Nats-Msg-Id was first stored, and at which sequence.
+type dedupeEntry struct {
+	at  time.Time
+	seq uint64
+}
+
+type stream struct {
+	name       string
+	lastSeq    uint64
+	messages   []storedMessage
+	dedupe     map[string]dedupeEntry
+	duplicates time.Duration
+}
+
+// Publish stores messages on their streams and fans them out to matching consumers.
+// When topic is empty, each message is routed by its metadata topic.
+func (b *EventBus) Publish(topic string, messages ...*message.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	if topic != "" {
+		_, err := b.publishToTopic(topic, messages)
+		return err
+	}
+
+	topicGroups := make(map[string][]*message.Message)
+	for _, msg := range messages {
+		msgTopic := eventbus.ResolveMessageTopic(msg)
+		if msgTopic == "" {
+			return fmt.Errorf("message %s has no topic in metadata", msg.UUID)
+		}
+		topicGroups[msgTopic] = append(topicGroups[msgTopic], msg)
+	}
+
+	var errs []error
+	for t, msgs := range topicGroups {
+		if _, err := b.publishToTopic(t, msgs); err != nil {
+			errs = append(errs, fmt.Errorf("topic %s: %w", t, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// PublishAsync publishes messages synchronously and returns already resolved futures.
+func (b *EventBus) PublishAsync(ctx context.Context, topic string, messages ...*message.Message) ([]*eventbus.PublishFuture, error) {
+	acks, err := b.PublishBatch(ctx, topic, messages...)
+	if err != nil {
+		return nil, err
+	}
+	futures := make([]*eventbus.PublishFuture, len(acks))
+	for i, ack := range acks {
+		futures[i] = eventbus.ResolvedPublishFuture(ack, nil)
+	}
+	return futures, nil
+}
+
+// PublishBatch publishes messages to topic and returns their acks in order.
+func (b *EventBus) PublishBatch(ctx context.Context, topic string, messages ...*message.Message) ([]eventbus.PublishAck, error) {
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	if topic == "" {
+		return nil, errors.New("async publish requires a topic")
+	}
+	if strings.HasPrefix(topic, "_INBOX.") {
+		return nil, fmt.Errorf("async publish to inbox topic %q is not supported", topic)
+	}
+	return b.publishToTopic(topic, messages)
+}
+
+// publishToTopic stores messages and returns their acks, which are empty for inbox topics.
+func (b *EventBus) publishToTopic(topic string, messages []*message.Message) ([]eventbus.PublishAck, error) {
+	if strings.HasPrefix(topic, "discord.") && b.appType != eventbus.AppTypeDiscord {
+		return nil, fmt.Errorf("publishing to discord topics forbidden for app %q: %w", b.appType, eventbus.ErrPublishForbidden)
+	}
+	if strings.HasPrefix(topic, "_INBOX.") {
+		return nil, b.validateInboxPublish(topic, messages)
+	}
+
+	streamName, err := b.topology.ResolveStream(topic)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, ErrClosed
+	}
+
+	st := b.streamLocked(streamName)
+	now := b.now()
+	acks := make([]eventbus.PublishAck, 0, len(messages))
+	for _, msg := range messages {
+		if msg.Metadata.Get("Nats-Msg-Id") == "" {
+			if key := msg.Metadata.Get("idempotency_key"); key != "" {
+				msg.Metadata.Set("Nats-Msg-Id", eventbus.DedupeMsgID(key, topic))
+			} else {
+				msg.Metadata.Set("Nats-Msg-Id", msg.UUID)
+			}
+		}
+		msgID := msg.Metadata.Get("Nats-Msg-Id")
+		if seen, ok := st.dedupe[msgID]; ok && now.Sub(seen.at) < st.duplicates {
+			b.logger.Debug("Duplicate message dropped", attr.Topic(topic), attr.String("nats_msg_id", msgID))
+			acks = append(acks, eventbus.PublishAck{MessageID: msg.UUID, Stream: streamName, Sequence: seen.seq, Duplicate: true})
+			continue
+		}
+
+		st.lastSeq++
+		st.dedupe[msgID] = dedupeEntry{at: now, seq: st.lastSeq}
+		acks = append(acks, eventbus.PublishAck{MessageID: msg.UUID, Stream: streamName, Sequence: st.lastSeq})
+		stored := storedMessage{
+			seq:       st.lastSeq,
+			stream:    streamName,
+			subject:   topic,
+			payload:   append([]byte(nil), msg.Payload...),
+			metadata:  copyMetadata(msg.Metadata),
+			published: now,
+		}
+		st.messages = append(st.messages, stored)
+
+		for _, c := range b.consumers {
+			if c.stream == streamName && eventbus.SubjectMatches(c.filter, topic) {
+				c.enqueue(&delivery{msg: stored})
+			}
+		}
+	}
+	return acks, nil
+}
+
+func (b *EventBus) validateInboxPublish(topic string, messages []*message.Message) error {
+	if b.appType != eventbus.AppTypeBackend {
+		return fmt.Errorf("publishing to inbox topics forbidden for app %q: %w", b.appType, eventbus.ErrPublishForbidden)
+	}
+	if !inboxSubjectPattern.MatchString(topic) {
+		return fmt.Errorf("invalid inbox topic format")
+	}
+	for i, msg := range messages {
+		if msg == nil {
+			return fmt.Errorf("message[%d] is nil", i)
+		}
+	}
+	return nil
+}
+
+// streamLocked returns the named stream, creating its state on first use. b.mu must be held.
+func (b *EventBus) streamLocked(name string) *stream {
+	if st, ok := b.streams[name]; ok {
+		return st
+	}
+	window := b.duplicatesWindow
+	if window == 0 {
+		if def, ok := b.topology.Get(name); ok {
+			window = def.Duplicates
+		}
+	}
+	if window == 0 {
+		window = defaultDuplicatesWindow
+	}
+	st := &stream{name: name, dedupe: make(map[string]dedupeEntry), duplicates: window}
+	b.streams[name] = st
+	return st
+}
+
+// Messages returns every message stored for topic (wildcards allowed), oldest first.
+// It is a test helper for asserting on what a flow published.
+func (b *EventBus) Messages(topic string) []*message.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var out []*message.Message
+	for _, st := range b.streams {
+		for _, stored := range st.messages {
+			if eventbus.SubjectMatches(topic, stored.subject) {
+				msg := message.NewMessage(stored.metadata.Get("Nats-Msg-Id"), stored.payload)
+				msg.Metadata = copyMetadata(stored.metadata)
+				out = append(out, msg)
+			}
+		}
+	}
+	return out
+}
+
+// Subscribe attaches to the app's durable consumer for topic. Subscribing twice to the
+// same topic shares the consumer, so messages are load-balanced like queue groups.
+func (b *EventBus) Subscribe(ctx context.Context, topic string) (<-chan *message.Message, error) {
+	cfg := b.consumerConfigs.Resolve(b.appType, topic)
+	return b.subscribe(ctx, topic, consumerName(b.appType, topic), cfg)
+}
+
+// SubscribeWithOptions validates opts and subscribes like Subscribe. Subscriptions
+// already handle one message at a time, which satisfies every ordering mode.
+func (b *EventBus) SubscribeWithOptions(ctx context.Context, topic string, opts eventbus.SubscribeOptions) (<-chan *message.Message, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid subscribe options for %s: %w", topic, err)
+	}
+	return b.Subscribe(ctx, topic)
+}
+
+// PauseSubscription stops delivering from the app's durable consumer for topic.
+// A message already in flight is still settled.
+func (b *EventBus) PauseSubscription(topic string) error {
+	return b.setPaused(topic, true)
+}
+
+// ResumeSubscription restarts delivery for a topic paused with PauseSubscription.
+func (b *EventBus) ResumeSubscription(topic string) error {
+	return b.setPaused(topic, false)
+}
+
+func (b *EventBus) setPaused(topic string, paused bool) error {
+	b.mu.Lock()
+	c, ok := b.consumers[consumerName(b.appType, topic)]
+	b.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w for topic %s", eventbus.ErrSubscriptionNotFound, topic)
+	}
+	if c.setPaused(paused) {
+		b.logger.Info("Subscription paused or resumed",
+			attr.Topic(topic),
+			attr.String("consumer", c.name),
+			attr.Bool("paused", paused),
+		)
+	}
+	return nil
+}
+
+// UpdateConsumerConfig sets cfg for topic and applies it to the app's durable consumer
+// for subsequent deliveries.
+func (b *EventBus) UpdateConsumerConfig(ctx context.Context, topic string, cfg eventbus.ConsumerConfig) error {
+	b.consumerConfigs.SetForTopic(topic, cfg)
+
+	b.mu.Lock()
+	c, ok := b.consumers[consumerName(b.appType, topic)]
+	b.mu.Unlock()
+	if ok {
+		c.setConfig(cfg)
+	}
+	return nil
+}
+
+// SubscribeForTest creates an ephemeral consumer that only sees messages published
+// after the call and delivers each message once.
+func (b *EventBus) SubscribeForTest(ctx context.Context, topic string) (<-chan *message.Message, error) {
+	b.mu.Lock()
+	b.ephemeralSeq++
+	name := fmt.Sprintf("ephemeral-%d", b.ephemeralSeq)
+	b.mu.Unlock()
+
+	return b.subscribe(ctx, topic, name, eventbus.ConsumerConfig{
+		MaxDeliver:    1,
+		DeliverPolicy: jetstream.DeliverNewPolicy,
+	})
+}
+
+func (b *EventBus) subscribe(ctx context.Context, topic, name string, cfg eventbus.ConsumerConfig) (<-chan *message.Message, error) {
+	if strings.HasPrefix(topic, "discord.") && b.appType != eventbus.AppTypeDiscord {
+		return nil, fmt.Errorf("subscription to discord topics forbidden for app %q", b.appType)
+	}
+
+	streamName, err := b.topology.ResolveStream(topic)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, ErrClosed
+	}
+	b.createdStreams[streamName] = true
+
+	c, ok := b.consumers[name]
+	if !ok {
+		c = newConsumer(name, streamName, topic, cfg)
+		if cfg.DeliverPolicy != jetstream.DeliverNewPolicy {
+			for _, stored := range b.streamLocked(streamName).messages {
+				if eventbus.SubjectMatches(topic, stored.subject) {
+					c.enqueue(&delivery{msg: stored})
+				}
+			}
+		}
+		b.consumers[name] = c
+	}
+
+	subCtx, cancel := context.WithCancel(ctx)
+	b.cancels = append(b.cancels, cancel)
+
+	out := make(chan *message.Message)
+	b.wg.Add(1)
+	go b.pump(subCtx, c, out)
+	return out, nil
+}
+
+// Close stops all subscriptions and pending schedules. In-flight messages are requeued.
+func (b *EventBus) Close() error {
+	return b.Shutdown(context.Background())
+}
+
+// Shutdown stops all subscriptions and pending schedules, requeueing in-flight messages,
+// and waits for subscription goroutines to exit until ctx is done.
+func (b *EventBus) Shutdown(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	for _, cancel := range b.cancels {
+		cancel()
+	}
+	for key, timer := range b.scheduled {
+		timer.Stop()
+		delete(b.scheduled, key)
+	}
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// GetNATSConnection returns nil; there is no NATS connection behind the in-memory bus.
+func (b *EventBus) GetNATSConnection() *nc.Conn {
+	return nil
+}
+
+// GetJetStream returns nil; there is no JetStream context behind the in-memory bus.
+func (b *EventBus) GetJetStream() jetstream.JetStream {
+	return nil
+}
+
+// GetHealthCheckers returns a checker that fails once the bus is closed.
+func (b *EventBus) GetHealthCheckers() []eventbus.HealthChecker {
+	return []eventbus.HealthChecker{healthChecker{bus: b}}
+}
+
+// CreateStream validates streamName against the topology. Streams hold no state until
+// the first publish, so there is nothing else to create.
+func (b *EventBus) CreateStream(ctx context.Context, streamName string) error {
+	if _, ok := b.topology.Get(streamName); !ok {
+		return fmt.Errorf("unknown stream name: %s", streamName)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.createdStreams[streamName] = true
+	return nil
+}
+
+type healthChecker struct {
+	bus *EventBus
+}
+
+func (h healthChecker) Check(ctx context.Context) error {
+	h.bus.mu.Lock()
+	defer h.bus.mu.Unlock()
+	if h.bus.closed {
+		return ErrClosed
+	}
+	return nil
+}
+
+func (h healthChecker) Name() string {
+	return "Memory EventBus"
+}
+
+func consumerName(appType, topic string) string {
+	sanitized := strings.NewReplacer(".", "-", "*", "star", ">", "all").Replace(topic)
+	return fmt.Sprintf("%s-%s", appType, sanitized)
+}
+
+func copyMetadata(md message.Metadata) message.Metadata {
+	out := make(message.Metadata, len(md))
+	for k, v := range md {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,374 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/eventbus"
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+func receive(t *testing.T, ch <-chan *message.Message) *message.Message {
+	t.Helper()
+	select {
+	case msg := <-ch:
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for message")
+		return nil
+	}
+}
+
+func expectNone(t *testing.T, ch <-chan *message.Message) {
+	t.Helper()
+	select {
+	case msg := <-ch:
+		t.Fatalf("unexpected message %s", msg.UUID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestPublishSubscribe_DeliversBacklogAndDedupes(t *testing.T) {
+	bus := New(eventbus.AppTypeBackend)
+	defer bus.Close()
+
+	first := message.NewMessage("1", []byte(`{"n":1}`))
+	first.Metadata.Set("idempotency_key", "round-1")
+	if err := bus.Publish("round.created.v1", first); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Same idempotency key within the duplicates window is dropped.
+	dup := message.NewMessage("2", []byte(`{"n":2}`))
+	dup.Metadata.Set("idempotency_key", "round-1")
+	if err := bus.Publish("round.created.v1", dup); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ch, err := bus.Subscribe(context.Background(), "round.created.v1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msg := receive(t, ch)
+	if string(msg.Payload) != `{"n":1}` {
+		t.Fatalf("unexpected payload %s", msg.Payload)
+	}
+	if msg.Metadata.Get("_js_stream") != "round" || msg.Metadata.Get("_js_num_delivered") != "1" {
+		t.Fatalf("unexpected metadata %v", msg.Metadata)
+	}
+	msg.Ack()
+	expectNone(t, ch)
+}
+
+func TestPublish_EnforcesBoundaryGuards(t *testing.T) {
+	bus := New(eventbus.AppTypeBackend)
+	defer bus.Close()
+
+	if err := bus.Publish("discord.round.created.v1", message.NewMessage("1", nil)); err == nil {
+		t.Fatal("expected discord publish from backend to be rejected")
+	}
+	if _, err := bus.Subscribe(context.Background(), "discord.round.created.v1"); err == nil {
+		t.Fatal("expected discord subscribe from backend to be rejected")
+	}
+	if err := bus.Publish("_INBOX.abc", message.NewMessage("1", nil)); err != nil {
+		t.Fatalf("expected backend inbox publish to succeed, got %v", err)
+	}
+	if err := bus.Publish("unknown.topic.v1", message.NewMessage("1", nil)); err == nil {
+		t.Fatal("expected unroutable topic to be rejected")
+	}
+
+	discord := New(eventbus.AppTypeDiscord)
+	defer discord.Close()
+	if err := discord.Publish("_INBOX.abc", message.NewMessage("1", nil)); err == nil {
+		t.Fatal("expected discord inbox publish to be rejected")
+	}
+}
+
+func TestNack_RedeliversUntilMaxDeliverThenDeadLetters(t *testing.T) {
+	registry := eventbus.NewConsumerConfigRegistry()
+	registry.SetDefault(eventbus.ConsumerConfig{MaxDeliver: 3})
+	bus := New(eventbus.AppTypeBackend, WithConsumerConfigRegistry(registry))
+	defer bus.Close()
+
+	ch, err := bus.Subscribe(context.Background(), "score.process.v1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := bus.Publish("score.process.v1", message.NewMessage("1", []byte(`{}`))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		msg := receive(t, ch)
+		if got := msg.Metadata.Get("_js_num_delivered"); got != strconv.Itoa(i) {
+			t.Fatalf("expected delivery %d, got %s", i, got)
+		}
+		msg.Metadata.Set(eventbus.LastErrorMetadataKey, "boom")
+		msg.Nack()
+	}
+	expectNone(t, ch)
+
+	dls, err := bus.ListDeadLetters(context.Background(), "score", 0)
+	if err != nil || len(dls) != 1 {
+		t.Fatalf("expected 1 dead letter, got %v (err %v)", dls, err)
+	}
+	dl := dls[0]
+	if dl.Reason != eventbus.DeadLetterReasonMaxDeliver || dl.DeliveryCount != 3 || dl.LastError != "boom" {
+		t.Fatalf("unexpected dead letter %+v", dl)
+	}
+
+	if err := bus.ReplayDeadLetter(context.Background(), "score", dl.Sequence); err != nil {
+		t.Fatalf("unexpected replay error: %v", err)
+	}
+	replayed := receive(t, ch)
+	if replayed.Metadata.Get(eventbus.DeadLetterMetadataKey) != "true" {
+		t.Fatalf("expected replayed message to be flagged, got %v", replayed.Metadata)
+	}
+	replayed.Ack()
+	if dls, _ := bus.ListDeadLetters(context.Background(), "score", 0); len(dls) != 0 {
+		t.Fatalf("expected replayed dead letter to be removed, got %v", dls)
+	}
+}
+
+func TestAckWait_ExhaustedMessageIsDeadLettered(t *testing.T) {
+	registry := eventbus.NewConsumerConfigRegistry()
+	registry.SetDefault(eventbus.ConsumerConfig{MaxDeliver: 2, AckWait: 20 * time.Millisecond})
+	bus := New(eventbus.AppTypeBackend, WithConsumerConfigRegistry(registry))
+	defer bus.Close()
+
+	ch, err := bus.Subscribe(context.Background(), "score.process.v1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := bus.Publish("score.process.v1", message.NewMessage("1", []byte(`{}`))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Neither delivery is settled, so both run into the ack wait.
+	receive(t, ch)
+	receive(t, ch)
+	expectNone(t, ch)
+
+	dls, err := bus.ListDeadLetters(context.Background(), "score", 0)
+	if err != nil || len(dls) != 1 {
+		t.Fatalf("expected 1 dead letter, got %v (err %v)", dls, err)
+	}
+	if dl := dls[0]; dl.Reason != eventbus.DeadLetterReasonMaxDeliver || dl.DeliveryCount != 2 {
+		t.Fatalf("unexpected dead letter %+v", dl)
+	}
+}
+
+func TestNack_TerminatedMessageIsDeadLetteredImmediately(t *testing.T) {
+	bus := New(eventbus.AppTypeBackend)
+	defer bus.Close()
+
+	ch, _ := bus.Subscribe(context.Background(), "user.created.v1")
+	_ = bus.Publish("user.created.v1", message.NewMessage("1", []byte(`not json`)))
+
+	msg := receive(t, ch)
+	eventbus.TerminateMessage(msg, "malformed payload")
+	msg.Nack()
+	expectNone(t, ch)
+
+	dl, err := bus.GetDeadLetter(context.Background(), "user", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dl.Reason != eventbus.DeadLetterReasonTerminated || dl.LastError != "malformed payload" {
+		t.Fatalf("unexpected dead letter %+v", dl)
+	}
+}
+
+func TestSubscribe_SharedConsumerLoadBalances(t *testing.T) {
+	bus := New(eventbus.AppTypeBackend)
+	defer bus.Close()
+
+	a, _ := bus.Subscribe(context.Background(), "guild.config.updated.v1")
+	b, _ := bus.Subscribe(context.Background(), "guild.config.updated.v1")
+	test, _ := bus.SubscribeForTest(context.Background(), "guild.config.updated.v1")
+
+	for i := 0; i < 2; i++ {
+		_ = bus.Publish("guild.config.updated.v1", message.NewMessage(watermill.NewUUID(), nil))
+	}
+
+	got := 0
+	timeout := time.After(2 * time.Second)
+	for got < 2 {
+		select {
+		case msg := <-a:
+			msg.Ack()
+			got++
+		case msg := <-b:
+			msg.Ack()
+			got++
+		case <-timeout:
+			t.Fatalf("expected 2 deliveries across subscribers, got %d", got)
+		}
+	}
+	expectNone(t, a)
+	expectNone(t, b)
+
+	// The ephemeral test consumer sees every message independently.
+	receive(t, test).Ack()
+	receive(t, test).Ack()
+}
+
+func TestPauseSubscription_HoldsDeliveryUntilResumed(t *testing.T) {
+	bus := New(eventbus.AppTypeBackend)
+	defer bus.Close()
+
+	if err := bus.PauseSubscription("round.created.v1"); !errors.Is(err, eventbus.ErrSubscriptionNotFound) {
+		t.Fatalf("expected ErrSubscriptionNotFound, got %v", err)
+	}
+
+	ch, err := bus.Subscribe(context.Background(), "round.created.v1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := bus.PauseSubscription("round.created.v1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := bus.Publish("round.created.v1", message.NewMessage("1", []byte(`{}`))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectNone(t, ch)
+
+	if err := bus.ResumeSubscription("round.created.v1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	receive(t, ch).Ack()
+}
+
+func TestScheduleAt_PublishesWhenDueAndCancels(t *testing.T) {
+	bus := New(eventbus.AppTypeBackend)
+	defer bus.Close()
+
+	ch, _ := bus.Subscribe(context.Background(), "round.reminder.v1")
+
+	due := message.NewMessage("due", []byte(`{}`))
+	if err := bus.ScheduleAt(context.Background(), "round.reminder.v1", due, time.Now().Add(20*time.Millisecond)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cancelled := message.NewMessage("cancelled", []byte(`{}`))
+	cancelled.Metadata.Set(eventbus.ScheduleKeyMetadataKey, "round-1-reminder")
+	if err := bus.ScheduleAt(context.Background(), "round.reminder.v1", cancelled, time.Now().Add(20*time.Millisecond)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := bus.CancelScheduled(context.Background(), "round-1-reminder"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msg := receive(t, ch)
+	if msg.UUID != "due" {
+		t.Fatalf("expected the due message, got %s", msg.UUID)
+	}
+	msg.Ack()
+	expectNone(t, ch)
+}
+
+func TestScheduleAt_SanitizesKeysLikeNATS(t *testing.T) {
+	bus := New(eventbus.AppTypeBackend)
+	defer bus.Close()
+
+	ch, _ := bus.Subscribe(context.Background(), "round.reminder.v1")
+
+	first := message.NewMessage("first", []byte(`{}`))
+	first.Metadata.Set(eventbus.ScheduleKeyMetadataKey, "round.1.reminder")
+	if err := bus.ScheduleAt(context.Background(), "round.reminder.v1", first, time.Now().Add(20*time.Millisecond)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// "round-1-reminder" sanitizes to the same token and replaces the pending entry.
+	second := message.NewMessage("second", []byte(`{}`))
+	second.Metadata.Set(eventbus.ScheduleKeyMetadataKey, "round-1-reminder")
+	if err := bus.ScheduleAt(context.Background(), "round.reminder.v1", second, time.Now().Add(20*time.Millisecond)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msg := receive(t, ch)
+	if msg.UUID != "second" {
+		t.Fatalf("expected the replacing message, got %s", msg.UUID)
+	}
+	msg.Ack()
+	expectNone(t, ch)
+
+	if err := bus.CancelScheduled(context.Background(), ">*"); err == nil {
+		t.Fatal("expected a key with no valid subject characters to be rejected")
+	}
+}
+
+func TestRouter_RunsMultiHandlerFlow(t *testing.T) {
+	bus := New(eventbus.AppTypeBackend)
+	defer bus.Close()
+
+	router, err := message.NewRouter(message.RouterConfig{}, watermill.NopLogger{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	router.AddHandler("create", "round.create.requested.v1", bus, "round.entity.created.v1", bus,
+		func(msg *message.Message) ([]*message.Message, error) {
+			return []*message.Message{message.NewMessage(watermill.NewUUID(), msg.Payload)}, nil
+		})
+	router.AddHandler("schedule", "round.entity.created.v1", bus, "round.created.v1", bus,
+		func(msg *message.Message) ([]*message.Message, error) {
+			return []*message.Message{message.NewMessage(watermill.NewUUID(), msg.Payload)}, nil
+		})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		if err := router.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			t.Errorf("router stopped: %v", err)
+		}
+	}()
+	<-router.Running()
+
+	created, _ := bus.SubscribeForTest(ctx, "round.created.v1")
+	if err := bus.Publish("round.create.requested.v1", message.NewMessage("req", []byte(`{"title":"Doubles"}`))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msg := receive(t, created)
+	if string(msg.Payload) != `{"title":"Doubles"}` {
+		t.Fatalf("unexpected payload %s", msg.Payload)
+	}
+	msg.Ack()
+}
+
+func TestPublishBatch_ReturnsAcksWithDuplicates(t *testing.T) {
+	bus := New(eventbus.AppTypeBackend)
+	defer bus.Close()
+
+	first := message.NewMessage("1", []byte(`{}`))
+	first.Metadata.Set("idempotency_key", "score-1")
+	second := message.NewMessage("2", []byte(`{}`))
+	dup := message.NewMessage("3", []byte(`{}`))
+	dup.Metadata.Set("idempotency_key", "score-1")
+
+	acks, err := bus.PublishBatch(context.Background(), "score.update.requested.v1", first, second, dup)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []eventbus.PublishAck{
+		{MessageID: "1", Stream: "score", Sequence: 1},
+		{MessageID: "2", Stream: "score", Sequence: 2},
+		{MessageID: "3", Stream: "score", Sequence: 1, Duplicate: true},
+	}
+	for i := range want {
+		if acks[i] != want[i] {
+			t.Fatalf("ack %d: got %+v, want %+v", i, acks[i], want[i])
+		}
+	}
+
+	futures, err := bus.PublishAsync(context.Background(), "score.update.requested.v1", message.NewMessage("4", []byte(`{}`)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ack, err := futures[0].Wait(context.Background()); err != nil || ack.Sequence != 3 {
+		t.Fatalf("unexpected async ack %+v, %v", ack, err)
+	}
+}
@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/eventbus"
+	"github.com/Black-And-White-Club/frolf-bot-shared/observability/attr"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+// ScheduleAt publishes msg on topic at executeAt using an in-process timer. The schedule
+// key follows the NATS implementation: Schedule-Key metadata, defaulting to msg.UUID,
+// sanitized with eventbus.ScheduleKeyToken, and scheduling the same key again replaces
+// the pending entry.
+func (b *EventBus) ScheduleAt(ctx context.Context, topic string, msg *message.Message, executeAt time.Time) error {
+	if msg == nil {
+		return errors.New("scheduled message is nil")
+	}
+	if topic == "" {
+		return errors.New("scheduled message topic is required")
+	}
+	if strings.HasPrefix(topic, "_INBOX.") {
+		return fmt.Errorf("scheduling to inbox topics is not supported: %s", topic)
+	}
+	if strings.HasPrefix(topic, eventbus.DelayedMessagesStream+".") {
+		return fmt.Errorf("scheduling to delayed topics is not supported: %s", topic)
+	}
+	if strings.HasPrefix(topic, "discord.") && b.appType != eventbus.AppTypeDiscord {
+		return fmt.Errorf("scheduling to discord topics forbidden for app %q: %w", b.appType, eventbus.ErrPublishForbidden)
+	}
+
+	key := msg.Metadata.Get(eventbus.ScheduleKeyMetadataKey)
+	if key == "" {
+		key = msg.UUID
+		msg.Metadata.Set(eventbus.ScheduleKeyMetadataKey, key)
+	}
+	token, err := eventbus.ScheduleKeyToken(key)
+	if err != nil {
+		return err
+	}
+
+	scheduled := message.NewMessage(msg.UUID, append([]byte(nil), msg.Payload...))
+	scheduled.Metadata = copyMetadata(msg.Metadata)
+	delete(scheduled.Metadata, eventbus.ScheduleKeyMetadataKey)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+	if existing, ok := b.scheduled[token]; ok {
+		existing.Stop()
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(time.Until(executeAt), func() {
+		b.mu.Lock()
+		if b.scheduled[token] != timer {
+			b.mu.Unlock()
+			return
+		}
+		delete(b.scheduled, token)
+		b.mu.Unlock()
+
+		scheduled.SetContext(context.Background())
+		if _, err := b.publishToTopic(topic, []*message.Message{scheduled}); err != nil {
+			b.logger.Error("Failed to publish scheduled message",
+				attr.Topic(topic),
+				attr.String("schedule_key", key),
+				attr.Error(err),
+			)
+		}
+	})
+	b.scheduled[token] = timer
+	return nil
+}
+
+// CancelScheduled removes a pending scheduled message by key. Unknown or already fired
+// keys are not an error.
+func (b *EventBus) CancelScheduled(ctx context.Context, key string) error {
+	token, err := eventbus.ScheduleKeyToken(key)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if timer, ok := b.scheduled[token]; ok {
+		timer.Stop()
+		delete(b.scheduled, token)
+	}
+	return nil
+}
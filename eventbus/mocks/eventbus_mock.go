@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: eventbus/eventbus.go
+// Source: eventbus.go
 //
 // Generated by this command:
 //
-//	mockgen -source=eventbus/eventbus.go -destination=eventbus/mocks/eventbus_mock.go -package=mocks
+//	mockgen -source=eventbus.go -destination=mocks/eventbus_mock.go -package=mocks
 //
 
 // Package mocks is a generated GoMock package.
@@ -12,6 +12,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	eventbus "github.com/Black-And-White-Club/frolf-bot-shared/eventbus"
 	message "github.com/ThreeDotsLabs/watermill/message"
@@ -44,6 +45,20 @@ func (m *MockEventBus) EXPECT() *MockEventBusMockRecorder {
 	return m.recorder
 }
 
+// CancelScheduled mocks base method.
+func (m *MockEventBus) CancelScheduled(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelScheduled", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelScheduled indicates an expected call of CancelScheduled.
+func (mr *MockEventBusMockRecorder) CancelScheduled(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelScheduled", reflect.TypeOf((*MockEventBus)(nil).CancelScheduled), ctx, key)
+}
+
 // Close mocks base method.
 func (m *MockEventBus) Close() error {
 	m.ctrl.T.Helper()
@@ -72,6 +87,21 @@ func (mr *MockEventBusMockRecorder) CreateStream(ctx, streamName any) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStream", reflect.TypeOf((*MockEventBus)(nil).CreateStream), ctx, streamName)
 }
 
+// GetDeadLetter mocks base method.
+func (m *MockEventBus) GetDeadLetter(ctx context.Context, domain string, seq uint64) (*eventbus.DeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeadLetter", ctx, domain, seq)
+	ret0, _ := ret[0].(*eventbus.DeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeadLetter indicates an expected call of GetDeadLetter.
+func (mr *MockEventBusMockRecorder) GetDeadLetter(ctx, domain, seq any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeadLetter", reflect.TypeOf((*MockEventBus)(nil).GetDeadLetter), ctx, domain, seq)
+}
+
 // GetHealthCheckers mocks base method.
 func (m *MockEventBus) GetHealthCheckers() []eventbus.HealthChecker {
 	m.ctrl.T.Helper()
@@ -114,6 +144,35 @@ func (mr *MockEventBusMockRecorder) GetNATSConnection() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNATSConnection", reflect.TypeOf((*MockEventBus)(nil).GetNATSConnection))
 }
 
+// ListDeadLetters mocks base method.
+func (m *MockEventBus) ListDeadLetters(ctx context.Context, domain string, limit int) ([]eventbus.DeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeadLetters", ctx, domain, limit)
+	ret0, _ := ret[0].([]eventbus.DeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeadLetters indicates an expected call of ListDeadLetters.
+func (mr *MockEventBusMockRecorder) ListDeadLetters(ctx, domain, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeadLetters", reflect.TypeOf((*MockEventBus)(nil).ListDeadLetters), ctx, domain, limit)
+}
+
+// PauseSubscription mocks base method.
+func (m *MockEventBus) PauseSubscription(topic string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PauseSubscription", topic)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PauseSubscription indicates an expected call of PauseSubscription.
+func (mr *MockEventBusMockRecorder) PauseSubscription(topic any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PauseSubscription", reflect.TypeOf((*MockEventBus)(nil).PauseSubscription), topic)
+}
+
 // Publish mocks base method.
 func (m *MockEventBus) Publish(topic string, messages ...*message.Message) error {
 	m.ctrl.T.Helper()
@@ -133,6 +192,102 @@ func (mr *MockEventBusMockRecorder) Publish(topic any, messages ...any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockEventBus)(nil).Publish), varargs...)
 }
 
+// PublishAsync mocks base method.
+func (m *MockEventBus) PublishAsync(ctx context.Context, topic string, messages ...*message.Message) ([]*eventbus.PublishFuture, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, topic}
+	for _, a := range messages {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PublishAsync", varargs...)
+	ret0, _ := ret[0].([]*eventbus.PublishFuture)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishAsync indicates an expected call of PublishAsync.
+func (mr *MockEventBusMockRecorder) PublishAsync(ctx, topic any, messages ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, topic}, messages...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishAsync", reflect.TypeOf((*MockEventBus)(nil).PublishAsync), varargs...)
+}
+
+// PublishBatch mocks base method.
+func (m *MockEventBus) PublishBatch(ctx context.Context, topic string, messages ...*message.Message) ([]eventbus.PublishAck, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, topic}
+	for _, a := range messages {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "PublishBatch", varargs...)
+	ret0, _ := ret[0].([]eventbus.PublishAck)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishBatch indicates an expected call of PublishBatch.
+func (mr *MockEventBusMockRecorder) PublishBatch(ctx, topic any, messages ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, topic}, messages...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishBatch", reflect.TypeOf((*MockEventBus)(nil).PublishBatch), varargs...)
+}
+
+// ReplayDeadLetter mocks base method.
+func (m *MockEventBus) ReplayDeadLetter(ctx context.Context, domain string, seq uint64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplayDeadLetter", ctx, domain, seq)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplayDeadLetter indicates an expected call of ReplayDeadLetter.
+func (mr *MockEventBusMockRecorder) ReplayDeadLetter(ctx, domain, seq any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayDeadLetter", reflect.TypeOf((*MockEventBus)(nil).ReplayDeadLetter), ctx, domain, seq)
+}
+
+// ResumeSubscription mocks base method.
+func (m *MockEventBus) ResumeSubscription(topic string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeSubscription", topic)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResumeSubscription indicates an expected call of ResumeSubscription.
+func (mr *MockEventBusMockRecorder) ResumeSubscription(topic any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeSubscription", reflect.TypeOf((*MockEventBus)(nil).ResumeSubscription), topic)
+}
+
+// ScheduleAt mocks base method.
+func (m *MockEventBus) ScheduleAt(ctx context.Context, topic string, msg *message.Message, executeAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleAt", ctx, topic, msg, executeAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScheduleAt indicates an expected call of ScheduleAt.
+func (mr *MockEventBusMockRecorder) ScheduleAt(ctx, topic, msg, executeAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleAt", reflect.TypeOf((*MockEventBus)(nil).ScheduleAt), ctx, topic, msg, executeAt)
+}
+
+// Shutdown mocks base method.
+func (m *MockEventBus) Shutdown(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Shutdown", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Shutdown indicates an expected call of Shutdown.
+func (mr *MockEventBusMockRecorder) Shutdown(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shutdown", reflect.TypeOf((*MockEventBus)(nil).Shutdown), ctx)
+}
+
 // Subscribe mocks base method.
 func (m *MockEventBus) Subscribe(ctx context.Context, topic string) (<-chan *message.Message, error) {
 	m.ctrl.T.Helper()
@@ -163,6 +318,35 @@ func (mr *MockEventBusMockRecorder) SubscribeForTest(ctx, topic any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeForTest", reflect.TypeOf((*MockEventBus)(nil).SubscribeForTest), ctx, topic)
 }
 
+// SubscribeWithOptions mocks base method.
+func (m *MockEventBus) SubscribeWithOptions(ctx context.Context, topic string, opts eventbus.SubscribeOptions) (<-chan *message.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeWithOptions", ctx, topic, opts)
+	ret0, _ := ret[0].(<-chan *message.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubscribeWithOptions indicates an expected call of SubscribeWithOptions.
+func (mr *MockEventBusMockRecorder) SubscribeWithOptions(ctx, topic, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeWithOptions", reflect.TypeOf((*MockEventBus)(nil).SubscribeWithOptions), ctx, topic, opts)
+}
+
+// UpdateConsumerConfig mocks base method.
+func (m *MockEventBus) UpdateConsumerConfig(ctx context.Context, topic string, cfg eventbus.ConsumerConfig) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateConsumerConfig", ctx, topic, cfg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateConsumerConfig indicates an expected call of UpdateConsumerConfig.
+func (mr *MockEventBusMockRecorder) UpdateConsumerConfig(ctx, topic, cfg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateConsumerConfig", reflect.TypeOf((*MockEventBus)(nil).UpdateConsumerConfig), ctx, topic, cfg)
+}
+
 // MockHealthChecker is a mock of HealthChecker interface.
 type MockHealthChecker struct {
 	ctrl     *gomock.Controller
@@ -0,0 +1,288 @@
+// Package natstest runs an in-process nats-server with JetStream for end-to-end eventbus
+// tests:
+//
+//	srv := natstest.RunServer(t)
+//	bus := srv.NewEventBus(eventbus.AppTypeBackend)
+//	ch, _ := bus.Subscribe(ctx, "round.created.v1")
+//	_ = bus.Publish("round.created.v1", msg)
+//	got := natstest.AwaitMessage(t, ch, 5*time.Second)
+//
+// Every resource is released through t.Cleanup. Tests importing this package from the
+// eventbus directory must use the external eventbus_test package to avoid an import cycle.
+package natstest
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/eventbus"
+	eventbusmetrics "github.com/Black-And-White-Club/frolf-bot-shared/observability/otel/metrics/eventbus"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/nats-io/nats-server/v2/server"
+	nc "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// readyTimeout bounds how long the server may take to accept connections.
+const readyTimeout = 10 * time.Second
+
+// Server is an embedded JetStream-enabled nats-server bound to a test.
+type Server struct {
+	t      testing.TB
+	opts   *server.Options
+	srv    *server.Server
+	conn   *nc.Conn
+	js     jetstream.JetStream
+	logger *slog.Logger
+}
+
+// Option configures the embedded server.
+type Option func(*Server)
+
+// WithLogger sets the logger passed to event buses created by the server. Logs are
+// discarded by default.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// WithServerOptions adjusts the nats-server options before start, e.g. to set limits.
+// JetStream and the store directory are always enabled.
+func WithServerOptions(configure func(*server.Options)) Option {
+	return func(s *Server) {
+		configure(s.opts)
+	}
+}
+
+// RunServer starts a nats-server with JetStream on a random port, storing data in a
+// temp dir that survives Restart. The server is shut down when the test ends.
+func RunServer(t testing.TB, opts ...Option) *Server {
+	t.Helper()
+
+	s := &Server{
+		t: t,
+		opts: &server.Options{
+			Host:      "127.0.0.1",
+			Port:      server.RANDOM_PORT,
+			NoLog:     true,
+			NoSigs:    true,
+			JetStream: true,
+		},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.opts.JetStream = true
+	s.opts.StoreDir = t.TempDir()
+
+	s.start()
+	// Pin the port so restarts come back on the same URL and clients reconnect.
+	s.opts.Port = s.srv.Addr().(*net.TCPAddr).Port
+	t.Cleanup(s.Shutdown)
+	return s
+}
+
+func (s *Server) start() {
+	s.t.Helper()
+
+	srv, err := server.NewServer(s.opts)
+	if err != nil {
+		s.t.Fatalf("failed to create nats-server: %v", err)
+	}
+	go srv.Start()
+	if !srv.ReadyForConnections(readyTimeout) {
+		srv.Shutdown()
+		s.t.Fatalf("nats-server not ready after %s", readyTimeout)
+	}
+	s.srv = srv
+}
+
+// URL returns the client URL of the server.
+func (s *Server) URL() string {
+	return s.srv.ClientURL()
+}
+
+// NATSServer exposes the underlying server for assertions not covered by the helpers.
+func (s *Server) NATSServer() *server.Server {
+	return s.srv
+}
+
+// Shutdown stops the server. It is safe to call more than once.
+func (s *Server) Shutdown() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+		s.js = nil
+	}
+	if s.srv != nil {
+		s.srv.Shutdown()
+		s.srv.WaitForShutdown()
+	}
+}
+
+// Restart stops the server and starts it again on the same port and store directory,
+// so streams and consumers survive and connected clients reconnect.
+func (s *Server) Restart() {
+	s.t.Helper()
+
+	if s.srv != nil {
+		s.srv.Shutdown()
+		s.srv.WaitForShutdown()
+	}
+	s.start()
+}
+
+// Conn returns a client connection owned by the harness, for inspecting the server
+// independently of the event bus under test.
+func (s *Server) Conn() *nc.Conn {
+	s.t.Helper()
+
+	if s.conn == nil {
+		conn, err := nc.Connect(s.URL(), nc.MaxReconnects(-1), nc.ReconnectWait(50*time.Millisecond))
+		if err != nil {
+			s.t.Fatalf("failed to connect to nats-server: %v", err)
+		}
+		s.conn = conn
+	}
+	return s.conn
+}
+
+// JetStream returns a JetStream context on the harness connection.
+func (s *Server) JetStream() jetstream.JetStream {
+	s.t.Helper()
+
+	if s.js == nil {
+		js, err := jetstream.New(s.Conn())
+		if err != nil {
+			s.t.Fatalf("failed to create JetStream context: %v", err)
+		}
+		s.js = js
+	}
+	return s.js
+}
+
+// NewEventBus creates an EventBus for appType connected to the server, with no-op
+// metrics and tracing. It is closed when the test ends.
+func (s *Server) NewEventBus(appType string, opts ...eventbus.EventBusOption) eventbus.EventBus {
+	s.t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), readyTimeout)
+	defer cancel()
+
+	bus, err := eventbus.NewEventBus(ctx, s.URL(), s.logger, appType, eventbusmetrics.NewNoop(), noop.NewTracerProvider().Tracer("natstest"), opts...)
+	if err != nil {
+		s.t.Fatalf("failed to create event bus for %s: %v", appType, err)
+	}
+	s.t.Cleanup(func() { _ = bus.Close() })
+	return bus
+}
+
+// StreamInfo returns the current state of a stream.
+func (s *Server) StreamInfo(stream string) *jetstream.StreamInfo {
+	s.t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), readyTimeout)
+	defer cancel()
+
+	st, err := s.JetStream().Stream(ctx, stream)
+	if err != nil {
+		s.t.Fatalf("failed to look up stream %s: %v", stream, err)
+	}
+	info, err := st.Info(ctx)
+	if err != nil {
+		s.t.Fatalf("failed to read stream %s info: %v", stream, err)
+	}
+	return info
+}
+
+// ConsumerInfo returns the current state of a consumer, e.g. to assert on
+// NumAckPending or NumRedelivered.
+func (s *Server) ConsumerInfo(stream, consumer string) *jetstream.ConsumerInfo {
+	s.t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), readyTimeout)
+	defer cancel()
+
+	cons, err := s.JetStream().Consumer(ctx, stream, consumer)
+	if err != nil {
+		s.t.Fatalf("failed to look up consumer %s on %s: %v", consumer, stream, err)
+	}
+	info, err := cons.Info(ctx)
+	if err != nil {
+		s.t.Fatalf("failed to read consumer %s info: %v", consumer, err)
+	}
+	return info
+}
+
+// Watch subscribes to subject over core NATS, so messages can be awaited regardless of
+// JetStream consumers. Start watching before the publish under test.
+func (s *Server) Watch(subject string) *Watcher {
+	s.t.Helper()
+
+	ch := make(chan *nc.Msg, 64)
+	sub, err := s.Conn().ChanSubscribe(subject, ch)
+	if err != nil {
+		s.t.Fatalf("failed to watch %s: %v", subject, err)
+	}
+	if err := s.Conn().Flush(); err != nil {
+		s.t.Fatalf("failed to flush watch on %s: %v", subject, err)
+	}
+	s.t.Cleanup(func() { _ = sub.Unsubscribe() })
+	return &Watcher{t: s.t, subject: subject, ch: ch}
+}
+
+// Watcher collects raw NATS messages on a subject.
+type Watcher struct {
+	t       testing.TB
+	subject string
+	ch      chan *nc.Msg
+}
+
+// Next waits for the next message on the watched subject.
+func (w *Watcher) Next(timeout time.Duration) *nc.Msg {
+	w.t.Helper()
+
+	select {
+	case msg := <-w.ch:
+		return msg
+	case <-time.After(timeout):
+		w.t.Fatalf("timed out after %s waiting for a message on %s", timeout, w.subject)
+		return nil
+	}
+}
+
+// AwaitMessage waits for the next message on an eventbus subscription channel.
+func AwaitMessage(t testing.TB, ch <-chan *message.Message, timeout time.Duration) *message.Message {
+	t.Helper()
+
+	select {
+	case msg, ok := <-ch:
+		if !ok {
+			t.Fatalf("subscription closed while waiting for a message")
+		}
+		return msg
+	case <-time.After(timeout):
+		t.Fatalf("timed out after %s waiting for a message", timeout)
+		return nil
+	}
+}
+
+// ExpectNoMessage fails the test if a message arrives on ch within wait.
+func ExpectNoMessage(t testing.TB, ch <-chan *message.Message, wait time.Duration) {
+	t.Helper()
+
+	select {
+	case msg, ok := <-ch:
+		if ok {
+			t.Fatalf("unexpected message %s", msg.UUID)
+		}
+	case <-time.After(wait):
+	}
+}
@@ -0,0 +1,163 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-process Store for tests and single-process tools.
+// It offers no transactional guarantees.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records []Record
+	index   map[string]int
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{index: make(map[string]int)}
+}
+
+// Add stores records as pending. Records with an ID already in the store are ignored.
+func (s *MemoryStore) Add(ctx context.Context, records ...Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range records {
+		if r.ID == "" {
+			return fmt.Errorf("outbox record for %s has no ID", r.Topic)
+		}
+		if _, exists := s.index[r.ID]; exists {
+			continue
+		}
+		if r.CreatedAt.IsZero() {
+			r.CreatedAt = time.Now().UTC()
+		}
+		s.index[r.ID] = len(s.records)
+		s.records = append(s.records, r.clone())
+	}
+	return nil
+}
+
+// Pending returns up to limit unpublished, unparked records in insertion order.
+func (s *MemoryStore) Pending(ctx context.Context, limit int) ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Record
+	for _, r := range s.records {
+		if r.PublishedAt != nil || r.ParkedAt != nil {
+			continue
+		}
+		out = append(out, r.clone())
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// MarkPublished flags a record as published.
+func (s *MemoryStore) MarkPublished(ctx context.Context, id string, publishedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, ok := s.index[id]
+	if !ok {
+		return ErrRecordNotFound
+	}
+	s.records[i].PublishedAt = &publishedAt
+	return nil
+}
+
+// MarkFailed records a failed publish attempt.
+func (s *MemoryStore) MarkFailed(ctx context.Context, id string, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, ok := s.index[id]
+	if !ok {
+		return ErrRecordNotFound
+	}
+	s.records[i].Attempts++
+	s.records[i].LastError = reason
+	return nil
+}
+
+// MarkParked records a final failed attempt and stops returning the record from Pending.
+func (s *MemoryStore) MarkParked(ctx context.Context, id string, reason string, parkedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, ok := s.index[id]
+	if !ok {
+		return ErrRecordNotFound
+	}
+	s.records[i].Attempts++
+	s.records[i].LastError = reason
+	s.records[i].ParkedAt = &parkedAt
+	return nil
+}
+
+// Requeue returns a parked record to the pending set with its attempts reset.
+func (s *MemoryStore) Requeue(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i, ok := s.index[id]
+	if !ok || s.records[i].ParkedAt == nil {
+		return ErrRecordNotFound
+	}
+	s.records[i].ParkedAt = nil
+	s.records[i].Attempts = 0
+	return nil
+}
+
+// Stats reports the backlog of unpublished records.
+func (s *MemoryStore) Stats(ctx context.Context) (Stats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var stats Stats
+	for _, r := range s.records {
+		if r.PublishedAt != nil {
+			continue
+		}
+		if r.ParkedAt != nil {
+			stats.Parked++
+			continue
+		}
+		stats.Pending++
+		if stats.OldestCreatedAt.IsZero() || r.CreatedAt.Before(stats.OldestCreatedAt) {
+			stats.OldestCreatedAt = r.CreatedAt
+		}
+	}
+	return stats, nil
+}
+
+// Records returns a copy of every record, published or not.
+func (s *MemoryStore) Records() []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Record, 0, len(s.records))
+	for _, r := range s.records {
+		out = append(out, r.clone())
+	}
+	return out
+}
+
+// DeletePublished removes records published before the cutoff and returns how many were removed.
+func (s *MemoryStore) DeletePublished(ctx context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.records[:0]
+	removed := 0
+	for _, r := range s.records {
+		if r.PublishedAt != nil && r.PublishedAt.Before(before) {
+			removed++
+			continue
+		}
+		kept = append(kept, r)
+	}
+	s.records = kept
+	s.index = make(map[string]int, len(kept))
+	for i, r := range kept {
+		s.index[r.ID] = i
+	}
+	return removed, nil
+}
@@ -0,0 +1,183 @@
+// Package outbox implements the transactional outbox pattern for the event bus.
+//
+// Services write the events produced by an operation into an outbox table in the
+// same database transaction as their state change. A Relay later drains pending
+// records into EventBus.Publish, so events are published if and only if the
+// transaction committed.
+//
+// Usage:
+//
+//	tx, _ := db.BeginTx(ctx, nil)
+//	// ... domain writes on tx ...
+//	records, _ := outbox.FromResults(results)
+//	if err := store.WithTx(tx).Add(ctx, records...); err != nil { ... }
+//	tx.Commit()
+//
+//	relay := outbox.NewRelay(store, eventBus, logger, registry.OutboxMetrics)
+//	go relay.Run(ctx)
+//
+// The relay is at-least-once: a crash between publishing and marking a record
+// published republishes it. Every record carries an idempotency_key, so the
+// event bus derives the same Nats-Msg-Id and JetStream drops the duplicate.
+//
+// A record that fails with a permanent error, or that keeps failing past the
+// relay's max attempts, is parked: it leaves the pending set with its last error
+// so the relay can move on, and an operator can inspect or requeue it.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/utils/handlerwrapper"
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+// IdempotencyKeyMetadataKey is the metadata key the event bus turns into a Nats-Msg-Id.
+const IdempotencyKeyMetadataKey = "idempotency_key"
+
+// ErrRecordNotFound is returned when marking a record that does not exist.
+var ErrRecordNotFound = errors.New("outbox record not found")
+
+// Record is an event waiting in the outbox.
+type Record struct {
+	ID       string
+	Topic    string
+	Payload  []byte
+	Metadata map[string]string
+
+	CreatedAt   time.Time
+	Attempts    int
+	LastError   string
+	PublishedAt *time.Time
+	// ParkedAt is set once the relay gives up on the record.
+	ParkedAt *time.Time
+}
+
+// Stats describes the unpublished part of the outbox.
+type Stats struct {
+	Pending int
+	// OldestCreatedAt is zero when nothing is pending.
+	OldestCreatedAt time.Time
+	// Parked counts unpublished records the relay gave up on; they are not in Pending.
+	Parked int
+}
+
+// Store persists outbox records.
+type Store interface {
+	// Add stores records as pending.
+	Add(ctx context.Context, records ...Record) error
+	// Pending returns up to limit unpublished, unparked records, oldest first.
+	Pending(ctx context.Context, limit int) ([]Record, error)
+	// MarkPublished flags a record as published.
+	MarkPublished(ctx context.Context, id string, publishedAt time.Time) error
+	// MarkFailed records a failed publish attempt; the record stays pending.
+	MarkFailed(ctx context.Context, id string, reason string) error
+	// MarkParked records a final failed attempt and removes the record from Pending.
+	MarkParked(ctx context.Context, id string, reason string, parkedAt time.Time) error
+	// Stats reports the backlog of unpublished records.
+	Stats(ctx context.Context) (Stats, error)
+}
+
+// NewRecord builds a record for topic with a JSON-encoded payload.
+func NewRecord(topic string, payload any, metadata map[string]string) (Record, error) {
+	if topic == "" {
+		return Record{}, errors.New("outbox record topic is required")
+	}
+	if payload == nil {
+		return Record{}, errors.New("outbox record payload is required")
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Record{}, fmt.Errorf("failed to marshal outbox payload for %s: %w", topic, err)
+	}
+
+	md := make(map[string]string, len(metadata)+1)
+	for k, v := range metadata {
+		md[k] = v
+	}
+	md["topic"] = topic
+
+	return Record{
+		ID:        watermill.NewUUID(),
+		Topic:     topic,
+		Payload:   data,
+		Metadata:  md,
+		CreatedAt: time.Now().UTC(),
+	}, nil
+}
+
+// FromResults converts handler results into outbox records.
+func FromResults(results []handlerwrapper.Result) ([]Record, error) {
+	records := make([]Record, 0, len(results))
+	for i, res := range results {
+		if err := res.Validate(); err != nil {
+			return nil, fmt.Errorf("result[%d]: %w", i, err)
+		}
+		record, err := NewRecord(res.Topic, res.Payload, res.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("result[%d]: %w", i, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// FromMessage converts an already-built message (e.g. from Helpers.CreateResultMessage)
+// into an outbox record, keeping its UUID and metadata.
+func FromMessage(topic string, msg *message.Message) Record {
+	md := make(map[string]string, len(msg.Metadata)+1)
+	for k, v := range msg.Metadata {
+		md[k] = v
+	}
+	md["topic"] = topic
+
+	return Record{
+		ID:        msg.UUID,
+		Topic:     topic,
+		Payload:   append([]byte(nil), msg.Payload...),
+		Metadata:  md,
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+// Message rebuilds the Watermill message published for this record.
+// Records without an idempotency key use their ID, so a republished record
+// maps to the same Nats-Msg-Id.
+func (r Record) Message(ctx context.Context) *message.Message {
+	msg := message.NewMessage(r.ID, r.Payload)
+	for k, v := range r.Metadata {
+		msg.Metadata.Set(k, v)
+	}
+	if msg.Metadata.Get(IdempotencyKeyMetadataKey) == "" {
+		msg.Metadata.Set(IdempotencyKeyMetadataKey, r.ID)
+	}
+	if msg.Metadata.Get("topic") == "" {
+		msg.Metadata.Set("topic", r.Topic)
+	}
+	msg.SetContext(ctx)
+	return msg
+}
+
+func (r Record) clone() Record {
+	out := r
+	out.Payload = append([]byte(nil), r.Payload...)
+	out.Metadata = make(map[string]string, len(r.Metadata))
+	for k, v := range r.Metadata {
+		out.Metadata[k] = v
+	}
+	if r.PublishedAt != nil {
+		at := *r.PublishedAt
+		out.PublishedAt = &at
+	}
+	if r.ParkedAt != nil {
+		at := *r.ParkedAt
+		out.ParkedAt = &at
+	}
+	return out
+}
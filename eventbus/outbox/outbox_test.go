@@ -0,0 +1,68 @@
+package outbox
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/utils/handlerwrapper"
+)
+
+func TestFromResults_BuildsRecords(t *testing.T) {
+	records, err := FromResults([]handlerwrapper.Result{
+		{Topic: "round.created.v1", Payload: map[string]string{"round_id": "r1"}, Metadata: map[string]string{"guild_id": "g1"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+
+	r := records[0]
+	if r.ID == "" || r.CreatedAt.IsZero() {
+		t.Fatalf("expected ID and CreatedAt to be set, got %+v", r)
+	}
+	if string(r.Payload) != `{"round_id":"r1"}` {
+		t.Fatalf("unexpected payload %s", r.Payload)
+	}
+	if r.Metadata["guild_id"] != "g1" || r.Metadata["topic"] != "round.created.v1" {
+		t.Fatalf("unexpected metadata %v", r.Metadata)
+	}
+}
+
+func TestFromResults_RejectsInvalidResult(t *testing.T) {
+	if _, err := FromResults([]handlerwrapper.Result{{Topic: "round.created.v1"}}); err == nil {
+		t.Fatal("expected error for result without payload")
+	}
+}
+
+func TestRecordMessage_DefaultsIdempotencyKeyToRecordID(t *testing.T) {
+	r, err := NewRecord("round.created.v1", struct{}{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msg := r.Message(context.Background())
+	if msg.UUID != r.ID {
+		t.Fatalf("expected message UUID %q, got %q", r.ID, msg.UUID)
+	}
+	if got := msg.Metadata.Get(IdempotencyKeyMetadataKey); got != r.ID {
+		t.Fatalf("expected idempotency key %q, got %q", r.ID, got)
+	}
+
+	r.Metadata[IdempotencyKeyMetadataKey] = "round-r1-created"
+	if got := r.Message(context.Background()).Metadata.Get(IdempotencyKeyMetadataKey); got != "round-r1-created" {
+		t.Fatalf("expected explicit idempotency key to be kept, got %q", got)
+	}
+}
+
+func TestSQLStoreRebind(t *testing.T) {
+	query := "UPDATE t SET a = ? WHERE id = ?"
+
+	if got := NewSQLStore(nil).rebind(query); got != "UPDATE t SET a = $1 WHERE id = $2" {
+		t.Fatalf("unexpected postgres query %q", got)
+	}
+	if got := NewSQLStore(nil, WithQuestionPlaceholders()).rebind(query); got != query {
+		t.Fatalf("unexpected question placeholder query %q", got)
+	}
+}
@@ -0,0 +1,235 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/eventbus"
+	"github.com/Black-And-White-Club/frolf-bot-shared/observability/attr"
+	outboxmetrics "github.com/Black-And-White-Club/frolf-bot-shared/observability/otel/metrics/outbox"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+const (
+	defaultRelayBatchSize = 100
+	defaultRelayInterval  = time.Second
+	// defaultRelayMaxAttempts bounds how long one record can hold back the outbox.
+	defaultRelayMaxAttempts = 10
+)
+
+// Publisher is the part of eventbus.EventBus the relay needs.
+type Publisher interface {
+	Publish(topic string, messages ...*message.Message) error
+}
+
+// RelayOption configures a Relay.
+type RelayOption func(*Relay)
+
+// WithBatchSize sets how many pending records are read per pass.
+func WithBatchSize(n int) RelayOption {
+	return func(r *Relay) {
+		if n > 0 {
+			r.batchSize = n
+		}
+	}
+}
+
+// WithPollInterval sets how long the relay waits between passes once the outbox is drained.
+func WithPollInterval(d time.Duration) RelayOption {
+	return func(r *Relay) {
+		if d > 0 {
+			r.interval = d
+		}
+	}
+}
+
+// WithMaxAttempts sets how many failed publishes a record gets before it is parked.
+func WithMaxAttempts(n int) RelayOption {
+	return func(r *Relay) {
+		if n > 0 {
+			r.maxAttempts = n
+		}
+	}
+}
+
+// WithPermanentErrors sets how the relay recognises publish errors that will never
+// succeed on retry. Records failing with them are parked on the first attempt.
+// The default treats payload validation and publish boundary errors as permanent.
+func WithPermanentErrors(isPermanent func(error) bool) RelayOption {
+	return func(r *Relay) {
+		if isPermanent != nil {
+			r.isPermanent = isPermanent
+		}
+	}
+}
+
+// IsPermanentPublishError reports whether err is an event bus error that retrying cannot fix.
+func IsPermanentPublishError(err error) bool {
+	return errors.Is(err, eventbus.ErrPayloadValidation) || errors.Is(err, eventbus.ErrPublishForbidden)
+}
+
+// Relay drains pending outbox records into the event bus.
+type Relay struct {
+	store     Store
+	publisher Publisher
+	logger    *slog.Logger
+	metrics   outboxmetrics.OutboxMetrics
+
+	batchSize   int
+	interval    time.Duration
+	maxAttempts int
+	isPermanent func(error) bool
+	now         func() time.Time
+}
+
+// NewRelay creates a relay from store to publisher. A nil metrics uses the no-op implementation.
+func NewRelay(store Store, publisher Publisher, logger *slog.Logger, metrics outboxmetrics.OutboxMetrics, opts ...RelayOption) *Relay {
+	if metrics == nil {
+		metrics = outboxmetrics.NewNoop()
+	}
+	r := &Relay{
+		store:       store,
+		publisher:   publisher,
+		logger:      logger,
+		metrics:     metrics,
+		batchSize:   defaultRelayBatchSize,
+		interval:    defaultRelayInterval,
+		maxAttempts: defaultRelayMaxAttempts,
+		isPermanent: IsPermanentPublishError,
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Run drains the outbox until ctx is cancelled. Full batches are followed
+// immediately by another pass; otherwise the relay waits for the poll interval.
+func (r *Relay) Run(ctx context.Context) error {
+	logger := r.logger.With(attr.String("operation", "outbox_relay"))
+	logger.Info("Outbox relay started", attr.Duration("poll_interval", r.interval), attr.Int("batch_size", r.batchSize))
+
+	for {
+		published, err := r.Drain(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Warn("Outbox relay pass failed", attr.Error(err))
+		}
+
+		if err == nil && published == r.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Info("Outbox relay stopped")
+			return ctx.Err()
+		case <-time.After(r.interval):
+		}
+	}
+}
+
+// Drain publishes one batch of pending records in order and returns how many were published.
+// It stops at the first retryable publish failure so later records for the same
+// aggregate are not published ahead of it. A record failing with a permanent error,
+// or on its last allowed attempt, is parked instead and the batch carries on.
+func (r *Relay) Drain(ctx context.Context) (int, error) {
+	r.recordBacklog(ctx)
+
+	records, err := r.store.Pending(ctx, r.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load pending outbox records: %w", err)
+	}
+
+	published := 0
+	for i, record := range records {
+		if err := ctx.Err(); err != nil {
+			r.release(ctx, records[i:])
+			return published, err
+		}
+
+		if err := r.publisher.Publish(record.Topic, record.Message(ctx)); err != nil {
+			r.metrics.RecordRelayPublish(ctx, record.Topic, false)
+			if r.isPermanent(err) || record.Attempts+1 >= r.maxAttempts {
+				if parkErr := r.park(ctx, record, err); parkErr != nil {
+					r.release(ctx, records[i:])
+					return published, parkErr
+				}
+				continue
+			}
+			if markErr := r.store.MarkFailed(ctx, record.ID, err.Error()); markErr != nil {
+				r.logger.Warn("Failed to record outbox publish failure",
+					attr.String("record_id", record.ID),
+					attr.Error(markErr),
+				)
+			}
+			r.release(ctx, records[i+1:])
+			return published, fmt.Errorf("failed to publish outbox record %s to %s: %w", record.ID, record.Topic, err)
+		}
+
+		now := r.now().UTC()
+		r.metrics.RecordRelayPublish(ctx, record.Topic, true)
+		r.metrics.RecordRelayLag(ctx, record.Topic, now.Sub(record.CreatedAt))
+
+		// Already published: a failure here only causes a deduplicated republish.
+		if err := r.store.MarkPublished(ctx, record.ID, now); err != nil {
+			r.release(ctx, records[i+1:])
+			return published, fmt.Errorf("failed to mark outbox record %s published: %w", record.ID, err)
+		}
+		published++
+	}
+	return published, nil
+}
+
+// releaser is implemented by stores whose Pending leases the records it returns.
+type releaser interface {
+	Release(ctx context.Context, ids ...string) error
+}
+
+// release hands back records a stopped pass did not get to, so the next pass
+// does not wait out their lease.
+func (r *Relay) release(ctx context.Context, records []Record) {
+	rel, ok := r.store.(releaser)
+	if !ok || len(records) == 0 {
+		return
+	}
+	ids := make([]string, len(records))
+	for i, record := range records {
+		ids[i] = record.ID
+	}
+	if err := rel.Release(context.WithoutCancel(ctx), ids...); err != nil {
+		r.logger.Warn("Failed to release outbox records", attr.Int("count", len(ids)), attr.Error(err))
+	}
+}
+
+// park takes a record the relay will not retry out of the pending set.
+func (r *Relay) park(ctx context.Context, record Record, publishErr error) error {
+	if err := r.store.MarkParked(ctx, record.ID, publishErr.Error(), r.now().UTC()); err != nil {
+		return fmt.Errorf("failed to park outbox record %s: %w", record.ID, err)
+	}
+	r.metrics.RecordParkedRecord(ctx, record.Topic)
+	r.logger.Error("Parked outbox record after publish failure",
+		attr.String("record_id", record.ID),
+		attr.String("topic", record.Topic),
+		attr.Int("attempts", record.Attempts+1),
+		attr.Error(publishErr),
+	)
+	return nil
+}
+
+func (r *Relay) recordBacklog(ctx context.Context) {
+	stats, err := r.store.Stats(ctx)
+	if err != nil {
+		r.logger.Debug("Failed to read outbox stats", attr.Error(err))
+		return
+	}
+	r.metrics.RecordPendingRecords(ctx, stats.Pending)
+	if stats.OldestCreatedAt.IsZero() {
+		r.metrics.RecordOldestPendingAge(ctx, 0)
+		return
+	}
+	r.metrics.RecordOldestPendingAge(ctx, r.now().Sub(stats.OldestCreatedAt))
+}
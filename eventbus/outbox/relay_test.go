@@ -0,0 +1,167 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/eventbus"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+type fakePublisher struct {
+	topics []string
+	failOn string
+}
+
+func (p *fakePublisher) Publish(topic string, messages ...*message.Message) error {
+	if topic == p.failOn {
+		return errors.New("nats unavailable")
+	}
+	p.topics = append(p.topics, topic)
+	return nil
+}
+
+func newTestRelay(store Store, pub Publisher) *Relay {
+	return NewRelay(store, pub, slog.New(slog.NewTextHandler(io.Discard, nil)), nil)
+}
+
+func mustRecord(t *testing.T, topic string) Record {
+	t.Helper()
+	r, err := NewRecord(topic, map[string]string{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return r
+}
+
+func TestRelayDrain_PublishesInOrderAndMarksPublished(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	if err := store.Add(ctx, mustRecord(t, "round.created.v1"), mustRecord(t, "round.updated.v1")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pub := &fakePublisher{}
+	n, err := newTestRelay(store, pub).Drain(ctx)
+	if err != nil || n != 2 {
+		t.Fatalf("expected 2 published, got %d err=%v", n, err)
+	}
+	if len(pub.topics) != 2 || pub.topics[0] != "round.created.v1" || pub.topics[1] != "round.updated.v1" {
+		t.Fatalf("unexpected publish order %v", pub.topics)
+	}
+
+	stats, _ := store.Stats(ctx)
+	if stats.Pending != 0 {
+		t.Fatalf("expected no pending records, got %d", stats.Pending)
+	}
+}
+
+func TestRelayDrain_StopsAtFirstFailure(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	failing := mustRecord(t, "round.failed.v1")
+	if err := store.Add(ctx, failing, mustRecord(t, "round.updated.v1")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pub := &fakePublisher{failOn: "round.failed.v1"}
+	n, err := newTestRelay(store, pub).Drain(ctx)
+	if err == nil || n != 0 {
+		t.Fatalf("expected failure with nothing published, got %d err=%v", n, err)
+	}
+	if len(pub.topics) != 0 {
+		t.Fatalf("expected later records to be held back, got %v", pub.topics)
+	}
+
+	records := store.Records()
+	if records[0].Attempts != 1 || records[0].LastError != "nats unavailable" {
+		t.Fatalf("expected failure to be recorded, got %+v", records[0])
+	}
+	if stats, _ := store.Stats(ctx); stats.Pending != 2 {
+		t.Fatalf("expected both records to stay pending, got %d", stats.Pending)
+	}
+}
+
+func TestRelayDrain_ParksPermanentFailureAndContinues(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	if err := store.Add(ctx, mustRecord(t, "round.invalid.v1"), mustRecord(t, "round.updated.v1")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pub := &fakePublisher{failOn: "round.invalid.v1"}
+	relay := newTestRelay(store, pub)
+	relay.isPermanent = func(error) bool { return true }
+
+	n, err := relay.Drain(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 published, got %d err=%v", n, err)
+	}
+	if len(pub.topics) != 1 || pub.topics[0] != "round.updated.v1" {
+		t.Fatalf("expected the next record to be published, got %v", pub.topics)
+	}
+
+	records := store.Records()
+	if records[0].ParkedAt == nil || records[0].Attempts != 1 || records[0].LastError != "nats unavailable" {
+		t.Fatalf("expected poison record to be parked, got %+v", records[0])
+	}
+	stats, _ := store.Stats(ctx)
+	if stats.Pending != 0 || stats.Parked != 1 {
+		t.Fatalf("expected 0 pending and 1 parked, got %+v", stats)
+	}
+
+	if err := store.Requeue(ctx, records[0].ID); err != nil {
+		t.Fatalf("unexpected requeue error: %v", err)
+	}
+	if pending, _ := store.Pending(ctx, 0); len(pending) != 1 || pending[0].Attempts != 0 {
+		t.Fatalf("expected requeued record to be pending with attempts reset, got %+v", pending)
+	}
+}
+
+func TestRelayDrain_ParksAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	if err := store.Add(ctx, mustRecord(t, "round.failed.v1"), mustRecord(t, "round.updated.v1")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pub := &fakePublisher{failOn: "round.failed.v1"}
+	relay := NewRelay(store, pub, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, WithMaxAttempts(3))
+
+	for pass := 1; pass <= 2; pass++ {
+		if _, err := relay.Drain(ctx); err == nil {
+			t.Fatalf("pass %d: expected retryable failure to stop the batch", pass)
+		}
+		if len(pub.topics) != 0 {
+			t.Fatalf("pass %d: expected later records to be held back, got %v", pass, pub.topics)
+		}
+	}
+
+	n, err := relay.Drain(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("expected last attempt to park and publish the next record, got %d err=%v", n, err)
+	}
+	if records := store.Records(); records[0].ParkedAt == nil || records[0].Attempts != 3 {
+		t.Fatalf("expected record parked after 3 attempts, got %+v", records[0])
+	}
+}
+
+func TestIsPermanentPublishError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{fmt.Errorf("publish: %w", &eventbus.PayloadValidationError{Topic: "round.created.v1", Reason: "bad"}), true},
+		{fmt.Errorf("publishing to inbox topics forbidden for app %q: %w", "backend", eventbus.ErrPublishForbidden), true},
+		{errors.New("nats unavailable"), false},
+	}
+	for _, tc := range cases {
+		if got := IsPermanentPublishError(tc.err); got != tc.want {
+			t.Fatalf("IsPermanentPublishError(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTableName is the outbox table used when no table is configured.
+const DefaultTableName = "event_outbox"
+
+// DefaultLeaseDuration is how long records returned by SQLStore.Pending stay
+// claimed by one relay before another replica may pick them up.
+const DefaultLeaseDuration = 30 * time.Second
+
+// DBTX is the subset of *sql.DB, *sql.Tx and *sql.Conn used by SQLStore.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// SQLStoreOption configures a SQLStore.
+type SQLStoreOption func(*SQLStore)
+
+// WithTableName overrides the outbox table name.
+func WithTableName(name string) SQLStoreOption {
+	return func(s *SQLStore) {
+		if name != "" {
+			s.table = name
+		}
+	}
+}
+
+// WithQuestionPlaceholders uses ? bind parameters (e.g. SQLite) instead of
+// the PostgreSQL-style $1 parameters.
+func WithQuestionPlaceholders() SQLStoreOption {
+	return func(s *SQLStore) {
+		s.questionPlaceholders = true
+	}
+}
+
+// WithLeaseDuration sets how long a relay holds the records it loaded. It should
+// comfortably exceed the time to publish one batch.
+func WithLeaseDuration(d time.Duration) SQLStoreOption {
+	return func(s *SQLStore) {
+		if d > 0 {
+			s.leaseDuration = d
+		}
+	}
+}
+
+// WithoutRowLocking drops FOR UPDATE SKIP LOCKED from the claim query, for
+// databases without row locks (e.g. SQLite). Claims then rely on the lease alone.
+func WithoutRowLocking() SQLStoreOption {
+	return func(s *SQLStore) {
+		s.rowLocking = false
+	}
+}
+
+// SQLStore is a database/sql Store. See CreateTableSQL for the expected schema.
+//
+// Pending claims the records it returns with a lease, so relays on several
+// replicas share the outbox without publishing the same record twice. Order is
+// kept within a batch; across replicas it is best effort.
+type SQLStore struct {
+	db                   DBTX
+	table                string
+	questionPlaceholders bool
+
+	leaseDuration time.Duration
+	rowLocking    bool
+	now           func() time.Time
+}
+
+// NewSQLStore creates a store on db, typically the service's *sql.DB.
+func NewSQLStore(db DBTX, opts ...SQLStoreOption) *SQLStore {
+	s := &SQLStore{
+		db:            db,
+		table:         DefaultTableName,
+		leaseDuration: DefaultLeaseDuration,
+		rowLocking:    true,
+		now:           time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// WithTx returns a copy of the store that runs its statements on tx, so records
+// added through it commit or roll back with the service's own writes.
+func (s *SQLStore) WithTx(tx DBTX) *SQLStore {
+	clone := *s
+	clone.db = tx
+	return &clone
+}
+
+// CreateTableSQL returns the PostgreSQL DDL for the outbox table. It also adds
+// the lease and parking columns to tables created by earlier versions.
+func CreateTableSQL(table string) string {
+	if table == "" {
+		table = DefaultTableName
+	}
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %[1]s (
+	id           TEXT PRIMARY KEY,
+	topic        TEXT NOT NULL,
+	payload      BYTEA NOT NULL,
+	metadata     TEXT NOT NULL DEFAULT '{}',
+	created_at   TIMESTAMPTZ NOT NULL,
+	attempts     INTEGER NOT NULL DEFAULT 0,
+	last_error   TEXT NOT NULL DEFAULT '',
+	published_at TIMESTAMPTZ,
+	locked_until TIMESTAMPTZ,
+	parked_at    TIMESTAMPTZ
+);
+ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ;
+ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS parked_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS %[1]s_pending_idx ON %[1]s (created_at) WHERE published_at IS NULL;`, table)
+}
+
+// Add inserts records as pending. Records with an existing ID are ignored.
+func (s *SQLStore) Add(ctx context.Context, records ...Record) error {
+	query := s.rebind(fmt.Sprintf(
+		`INSERT INTO %s (id, topic, payload, metadata, created_at) VALUES (?, ?, ?, ?, ?) ON CONFLICT (id) DO NOTHING`,
+		s.table,
+	))
+	for _, r := range records {
+		if r.ID == "" {
+			return fmt.Errorf("outbox record for %s has no ID", r.Topic)
+		}
+		if r.CreatedAt.IsZero() {
+			r.CreatedAt = time.Now().UTC()
+		}
+		metadata, err := json.Marshal(r.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to marshal outbox metadata for %s: %w", r.ID, err)
+		}
+		if _, err := s.db.ExecContext(ctx, query, r.ID, r.Topic, r.Payload, string(metadata), r.CreatedAt); err != nil {
+			return fmt.Errorf("failed to insert outbox record %s: %w", r.ID, err)
+		}
+	}
+	return nil
+}
+
+// Pending claims and returns up to limit unpublished, unparked records, oldest
+// first. Records claimed by another relay whose lease has not expired are skipped.
+func (s *SQLStore) Pending(ctx context.Context, limit int) ([]Record, error) {
+	now := s.now().UTC()
+	selectIDs := fmt.Sprintf(
+		`SELECT id FROM %s WHERE published_at IS NULL AND parked_at IS NULL AND (locked_until IS NULL OR locked_until < ?) ORDER BY created_at, id`,
+		s.table,
+	)
+	args := []any{now.Add(s.leaseDuration), now}
+	if limit > 0 {
+		selectIDs += " LIMIT ?"
+		args = append(args, limit)
+	}
+	if s.rowLocking {
+		selectIDs += " FOR UPDATE SKIP LOCKED"
+	}
+	query := fmt.Sprintf(
+		`UPDATE %s SET locked_until = ? WHERE id IN (%s) RETURNING id, topic, payload, metadata, created_at, attempts, last_error`,
+		s.table, selectIDs,
+	)
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending outbox records: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Record
+	for rows.Next() {
+		var (
+			r        Record
+			metadata string
+		)
+		if err := rows.Scan(&r.ID, &r.Topic, &r.Payload, &metadata, &r.CreatedAt, &r.Attempts, &r.LastError); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox record: %w", err)
+		}
+		if metadata != "" {
+			if err := json.Unmarshal([]byte(metadata), &r.Metadata); err != nil {
+				return nil, fmt.Errorf("failed to decode outbox metadata for %s: %w", r.ID, err)
+			}
+		}
+		out = append(out, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read pending outbox records: %w", err)
+	}
+	// RETURNING does not keep the subquery's order.
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.Before(out[j].CreatedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+// MarkPublished flags a record as published.
+func (s *SQLStore) MarkPublished(ctx context.Context, id string, publishedAt time.Time) error {
+	query := s.rebind(fmt.Sprintf(`UPDATE %s SET published_at = ? WHERE id = ?`, s.table))
+	return s.update(ctx, id, query, publishedAt, id)
+}
+
+// MarkFailed records a failed publish attempt and releases the record's lease so
+// the next pass retries it.
+func (s *SQLStore) MarkFailed(ctx context.Context, id string, reason string) error {
+	query := s.rebind(fmt.Sprintf(`UPDATE %s SET attempts = attempts + 1, last_error = ?, locked_until = NULL WHERE id = ?`, s.table))
+	return s.update(ctx, id, query, reason, id)
+}
+
+// MarkParked records a final failed attempt and stops returning the record from Pending.
+func (s *SQLStore) MarkParked(ctx context.Context, id string, reason string, parkedAt time.Time) error {
+	query := s.rebind(fmt.Sprintf(
+		`UPDATE %s SET attempts = attempts + 1, last_error = ?, parked_at = ?, locked_until = NULL WHERE id = ?`,
+		s.table,
+	))
+	return s.update(ctx, id, query, reason, parkedAt, id)
+}
+
+// Requeue returns a parked record to the pending set with its attempts reset.
+func (s *SQLStore) Requeue(ctx context.Context, id string) error {
+	query := s.rebind(fmt.Sprintf(
+		`UPDATE %s SET parked_at = NULL, attempts = 0 WHERE id = ? AND parked_at IS NOT NULL`,
+		s.table,
+	))
+	return s.update(ctx, id, query, id)
+}
+
+// Release drops the lease on records a relay claimed but did not get to, so
+// they need not wait for the lease to expire.
+func (s *SQLStore) Release(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	query := s.rebind(fmt.Sprintf(
+		`UPDATE %s SET locked_until = NULL WHERE id IN (%s) AND published_at IS NULL`,
+		s.table, strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", "),
+	))
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to release outbox records: %w", err)
+	}
+	return nil
+}
+
+// Stats reports the backlog of unpublished records.
+func (s *SQLStore) Stats(ctx context.Context) (Stats, error) {
+	query := fmt.Sprintf(
+		`SELECT COALESCE(SUM(CASE WHEN parked_at IS NULL THEN 1 ELSE 0 END), 0), MIN(CASE WHEN parked_at IS NULL THEN created_at END), COALESCE(SUM(CASE WHEN parked_at IS NOT NULL THEN 1 ELSE 0 END), 0) FROM %s WHERE published_at IS NULL`,
+		s.table,
+	)
+	var (
+		stats  Stats
+		oldest sql.NullTime
+	)
+	if err := s.db.QueryRowContext(ctx, query).Scan(&stats.Pending, &oldest, &stats.Parked); err != nil {
+		return Stats{}, fmt.Errorf("failed to query outbox stats: %w", err)
+	}
+	if oldest.Valid {
+		stats.OldestCreatedAt = oldest.Time
+	}
+	return stats, nil
+}
+
+// DeletePublished removes records published before the cutoff and returns how many were removed.
+func (s *SQLStore) DeletePublished(ctx context.Context, before time.Time) (int, error) {
+	query := s.rebind(fmt.Sprintf(`DELETE FROM %s WHERE published_at IS NOT NULL AND published_at < ?`, s.table))
+	res, err := s.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete published outbox records: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, nil
+	}
+	return int(n), nil
+}
+
+func (s *SQLStore) update(ctx context.Context, id, query string, args ...any) error {
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update outbox record %s: %w", id, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// rebind rewrites ? placeholders to $n unless question placeholders are configured.
+func (s *SQLStore) rebind(query string) string {
+	if s.questionPlaceholders {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
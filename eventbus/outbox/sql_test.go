@@ -0,0 +1,290 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDB is a database/sql driver that records statements and answers queries
+// with scripted rows, enough to check the SQL SQLStore sends.
+type fakeDB struct {
+	mu           sync.Mutex
+	calls        []fakeCall
+	columns      []string
+	rows         [][]driver.Value
+	rowsAffected int64
+}
+
+type fakeCall struct {
+	query string
+	args  []driver.Value
+}
+
+func newFakeDB(t *testing.T) (*fakeDB, *sql.DB) {
+	t.Helper()
+	fake := &fakeDB{rowsAffected: 1}
+	db := sql.OpenDB(fake)
+	t.Cleanup(func() { db.Close() })
+	return fake, db
+}
+
+func (f *fakeDB) lastCall(t *testing.T) fakeCall {
+	t.Helper()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.calls) == 0 {
+		t.Fatal("expected a statement to be executed")
+	}
+	return f.calls[len(f.calls)-1]
+}
+
+func (f *fakeDB) record(query string, args []driver.NamedValue) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	values := make([]driver.Value, len(args))
+	for i, a := range args {
+		values[i] = a.Value
+	}
+	f.calls = append(f.calls, fakeCall{query: query, args: values})
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return fakeConn{f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return fakeDriver{f} }
+
+type fakeDriver struct{ db *fakeDB }
+
+func (d fakeDriver) Open(string) (driver.Conn, error) { return fakeConn(d), nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+func (c fakeConn) Close() error              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error) { return nil, errors.New("transactions not supported") }
+
+func (c fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.record(query, args)
+	return driver.RowsAffected(c.db.rowsAffected), nil
+}
+
+func (c fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.record(query, args)
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	return &fakeRows{columns: c.db.columns, rows: c.db.rows}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestSQLStoreAdd_InsertsPendingRecord(t *testing.T) {
+	fake, db := newFakeDB(t)
+	created := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	err := NewSQLStore(db).Add(context.Background(), Record{
+		ID:        "rec-1",
+		Topic:     "round.created.v1",
+		Payload:   []byte(`{"round_id":"r1"}`),
+		Metadata:  map[string]string{"topic": "round.created.v1"},
+		CreatedAt: created,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	call := fake.lastCall(t)
+	if !strings.HasPrefix(call.query, "INSERT INTO event_outbox") || !strings.Contains(call.query, "VALUES ($1, $2, $3, $4, $5) ON CONFLICT (id) DO NOTHING") {
+		t.Fatalf("unexpected insert query %q", call.query)
+	}
+	if call.args[0] != "rec-1" || call.args[1] != "round.created.v1" || call.args[3] != `{"topic":"round.created.v1"}` {
+		t.Fatalf("unexpected insert args %v", call.args)
+	}
+	if at, ok := call.args[4].(time.Time); !ok || !at.Equal(created) {
+		t.Fatalf("expected created_at %v, got %v", created, call.args[4])
+	}
+}
+
+func TestSQLStorePending_ClaimsBatchWithLease(t *testing.T) {
+	fake, db := newFakeDB(t)
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	fake.columns = []string{"id", "topic", "payload", "metadata", "created_at", "attempts", "last_error"}
+	// RETURNING gives no ordering guarantee.
+	fake.rows = [][]driver.Value{
+		{"rec-2", "round.updated.v1", []byte(`{}`), `{"topic":"round.updated.v1"}`, now.Add(-time.Second), int64(0), ""},
+		{"rec-1", "round.created.v1", []byte(`{}`), `{"topic":"round.created.v1"}`, now.Add(-time.Minute), int64(2), "nats unavailable"},
+	}
+
+	store := NewSQLStore(db, WithLeaseDuration(time.Minute))
+	store.now = func() time.Time { return now }
+
+	records, err := store.Pending(context.Background(), 50)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	call := fake.lastCall(t)
+	for _, want := range []string{
+		"UPDATE event_outbox SET locked_until = $1 WHERE id IN (",
+		"published_at IS NULL AND parked_at IS NULL AND (locked_until IS NULL OR locked_until < $2)",
+		"ORDER BY created_at, id LIMIT $3 FOR UPDATE SKIP LOCKED)",
+		"RETURNING id, topic, payload, metadata, created_at, attempts, last_error",
+	} {
+		if !strings.Contains(call.query, want) {
+			t.Fatalf("expected claim query to contain %q, got %q", want, call.query)
+		}
+	}
+	if lease, ok := call.args[0].(time.Time); !ok || !lease.Equal(now.Add(time.Minute)) {
+		t.Fatalf("expected lease until %v, got %v", now.Add(time.Minute), call.args[0])
+	}
+	if cutoff, ok := call.args[1].(time.Time); !ok || !cutoff.Equal(now) {
+		t.Fatalf("expected expired-lease cutoff %v, got %v", now, call.args[1])
+	}
+	if call.args[2] != int64(50) {
+		t.Fatalf("expected limit 50, got %v", call.args[2])
+	}
+
+	if len(records) != 2 || records[0].ID != "rec-1" || records[1].ID != "rec-2" {
+		t.Fatalf("expected records oldest first, got %+v", records)
+	}
+	if records[0].Attempts != 2 || records[0].LastError != "nats unavailable" || records[0].Metadata["topic"] != "round.created.v1" {
+		t.Fatalf("unexpected scanned record %+v", records[0])
+	}
+}
+
+func TestSQLStorePending_WithoutRowLocking(t *testing.T) {
+	fake, db := newFakeDB(t)
+	fake.columns = []string{"id", "topic", "payload", "metadata", "created_at", "attempts", "last_error"}
+
+	if _, err := NewSQLStore(db, WithoutRowLocking(), WithQuestionPlaceholders()).Pending(context.Background(), 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	call := fake.lastCall(t)
+	if strings.Contains(call.query, "SKIP LOCKED") || strings.Contains(call.query, "LIMIT") {
+		t.Fatalf("expected no row locking or limit, got %q", call.query)
+	}
+	if !strings.Contains(call.query, "locked_until = ?") || len(call.args) != 2 {
+		t.Fatalf("expected lease claim with ? placeholders, got %q %v", call.query, call.args)
+	}
+}
+
+func TestSQLStoreMarkFailed_ReleasesLease(t *testing.T) {
+	fake, db := newFakeDB(t)
+
+	if err := NewSQLStore(db).MarkFailed(context.Background(), "rec-1", "nats unavailable"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	call := fake.lastCall(t)
+	if call.query != "UPDATE event_outbox SET attempts = attempts + 1, last_error = $1, locked_until = NULL WHERE id = $2" {
+		t.Fatalf("unexpected query %q", call.query)
+	}
+	if call.args[0] != "nats unavailable" || call.args[1] != "rec-1" {
+		t.Fatalf("unexpected args %v", call.args)
+	}
+}
+
+func TestSQLStoreMarkParked(t *testing.T) {
+	fake, db := newFakeDB(t)
+	parkedAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	if err := NewSQLStore(db).MarkParked(context.Background(), "rec-1", "invalid payload", parkedAt); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	call := fake.lastCall(t)
+	if !strings.Contains(call.query, "parked_at = $2, locked_until = NULL WHERE id = $3") {
+		t.Fatalf("unexpected query %q", call.query)
+	}
+	if at, ok := call.args[1].(time.Time); !ok || !at.Equal(parkedAt) || call.args[2] != "rec-1" {
+		t.Fatalf("unexpected args %v", call.args)
+	}
+}
+
+func TestSQLStoreMarkPublished_MissingRecord(t *testing.T) {
+	fake, db := newFakeDB(t)
+	fake.rowsAffected = 0
+
+	err := NewSQLStore(db).MarkPublished(context.Background(), "missing", time.Now())
+	if !errors.Is(err, ErrRecordNotFound) {
+		t.Fatalf("expected ErrRecordNotFound, got %v", err)
+	}
+}
+
+func TestSQLStoreRelease(t *testing.T) {
+	fake, db := newFakeDB(t)
+
+	if err := NewSQLStore(db).Release(context.Background(), "rec-1", "rec-2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	call := fake.lastCall(t)
+	if call.query != "UPDATE event_outbox SET locked_until = NULL WHERE id IN ($1, $2) AND published_at IS NULL" {
+		t.Fatalf("unexpected query %q", call.query)
+	}
+	if len(call.args) != 2 || call.args[0] != "rec-1" || call.args[1] != "rec-2" {
+		t.Fatalf("unexpected args %v", call.args)
+	}
+}
+
+func TestSQLStoreStats(t *testing.T) {
+	fake, db := newFakeDB(t)
+	oldest := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	fake.columns = []string{"pending", "oldest", "parked"}
+	fake.rows = [][]driver.Value{{int64(3), oldest, int64(1)}}
+
+	stats, err := NewSQLStore(db).Stats(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Pending != 3 || stats.Parked != 1 || !stats.OldestCreatedAt.Equal(oldest) {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	if call := fake.lastCall(t); !strings.Contains(call.query, "WHERE published_at IS NULL") {
+		t.Fatalf("unexpected stats query %q", call.query)
+	}
+}
+
+func TestSQLStoreRelay_ReleasesUnpublishedTailOnFailure(t *testing.T) {
+	fake, db := newFakeDB(t)
+	now := time.Now().UTC()
+	fake.columns = []string{"id", "topic", "payload", "metadata", "created_at", "attempts", "last_error"}
+	fake.rows = [][]driver.Value{
+		{"rec-1", "round.failed.v1", []byte(`{}`), `{}`, now.Add(-2 * time.Second), int64(0), ""},
+		{"rec-2", "round.updated.v1", []byte(`{}`), `{}`, now.Add(-time.Second), int64(0), ""},
+	}
+	store := NewSQLStore(db)
+
+	// The Stats scan fails on these rows, which the relay only logs.
+	relay := newTestRelay(store, &fakePublisher{failOn: "round.failed.v1"})
+	if _, err := relay.Drain(context.Background()); err == nil {
+		t.Fatal("expected publish failure")
+	}
+
+	call := fake.lastCall(t)
+	if !strings.Contains(call.query, "SET locked_until = NULL WHERE id IN ($1)") || call.args[0] != "rec-2" {
+		t.Fatalf("expected the unpublished tail to be released, got %q %v", call.query, call.args)
+	}
+}
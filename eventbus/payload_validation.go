@@ -0,0 +1,253 @@
+package eventbus
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/observability/attr"
+	eventbusmetrics "github.com/Black-And-White-Club/frolf-bot-shared/observability/otel/metrics/eventbus"
+	"github.com/Black-And-White-Club/frolf-bot-shared/utils"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+// ErrPayloadValidation is wrapped by every PayloadValidationError.
+var ErrPayloadValidation = errors.New("payload does not match registered type")
+
+// Validation directions reported in metrics.
+const (
+	payloadDirectionPublish = "publish"
+	payloadDirectionConsume = "consume"
+)
+
+// PayloadRegistry looks up the registered payload prototype for a topic
+// (e.g. &roundevents.RoundCreatedPayloadV1{}). catalog.Catalog implements it.
+type PayloadRegistry interface {
+	PayloadFor(topic string) (any, bool)
+}
+
+// PayloadValidationError describes a payload that does not match its topic's registered type.
+type PayloadValidationError struct {
+	Topic       string
+	PayloadType string
+	Reason      string
+}
+
+func (e *PayloadValidationError) Error() string {
+	return fmt.Sprintf("invalid payload for %s (expected %s): %s", e.Topic, e.PayloadType, e.Reason)
+}
+
+func (e *PayloadValidationError) Unwrap() error {
+	return ErrPayloadValidation
+}
+
+// PayloadValidationMode selects how strictly payloads are checked against their
+// registered type. Payloads that do not decode into the type are refused in every mode.
+type PayloadValidationMode int
+
+const (
+	// PayloadValidationLenient logs and counts missing required fields, and on publish
+	// fields unknown to the type, as warnings, so producers that predate the registry
+	// keep working.
+	PayloadValidationLenient PayloadValidationMode = iota
+	// PayloadValidationStrict refuses payloads missing a required (non-pointer,
+	// non-omitempty) field, and published payloads carrying fields unknown to the type.
+	PayloadValidationStrict
+)
+
+// SubjectMetadataKey holds the subject a message was delivered on. The JetStream
+// subscriber sets it from the delivery, so it is reliable where the producer-set topic
+// metadata may be missing.
+const SubjectMetadataKey = utils.MetadataSubject
+
+// MessageTopic returns the subject msg was delivered on, falling back to its topic
+// metadata for messages that did not come from a subscriber.
+func MessageTopic(msg *message.Message) string {
+	if subject := msg.Metadata.Get(SubjectMetadataKey); subject != "" {
+		return subject
+	}
+	return msg.Metadata.Get("topic")
+}
+
+// WithPayloadValidation validates every published payload against the type registered
+// for its topic, e.g. WithPayloadValidation(catalog.Default(), PayloadValidationStrict).
+// Unknown topics and _INBOX replies are not checked.
+func WithPayloadValidation(registry PayloadRegistry, mode PayloadValidationMode) EventBusOption {
+	return func(eb *eventBus) {
+		eb.payloadRegistry = registry
+		eb.payloadValidation = mode
+	}
+}
+
+// ValidatePayloads is the subscriber-side counterpart of WithPayloadValidation. It checks
+// each message against the type registered for the subject it was delivered on.
+// A payload that does not decode into the registered type, or in strict mode misses a
+// required field, is marked for termination and nacked, so it goes to the dead-letter
+// queue instead of being retried. Unknown fields are tolerated so consumers survive
+// additive changes. metrics may be nil.
+func ValidatePayloads(registry PayloadRegistry, mode PayloadValidationMode, logger *slog.Logger, metrics eventbusmetrics.EventBusMetrics) message.HandlerMiddleware {
+	return func(next message.HandlerFunc) message.HandlerFunc {
+		return func(msg *message.Message) ([]*message.Message, error) {
+			topic := MessageTopic(msg)
+			if topic == "" {
+				return next(msg)
+			}
+
+			warning, err := validatePayload(registry, mode, topic, msg.Payload, false)
+			if err != nil {
+				logger.ErrorContext(msg.Context(), "Rejecting message with invalid payload",
+					attr.Topic(topic),
+					attr.String("message_id", msg.UUID),
+					attr.Error(err),
+				)
+				if metrics != nil {
+					metrics.RecordPayloadValidationFailure(msg.Context(), topic, payloadDirectionConsume)
+				}
+				TerminateMessage(msg, err.Error())
+				return nil, err
+			}
+			if warning != nil {
+				logger.WarnContext(msg.Context(), "Message payload does not fully match its registered type",
+					attr.Topic(topic),
+					attr.String("message_id", msg.UUID),
+					attr.Error(warning),
+				)
+				if metrics != nil {
+					metrics.RecordPayloadValidationWarning(msg.Context(), topic, payloadDirectionConsume)
+				}
+			}
+			return next(msg)
+		}
+	}
+}
+
+// checkPublishPayload validates an outgoing message, refusing it on error and letting it
+// through with a warning otherwise.
+func (eb *eventBus) checkPublishPayload(topic string, msg *message.Message, ctxLogger *slog.Logger) error {
+	warning, err := validatePayload(eb.payloadRegistry, eb.payloadValidation, topic, msg.Payload, true)
+	if err != nil {
+		if eb.metrics != nil {
+			eb.metrics.RecordPayloadValidationFailure(msg.Context(), topic, payloadDirectionPublish)
+		}
+		ctxLogger.Error("Refusing to publish invalid payload", attr.String("message_uuid", msg.UUID), attr.Error(err))
+		return err
+	}
+	if warning != nil {
+		if eb.metrics != nil {
+			eb.metrics.RecordPayloadValidationWarning(msg.Context(), topic, payloadDirectionPublish)
+		}
+		ctxLogger.Warn("Publishing payload that does not fully match its registered type", attr.String("message_uuid", msg.UUID), attr.Error(warning))
+	}
+	return nil
+}
+
+// validatePayload checks data against the type registered for topic. A payload that
+// does not decode is an error. Missing required fields, and on publish fields unknown to
+// the type, are errors in strict mode and are returned as a warning otherwise.
+func validatePayload(registry PayloadRegistry, mode PayloadValidationMode, topic string, data []byte, publish bool) (warning, err error) {
+	if registry == nil {
+		return nil, nil
+	}
+	proto, ok := registry.PayloadFor(topic)
+	if !ok || proto == nil {
+		return nil, nil
+	}
+	t := reflect.TypeOf(proto)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	fail := func(reason string) error {
+		return &PayloadValidationError{Topic: topic, PayloadType: t.String(), Reason: reason}
+	}
+
+	if err := json.Unmarshal(data, reflect.New(t).Interface()); err != nil {
+		return nil, fail(err.Error())
+	}
+
+	var problems []string
+	if publish {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(reflect.New(t).Interface()); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if required := requiredJSONFields(t); len(required) > 0 {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, fail(err.Error())
+		}
+		var missing []string
+		for _, name := range required {
+			if _, ok := fields[name]; !ok {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			problems = append(problems, "missing required fields: "+strings.Join(missing, ", "))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil, nil
+	}
+	if mode == PayloadValidationStrict {
+		return nil, fail(strings.Join(problems, "; "))
+	}
+	return fail(strings.Join(problems, "; ")), nil
+}
+
+var requiredFieldsCache sync.Map // reflect.Type -> []string
+
+// requiredJSONFields returns the JSON names of struct fields that are neither
+// pointers nor tagged omitempty, flattening embedded structs like encoding/json.
+func requiredJSONFields(t reflect.Type) []string {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := requiredFieldsCache.Load(t); ok {
+		return cached.([]string)
+	}
+
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+
+		if field.Anonymous && parts[0] == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				continue
+			}
+			fields = append(fields, requiredJSONFields(ft)...)
+			continue
+		}
+
+		omitempty := false
+		for _, opt := range parts[1:] {
+			if opt == "omitempty" || opt == "omitzero" {
+				omitempty = true
+			}
+		}
+		if omitempty || field.Type.Kind() == reflect.Pointer {
+			continue
+		}
+		name := parts[0]
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, name)
+	}
+
+	requiredFieldsCache.Store(t, fields)
+	return fields
+}
@@ -0,0 +1,128 @@
+package eventbus
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/ThreeDotsLabs/watermill-nats/v2/pkg/nats"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+type testRoundDeletedPayload struct {
+	GuildID string  `json:"guild_id"`
+	RoundID string  `json:"round_id"`
+	Reason  *string `json:"reason,omitempty"`
+}
+
+type mapPayloadRegistry map[string]any
+
+func (r mapPayloadRegistry) PayloadFor(topic string) (any, bool) {
+	p, ok := r[topic]
+	return p, ok
+}
+
+var testRegistry = mapPayloadRegistry{
+	"round.deleted.v1": &testRoundDeletedPayload{},
+}
+
+func TestValidatePayload(t *testing.T) {
+	tests := []struct {
+		name        string
+		topic       string
+		payload     string
+		mode        PayloadValidationMode
+		publish     bool
+		wantErr     bool
+		wantWarning bool
+	}{
+		{name: "valid", topic: "round.deleted.v1", payload: `{"guild_id":"g","round_id":"r"}`, mode: PayloadValidationStrict, publish: true},
+		{name: "missing required field", topic: "round.deleted.v1", payload: `{"guild_id":"g"}`, mode: PayloadValidationStrict, publish: true, wantErr: true},
+		{name: "unknown field on publish", topic: "round.deleted.v1", payload: `{"guild_id":"g","round_id":"r","title":"Doubles"}`, mode: PayloadValidationStrict, publish: true, wantErr: true},
+		{name: "unknown field tolerated on consume", topic: "round.deleted.v1", payload: `{"guild_id":"g","round_id":"r","title":"Doubles"}`, mode: PayloadValidationStrict},
+		{name: "type mismatch", topic: "round.deleted.v1", payload: `{"guild_id":1,"round_id":"r"}`, mode: PayloadValidationStrict, wantErr: true},
+		{name: "unregistered topic", topic: "round.created.v1", payload: `not json`, mode: PayloadValidationStrict, publish: true},
+		{name: "lenient missing field warns", topic: "round.deleted.v1", payload: `{"guild_id":"g"}`, publish: true, wantWarning: true},
+		{name: "lenient unknown field warns", topic: "round.deleted.v1", payload: `{"guild_id":"g","round_id":"r","title":"Doubles"}`, publish: true, wantWarning: true},
+		{name: "lenient type mismatch", topic: "round.deleted.v1", payload: `{"guild_id":1,"round_id":"r"}`, publish: true, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			warning, err := validatePayload(testRegistry, tc.mode, tc.topic, []byte(tc.payload), tc.publish)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("wantErr=%v, got %v", tc.wantErr, err)
+			}
+			if tc.wantWarning != (warning != nil) {
+				t.Fatalf("wantWarning=%v, got %v", tc.wantWarning, warning)
+			}
+			if err != nil && !errors.Is(err, ErrPayloadValidation) {
+				t.Fatalf("expected ErrPayloadValidation, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPublish_AllowsLenientPayloadWithUnknownFields(t *testing.T) {
+	pub := &fakePublisher{}
+	eb := &eventBus{
+		appType:         "backend",
+		logger:          slog.New(slog.NewTextHandler(io.Discard, nil)),
+		marshaler:       &nats.NATSMarshaler{},
+		publisher:       pub,
+		payloadRegistry: testRegistry,
+	}
+
+	if err := eb.Publish("round.deleted.v1", message.NewMessage("id", []byte(`{"guild_id":"g","title":"Doubles"}`))); err != nil {
+		t.Fatalf("expected lenient validation to publish, got %v", err)
+	}
+	if len(pub.calls) != 1 {
+		t.Fatalf("expected one publish, got %d calls", len(pub.calls))
+	}
+}
+
+func TestPublish_RejectsInvalidPayloadWhenValidationEnabled(t *testing.T) {
+	pub := &fakePublisher{}
+	eb := &eventBus{
+		appType:           "backend",
+		logger:            slog.New(slog.NewTextHandler(io.Discard, nil)),
+		marshaler:         &nats.NATSMarshaler{},
+		publisher:         pub,
+		payloadRegistry:   testRegistry,
+		payloadValidation: PayloadValidationStrict,
+	}
+
+	err := eb.Publish("round.deleted.v1", message.NewMessage("id", []byte(`{"guild_id":"g","title":"Doubles"}`)))
+	if !errors.Is(err, ErrPayloadValidation) {
+		t.Fatalf("expected payload validation error, got %v", err)
+	}
+	if len(pub.calls) != 0 {
+		t.Fatalf("expected nothing to be published, got %d calls", len(pub.calls))
+	}
+}
+
+func TestValidatePayloads_MarksInvalidMessagesForTermination(t *testing.T) {
+	called := false
+	handler := ValidatePayloads(testRegistry, PayloadValidationStrict, slog.New(slog.NewTextHandler(io.Discard, nil)), nil)(
+		func(msg *message.Message) ([]*message.Message, error) {
+			called = true
+			return nil, nil
+		},
+	)
+
+	msg := message.NewMessage("id", []byte(`{"guild_id":"g"}`))
+	// The delivered subject wins over producer-set topic metadata.
+	msg.Metadata.Set("topic", "round.created.v1")
+	msg.Metadata.Set(SubjectMetadataKey, "round.deleted.v1")
+
+	if _, err := handler(msg); err == nil {
+		t.Fatal("expected validation error")
+	}
+	if called {
+		t.Fatal("expected handler not to run for invalid payload")
+	}
+	if msg.Metadata.Get(TerminateMetadataKey) == "" || msg.Metadata.Get(LastErrorMetadataKey) == "" {
+		t.Fatalf("expected message to be marked for termination, got %v", msg.Metadata)
+	}
+}
@@ -0,0 +1,318 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/observability/attr"
+	"github.com/ThreeDotsLabs/watermill/message"
+	nc "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	// defaultPublishAsyncMaxPending is how many async publishes may await their ack
+	// before PublishAsync blocks.
+	defaultPublishAsyncMaxPending = 256
+
+	// publishAsyncAckTimeout bounds the wait for each async publish ack. It matches the
+	// JetStream default used by synchronous publishes.
+	publishAsyncAckTimeout = 5 * time.Second
+)
+
+// WithPublishAsyncMaxPending sets how many PublishAsync messages may await their
+// JetStream ack at once. PublishAsync blocks while the window is full.
+func WithPublishAsyncMaxPending(n int) EventBusOption {
+	return func(eb *eventBus) {
+		if n > 0 {
+			eb.asyncMaxPending = n
+		}
+	}
+}
+
+// PublishAck is JetStream's acknowledgement of a published message.
+type PublishAck struct {
+	MessageID string
+	Stream    string
+	Sequence  uint64
+	// Duplicate reports that the stream dropped the message as a Nats-Msg-Id duplicate;
+	// Sequence is then the sequence of the original.
+	Duplicate bool
+}
+
+// PublishFuture resolves once JetStream acknowledges, or fails, one async publish.
+type PublishFuture struct {
+	done chan struct{}
+	ack  PublishAck
+	err  error
+}
+
+func newPublishFuture() *PublishFuture {
+	return &PublishFuture{done: make(chan struct{})}
+}
+
+// ResolvedPublishFuture returns a future that has already completed with ack or err,
+// for EventBus implementations that publish synchronously.
+func ResolvedPublishFuture(ack PublishAck, err error) *PublishFuture {
+	f := newPublishFuture()
+	f.resolve(ack, err)
+	return f
+}
+
+func (f *PublishFuture) resolve(ack PublishAck, err error) {
+	f.ack, f.err = ack, err
+	close(f.done)
+}
+
+// Done is closed once the publish has been acknowledged or has failed.
+func (f *PublishFuture) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the publish completes or ctx is done.
+func (f *PublishFuture) Wait(ctx context.Context) (PublishAck, error) {
+	select {
+	case <-f.done:
+		return f.ack, f.err
+	default:
+	}
+	select {
+	case <-f.done:
+		return f.ack, f.err
+	case <-ctx.Done():
+		return PublishAck{}, ctx.Err()
+	}
+}
+
+// WaitPublishFutures waits for every future, returning the acks in order. Failed and
+// unfinished publishes leave a zero PublishAck and are reported in the joined error.
+func WaitPublishFutures(ctx context.Context, futures []*PublishFuture) ([]PublishAck, error) {
+	acks := make([]PublishAck, len(futures))
+	var errs []error
+	for i, f := range futures {
+		ack, err := f.Wait(ctx)
+		if ctx.Err() != nil && err == ctx.Err() {
+			errs = append(errs, fmt.Errorf("%d of %d publishes not acknowledged: %w", len(futures)-i, len(futures), err))
+			break
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		acks[i] = ack
+	}
+	return acks, errors.Join(errs...)
+}
+
+// pendingPublish tracks one message between its async publish and its ack.
+type pendingPublish struct {
+	msg     *message.Message
+	natsMsg *nc.Msg
+	ack     jetstream.PubAckFuture
+	future  *PublishFuture
+	endSpan func(error)
+}
+
+// await returns the message's ack, or the error it failed with.
+func (p *pendingPublish) await() (PublishAck, error) {
+	select {
+	case pubAck := <-p.ack.Ok():
+		return PublishAck{
+			MessageID: p.msg.UUID,
+			Stream:    pubAck.Stream,
+			Sequence:  pubAck.Sequence,
+			Duplicate: pubAck.Duplicate,
+		}, nil
+	case err := <-p.ack.Err():
+		return PublishAck{}, err
+	}
+}
+
+// PublishAsync publishes messages to topic with JetStream async publish and returns a
+// future per message, in order, without waiting for acks. ctx bounds only the wait for
+// room in the max-pending window. Async publishes go through the circuit breaker but
+// are never spooled; use Publish for messages that must survive a JetStream outage.
+//
+// An error means no message was published. Once publishing starts, failures are
+// reported through the futures.
+func (eb *eventBus) PublishAsync(ctx context.Context, topic string, messages ...*message.Message) ([]*PublishFuture, error) {
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	if topic == "" {
+		return nil, errors.New("async publish requires a topic")
+	}
+	if eb.marshaler == nil {
+		return nil, errors.New("eventBus marshaler is not set")
+	}
+	if strings.HasPrefix(topic, "_INBOX.") {
+		return nil, fmt.Errorf("async publish to inbox topic %q is not supported", topic)
+	}
+	if err := eb.checkPublishBoundary(topic); err != nil {
+		return nil, err
+	}
+	js := eb.asyncJS
+	if js == nil {
+		js = eb.js
+	}
+	if js == nil {
+		return nil, errors.New("eventBus JetStream is not set")
+	}
+
+	ctxLogger := eb.logger.With(
+		"operation", "publish_async",
+		"topic", topic,
+		"message_count", len(messages),
+	)
+
+	if err := eb.preparePublish(topic, messages, ctxLogger); err != nil {
+		return nil, err
+	}
+
+	// Marshal everything up front so a bad message fails the call before any is sent.
+	pending := make([]*pendingPublish, len(messages))
+	for i, msg := range messages {
+		endSpan := eb.startPublishSpans(topic, []*message.Message{msg})
+		natsMsg, err := eb.marshaler.Marshal(topic, msg)
+		if err != nil {
+			endSpan(err)
+			for _, p := range pending[:i] {
+				p.endSpan(err)
+			}
+			ctxLogger.Error("Failed to marshal message", attr.String("message_uuid", msg.UUID), attr.Error(err))
+			return nil, fmt.Errorf("failed to marshal message %s for %s: %w", msg.UUID, topic, err)
+		}
+		pending[i] = &pendingPublish{msg: msg, natsMsg: natsMsg, future: newPublishFuture(), endSpan: endSpan}
+	}
+
+	if eb.breaker != nil && !eb.breaker.allow() {
+		err := fmt.Errorf("publish to %s: %w", topic, ErrCircuitOpen)
+		for _, p := range pending {
+			p.endSpan(err)
+		}
+		if eb.metrics != nil {
+			eb.metrics.RecordMessagePublishError(messages[0].Context(), topic)
+		}
+		return nil, err
+	}
+
+	if eb.metrics != nil {
+		eb.metrics.RecordMessagePublish(messages[0].Context(), topic)
+	}
+
+	futures := make([]*PublishFuture, len(pending))
+	for i, p := range pending {
+		futures[i] = p.future
+	}
+	for i, p := range pending {
+		ack, err := publishMsgAsync(ctx, js, p.natsMsg)
+		if err != nil {
+			// The rest of the batch was never sent; fail it without touching the breaker.
+			ctxLogger.Error("Async publish stopped", attr.Int("unsent", len(pending)-i), attr.Error(err))
+			for _, unsent := range pending[i:] {
+				unsent.endSpan(err)
+				unsent.future.resolve(PublishAck{}, fmt.Errorf("publish %s to %s: %w", unsent.msg.UUID, topic, err))
+			}
+			pending = pending[:i]
+			break
+		}
+		p.ack = ack
+	}
+
+	go eb.awaitPublishAcks(topic, pending, ctxLogger)
+	return futures, nil
+}
+
+// PublishBatch publishes messages to topic asynchronously and waits until every one is
+// acknowledged or ctx is done. The acks are returned in message order, with failures
+// joined into the error.
+func (eb *eventBus) PublishBatch(ctx context.Context, topic string, messages ...*message.Message) ([]PublishAck, error) {
+	futures, err := eb.PublishAsync(ctx, topic, messages...)
+	if err != nil {
+		return nil, err
+	}
+	return WaitPublishFutures(ctx, futures)
+}
+
+// publishMsgAsync submits msg, waiting while the max-pending window is full.
+func publishMsgAsync(ctx context.Context, js jetstream.JetStream, msg *nc.Msg) (jetstream.PubAckFuture, error) {
+	for {
+		ack, err := js.PublishMsgAsync(msg)
+		if !errors.Is(err, jetstream.ErrTooManyStalledMsgs) {
+			return ack, err
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// awaitPublishAcks resolves the futures of pending as their acks arrive. With dual
+// publish enabled the futures resolve once the downcast copies are published too, so a
+// downcast failure is reported like it is by Publish.
+func (eb *eventBus) awaitPublishAcks(topic string, pending []*pendingPublish, ctxLogger *slog.Logger) {
+	acks := make([]PublishAck, len(pending))
+	errs := make([]error, len(pending))
+	var outcome error
+	for i, p := range pending {
+		ack, err := p.await()
+		p.endSpan(err)
+		if err != nil {
+			err = fmt.Errorf("publish %s to %s: %w", p.msg.UUID, topic, err)
+			if eb.metrics != nil {
+				eb.metrics.RecordMessagePublishError(p.msg.Context(), topic)
+			}
+			ctxLogger.Error("Async publish failed", attr.String("message_uuid", p.msg.UUID), attr.Error(err))
+			if outcome == nil && eb.isRetryableError(err) {
+				outcome = err
+			}
+		}
+		if eb.versions == nil {
+			p.future.resolve(ack, err)
+			continue
+		}
+		acks[i], errs[i] = ack, err
+	}
+	if len(pending) > 0 {
+		eb.recordPublishOutcome(outcome)
+	}
+	if eb.versions == nil {
+		return
+	}
+
+	var published []*message.Message
+	for i, p := range pending {
+		if errs[i] == nil {
+			published = append(published, p.msg)
+		}
+	}
+	if len(published) > 0 {
+		if err := eb.publishDowncasts(topic, published, ctxLogger); err != nil {
+			for i := range errs {
+				if errs[i] == nil {
+					errs[i] = err
+				}
+			}
+		}
+	}
+	for i, p := range pending {
+		p.future.resolve(acks[i], errs[i])
+	}
+}
+
+// waitAsyncPublishes waits until every async publish has its ack, or ctx is done, so
+// draining the connection does not strand them.
+func (eb *eventBus) waitAsyncPublishes(ctx context.Context, ctxLogger *slog.Logger) {
+	if eb.asyncJS == nil || eb.asyncJS.PublishAsyncPending() == 0 {
+		return
+	}
+	select {
+	case <-eb.asyncJS.PublishAsyncComplete():
+	case <-ctx.Done():
+		ctxLogger.Warn("Async publishes still awaiting acks", attr.Int("pending", eb.asyncJS.PublishAsyncPending()))
+	}
+}
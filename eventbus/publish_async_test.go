@@ -0,0 +1,71 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill-nats/v2/pkg/nats"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+func TestWaitPublishFutures_ReturnsAcksInOrderAndJoinsErrors(t *testing.T) {
+	failed := errors.New("no responders")
+	futures := []*PublishFuture{
+		ResolvedPublishFuture(PublishAck{MessageID: "a", Stream: "score", Sequence: 1}, nil),
+		ResolvedPublishFuture(PublishAck{}, failed),
+		ResolvedPublishFuture(PublishAck{MessageID: "c", Stream: "score", Sequence: 2}, nil),
+	}
+
+	acks, err := WaitPublishFutures(context.Background(), futures)
+	if !errors.Is(err, failed) {
+		t.Fatalf("expected joined publish error, got %v", err)
+	}
+	if acks[0].Sequence != 1 || acks[1] != (PublishAck{}) || acks[2].Sequence != 2 {
+		t.Fatalf("unexpected acks %+v", acks)
+	}
+}
+
+func TestWaitPublishFutures_StopsAtDeadline(t *testing.T) {
+	pending := newPublishFuture()
+	futures := []*PublishFuture{
+		ResolvedPublishFuture(PublishAck{Sequence: 1}, nil),
+		pending,
+		newPublishFuture(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	acks, err := WaitPublishFutures(ctx, futures)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error, got %v", err)
+	}
+	if acks[0].Sequence != 1 {
+		t.Fatalf("expected the resolved ack to be kept, got %+v", acks[0])
+	}
+
+	// A resolved future still reports its result once the context is done.
+	pending.resolve(PublishAck{Sequence: 2}, nil)
+	if ack, err := pending.Wait(ctx); err != nil || ack.Sequence != 2 {
+		t.Fatalf("expected resolved ack, got %+v, %v", ack, err)
+	}
+}
+
+func TestPublishAsync_RejectsBeforePublishing(t *testing.T) {
+	eb := &eventBus{
+		appType:   "backend",
+		logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		marshaler: &nats.NATSMarshaler{},
+	}
+
+	for _, topic := range []string{"", "_INBOX.reply", "discord.leaderboard.tag.lookup.succeeded.v1"} {
+		futures, err := eb.PublishAsync(context.Background(), topic, message.NewMessage("id", []byte("payload")))
+		if err == nil || futures != nil {
+			t.Fatalf("expected %q to be rejected, got %v", topic, err)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/observability/attr"
+	"github.com/ThreeDotsLabs/watermill/message"
+	nc "github.com/nats-io/nats.go"
+)
+
+// WithCircuitBreaker tunes the publish circuit breaker, which opens after consecutive
+// connectivity failures so publishes fail fast (or are spooled) while NATS is down.
+func WithCircuitBreaker(cfg CircuitBreakerConfig) EventBusOption {
+	return func(eb *eventBus) {
+		eb.circuitConfig = cfg
+	}
+}
+
+// WithPublishSpool buffers publishes on disk while JetStream is unreachable and flushes
+// them in order once it recovers. Publish returns nil for spooled messages, and
+// ErrSpoolFull once the spool's limits are reached.
+func WithPublishSpool(cfg SpoolConfig) EventBusOption {
+	return func(eb *eventBus) {
+		eb.spoolConfig = &cfg
+	}
+}
+
+// publishWithRetry publishes through the circuit breaker, retrying retryable errors.
+func (eb *eventBus) publishWithRetry(topic string, messages []*message.Message, ctxLogger *slog.Logger) error {
+	if eb.breaker != nil && !eb.breaker.allow() {
+		if eb.metrics != nil {
+			eb.metrics.RecordMessagePublishError(messages[0].Context(), topic)
+		}
+		return fmt.Errorf("publish to %s: %w", topic, ErrCircuitOpen)
+	}
+
+	const maxRetries = 3
+	var lastErr error
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		err := eb.publisher.Publish(topic, messages...)
+		if err == nil {
+			eb.recordPublishOutcome(nil)
+			return nil
+		}
+		lastErr = err
+		if attempt < maxRetries && eb.isRetryableError(err) {
+			if eb.metrics != nil {
+				eb.metrics.RecordPublishRetry(messages[0].Context(), topic)
+			}
+			time.Sleep(time.Duration(attempt) * 100 * time.Millisecond)
+			continue
+		}
+		if eb.metrics != nil {
+			eb.metrics.RecordMessagePublishError(messages[0].Context(), topic)
+		}
+		ctxLogger.Error("Publish failed", "error", err, "attempts", attempt)
+		eb.recordPublishOutcome(err)
+		return fmt.Errorf("publish to %s failed after %d attempts: %w", topic, attempt, lastErr)
+	}
+	return lastErr
+}
+
+// recordPublishOutcome feeds the circuit breaker. Only connectivity errors count as
+// failures; anything else proves JetStream is reachable.
+func (eb *eventBus) recordPublishOutcome(err error) {
+	if eb.breaker == nil {
+		return
+	}
+	if err != nil && eb.isRetryableError(err) {
+		eb.breaker.failure()
+		return
+	}
+	eb.breaker.success()
+}
+
+func (eb *eventBus) onCircuitStateChange(from, to CircuitState) {
+	eb.logger.Warn("Publish circuit breaker state changed",
+		attr.String("from", from.String()),
+		attr.String("to", to.String()),
+	)
+	if eb.metrics != nil {
+		eb.metrics.RecordCircuitStateChange(context.Background(), to.String())
+	}
+	if to == CircuitClosed {
+		eb.wakeSpoolFlusher()
+	}
+}
+
+// shouldSpool reports whether a publish must queue behind already spooled messages to
+// keep publish order.
+func (eb *eventBus) shouldSpool() bool {
+	return eb.spool != nil && eb.spool.Len() > 0
+}
+
+// canSpool reports whether a failed publish may be spooled instead of returned.
+func (eb *eventBus) canSpool(err error) bool {
+	return eb.spool != nil && (errors.Is(err, ErrCircuitOpen) || eb.isRetryableError(err))
+}
+
+// spoolMessages writes messages to the spool in order. Messages that do not fit are
+// counted as dropped and reported with ErrSpoolFull.
+func (eb *eventBus) spoolMessages(topic string, messages []*message.Message, ctxLogger *slog.Logger) error {
+	for i, msg := range messages {
+		if err := eb.spool.append(topic, msg); err != nil {
+			dropped := len(messages) - i
+			if eb.metrics != nil {
+				for _, m := range messages[i:] {
+					eb.metrics.RecordSpoolDropped(m.Context(), topic)
+				}
+			}
+			ctxLogger.Error("Failed to spool message", attr.Error(err), attr.Int("dropped", dropped))
+			return fmt.Errorf("publish to %s: %d messages not spooled: %w", topic, dropped, err)
+		}
+		if eb.metrics != nil {
+			eb.metrics.RecordSpooledMessage(msg.Context(), topic)
+		}
+	}
+	eb.recordSpoolDepth()
+
+	ctxLogger.Warn("JetStream unavailable, messages spooled", attr.Int("spool_depth", eb.spool.Len()))
+	eb.wakeSpoolFlusher()
+	return nil
+}
+
+func (eb *eventBus) recordSpoolDepth() {
+	if eb.metrics != nil {
+		eb.metrics.RecordSpoolDepth(context.Background(), int64(eb.spool.Len()))
+	}
+}
+
+// startSpool opens the spool and starts flushing it, including anything left on disk
+// by a previous run.
+func (eb *eventBus) startSpool() error {
+	spool, err := openDiskSpool(*eb.spoolConfig)
+	if err != nil {
+		return err
+	}
+	eb.spool = spool
+	eb.spoolWake = make(chan struct{}, 1)
+
+	interval := eb.spoolConfig.FlushInterval
+	if interval <= 0 {
+		interval = defaultSpoolFlushInterval
+	}
+
+	if eb.natsConn != nil {
+		eb.natsConn.SetReconnectHandler(func(*nc.Conn) { eb.wakeSpoolFlusher() })
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	eb.spoolCancel = cancel
+	eb.spoolDone = make(chan struct{})
+	go eb.runSpoolFlusher(ctx, interval)
+
+	if n := spool.Len(); n > 0 {
+		eb.logger.Info("Recovered spooled messages", attr.Int("spool_depth", n))
+		eb.recordSpoolDepth()
+		eb.wakeSpoolFlusher()
+	}
+	return nil
+}
+
+func (eb *eventBus) wakeSpoolFlusher() {
+	if eb.spoolWake == nil {
+		return
+	}
+	select {
+	case eb.spoolWake <- struct{}{}:
+	default:
+	}
+}
+
+// stopSpoolFlusher stops flushing. Messages still spooled stay on disk for the next run.
+func (eb *eventBus) stopSpoolFlusher() {
+	if eb.spoolCancel == nil {
+		return
+	}
+	eb.spoolCancel()
+	<-eb.spoolDone
+	if n := eb.spool.Len(); n > 0 {
+		eb.logger.Warn("Stopped with messages still spooled", attr.Int("spool_depth", n))
+	}
+}
+
+func (eb *eventBus) runSpoolFlusher(ctx context.Context, interval time.Duration) {
+	defer close(eb.spoolDone)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-eb.spoolWake:
+		}
+		eb.flushSpool(ctx)
+	}
+}
+
+// flushSpool publishes spooled messages oldest first, stopping at the first connectivity
+// failure so order is kept. A message JetStream rejects outright is discarded so it
+// cannot block the spool.
+func (eb *eventBus) flushSpool(ctx context.Context) {
+	flushed := 0
+	defer func() {
+		if flushed > 0 {
+			eb.logger.Info("Flushed spooled messages", attr.Int("flushed", flushed), attr.Int("spool_depth", eb.spool.Len()))
+		}
+	}()
+
+	for ctx.Err() == nil {
+		m, seq, ok, err := eb.spool.peek()
+		if !ok {
+			return
+		}
+		if err != nil {
+			eb.logger.Error("Discarding unreadable spooled message", attr.Error(err))
+			if !eb.discardSpooled(seq, "") {
+				return
+			}
+			continue
+		}
+
+		if eb.breaker != nil && !eb.breaker.allow() {
+			return
+		}
+		err = eb.publisher.Publish(m.Topic, m.toMessage())
+		eb.recordPublishOutcome(err)
+		if err != nil && eb.isRetryableError(err) {
+			eb.logger.Debug("Spool flush paused, JetStream still unavailable", attr.Error(err))
+			return
+		}
+		if err != nil {
+			eb.logger.Error("Discarding spooled message rejected by JetStream",
+				attr.Topic(m.Topic),
+				attr.String("message_id", m.UUID),
+				attr.Error(err),
+			)
+			if !eb.discardSpooled(seq, m.Topic) {
+				return
+			}
+			continue
+		}
+
+		if err := eb.spool.remove(seq); err != nil {
+			eb.logger.Error("Failed to remove flushed message from spool", attr.Error(err))
+			return
+		}
+		flushed++
+		eb.recordSpoolDepth()
+	}
+}
+
+// discardSpooled drops the oldest spooled message, reporting whether it was removed.
+func (eb *eventBus) discardSpooled(seq uint64, topic string) bool {
+	if err := eb.spool.remove(seq); err != nil {
+		eb.logger.Error("Failed to remove message from spool", attr.Error(err))
+		return false
+	}
+	if eb.metrics != nil {
+		eb.metrics.RecordSpoolDropped(context.Background(), topic)
+	}
+	eb.recordSpoolDepth()
+	return true
+}
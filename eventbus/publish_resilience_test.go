@@ -0,0 +1,166 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill-nats/v2/pkg/nats"
+	"github.com/ThreeDotsLabs/watermill/message"
+	nc "github.com/nats-io/nats.go"
+)
+
+// flakyPublisher fails with a connectivity error while down.
+type flakyPublisher struct {
+	mu     sync.Mutex
+	down   bool
+	reject error
+	calls  []publishCall
+}
+
+func (f *flakyPublisher) Publish(topic string, messages ...*message.Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.down {
+		return nc.ErrNoServers
+	}
+	if f.reject != nil {
+		return f.reject
+	}
+	f.calls = append(f.calls, publishCall{topic: topic, msgs: messages})
+	return nil
+}
+
+func (f *flakyPublisher) Close() error { return nil }
+
+func (f *flakyPublisher) setDown(down bool) {
+	f.mu.Lock()
+	f.down = down
+	f.mu.Unlock()
+}
+
+func newSpoolingTestBus(t *testing.T, publisher message.Publisher, cfg SpoolConfig) *eventBus {
+	t.Helper()
+	eb := &eventBus{
+		appType:     "backend",
+		publisher:   publisher,
+		logger:      slog.New(slog.NewTextHandler(io.Discard, nil)),
+		marshaler:   &nats.NATSMarshaler{},
+		spoolConfig: &cfg,
+	}
+	eb.breaker = newCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: time.Millisecond}, eb.onCircuitStateChange)
+	eb.spool, _ = openDiskSpool(cfg)
+	if eb.spool == nil {
+		t.Fatal("failed to open spool")
+	}
+	return eb
+}
+
+func TestPublish_SpoolsWhileUnavailableAndFlushesInOrder(t *testing.T) {
+	fp := &flakyPublisher{down: true}
+	eb := newSpoolingTestBus(t, fp, SpoolConfig{Dir: t.TempDir()})
+
+	for _, key := range []string{"a", "b", "c"} {
+		msg := message.NewMessage("uuid-"+key, []byte(key))
+		msg.Metadata.Set("idempotency_key", key)
+		if err := eb.Publish("round.created.v1", msg); err != nil {
+			t.Fatalf("expected publish to be spooled, got %v", err)
+		}
+	}
+	if eb.spool.Len() != 3 {
+		t.Fatalf("expected 3 spooled messages, got %d", eb.spool.Len())
+	}
+	if eb.breaker.State() != CircuitOpen {
+		t.Fatalf("expected circuit to be open, got %s", eb.breaker.State())
+	}
+
+	fp.setDown(false)
+	time.Sleep(5 * time.Millisecond) // let the open timeout elapse
+	eb.flushSpool(context.Background())
+
+	if eb.spool.Len() != 0 || len(fp.calls) != 3 {
+		t.Fatalf("expected spool to flush, depth %d, calls %d", eb.spool.Len(), len(fp.calls))
+	}
+	for i, key := range []string{"a", "b", "c"} {
+		got := fp.calls[i].msgs[0]
+		if string(got.Payload) != key || got.Metadata.Get("Nats-Msg-Id") != DedupeMsgID(key, "round.created.v1") {
+			t.Fatalf("unexpected flushed message %d: %s %v", i, got.Payload, got.Metadata)
+		}
+	}
+	if eb.breaker.State() != CircuitClosed {
+		t.Fatalf("expected circuit to close after flushing, got %s", eb.breaker.State())
+	}
+}
+
+func TestPublish_SpoolFullReturnsError(t *testing.T) {
+	fp := &flakyPublisher{down: true}
+	eb := newSpoolingTestBus(t, fp, SpoolConfig{Dir: t.TempDir(), MaxMessages: 1})
+
+	if err := eb.Publish("round.created.v1", message.NewMessage("1", []byte("1"))); err != nil {
+		t.Fatalf("expected first publish to be spooled, got %v", err)
+	}
+	err := eb.Publish("round.created.v1", message.NewMessage("2", []byte("2")))
+	if !errors.Is(err, ErrSpoolFull) {
+		t.Fatalf("expected ErrSpoolFull, got %v", err)
+	}
+}
+
+func TestFlushSpool_DiscardsRejectedMessages(t *testing.T) {
+	fp := &flakyPublisher{down: true}
+	eb := newSpoolingTestBus(t, fp, SpoolConfig{Dir: t.TempDir()})
+	if err := eb.Publish("round.created.v1", message.NewMessage("1", []byte("1"))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fp.mu.Lock()
+	fp.down, fp.reject = false, errors.New("maximum payload exceeded")
+	fp.mu.Unlock()
+	time.Sleep(5 * time.Millisecond)
+	eb.flushSpool(context.Background())
+
+	if eb.spool.Len() != 0 {
+		t.Fatalf("expected rejected message to be discarded, depth %d", eb.spool.Len())
+	}
+}
+
+func TestDiskSpool_RecoversMessagesInOrder(t *testing.T) {
+	dir := t.TempDir()
+	spool, err := openDiskSpool(SpoolConfig{Dir: dir})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, id := range []string{"1", "2"} {
+		msg := message.NewMessage(id, []byte(id))
+		msg.Metadata.Set("Nats-Msg-Id", "msg-"+id)
+		if err := spool.append("round.created.v1", msg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	reopened, err := openDiskSpool(SpoolConfig{Dir: dir})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reopened.Len() != 2 {
+		t.Fatalf("expected 2 recovered messages, got %d", reopened.Len())
+	}
+	m, seq, ok, err := reopened.peek()
+	if !ok || err != nil || m.UUID != "1" || m.Topic != "round.created.v1" || m.toMessage().Metadata.Get("Nats-Msg-Id") != "msg-1" {
+		t.Fatalf("unexpected head %+v %v", m, err)
+	}
+	if err := reopened.remove(seq); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msg := message.NewMessage("3", []byte("3"))
+	if err := reopened.append("round.created.v1", msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m, _, _, _ := reopened.peek(); m.UUID != "2" {
+		t.Fatalf("expected message 2 next, got %s", m.UUID)
+	}
+}
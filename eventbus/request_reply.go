@@ -0,0 +1,183 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/utils"
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/ThreeDotsLabs/watermill/message/router/middleware"
+	nc "github.com/nats-io/nats.go"
+)
+
+// ErrRequestTimeout is returned by Request when no reply arrives before the timeout.
+var ErrRequestTimeout = errors.New("request timed out")
+
+// RequestFailedError is returned by Request when the responder answers on the
+// request's failure topic (e.g. user.get.failed.v1).
+type RequestFailedError struct {
+	// Topic is the failure topic the responder replied with.
+	Topic string
+	// Reason is the "reason" or "error" field of the failure payload, if present.
+	Reason string
+	// Payload is the raw failure payload; use Decode to read it into a typed struct.
+	Payload []byte
+}
+
+func (e *RequestFailedError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("request failed (%s): %s", e.Topic, e.Reason)
+	}
+	return fmt.Sprintf("request failed (%s)", e.Topic)
+}
+
+// Decode unmarshals the failure payload into v (e.g. *userevents.GetUserFailedPayloadV1).
+func (e *RequestFailedError) Decode(v any) error {
+	return json.Unmarshal(e.Payload, v)
+}
+
+// RequestOption configures a Request call.
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	failedTopic string
+	metadata    map[string]string
+}
+
+// WithFailedTopic overrides the failure topic derived by FailedTopicFor.
+func WithFailedTopic(topic string) RequestOption {
+	return func(o *requestOptions) {
+		o.failedTopic = topic
+	}
+}
+
+// WithRequestMetadata adds metadata to the outgoing request message.
+func WithRequestMetadata(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		if o.metadata == nil {
+			o.metadata = make(map[string]string)
+		}
+		o.metadata[key] = value
+	}
+}
+
+// FailedTopicFor derives the sibling failure topic of a request topic by replacing
+// its "requested"/"request" token with "failed":
+//
+//	user.get.requested.v1 -> user.get.failed.v1
+//	club.info.request.v1  -> club.info.failed.v1
+//
+// It returns an empty string when the topic has no request token.
+func FailedTopicFor(topic string) string {
+	tokens := strings.Split(topic, ".")
+	for i := len(tokens) - 1; i >= 0; i-- {
+		if tokens[i] == "requested" || tokens[i] == "request" {
+			tokens[i] = "failed"
+			return strings.Join(tokens, ".")
+		}
+	}
+	return ""
+}
+
+// Request publishes req on topic with a fresh reply inbox and waits for the typed reply.
+//
+// Responders answer on the reply_to inbox and must set reply_topic metadata to the
+// logical event topic; handlerwrapper.ReplyResult does, replies built by hand (e.g. with
+// Helpers.CreateResultMessage) must set utils.MetadataReplyTopic themselves. A reply whose
+// reply_topic matches the failure topic is returned as *RequestFailedError; any other
+// reply is decoded into Resp.
+func Request[Req, Resp any](
+	ctx context.Context,
+	bus EventBus,
+	topic string,
+	req Req,
+	timeout time.Duration,
+	opts ...RequestOption,
+) (*Resp, error) {
+	o := requestOptions{failedTopic: FailedTopicFor(topic)}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	conn := bus.GetNATSConnection()
+	if conn == nil {
+		return nil, errors.New("request requires a NATS connection")
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request for %s: %w", topic, err)
+	}
+
+	// Subscribe before publishing so a fast responder cannot beat us to the inbox.
+	inbox := conn.NewRespInbox()
+	sub, err := conn.SubscribeSync(inbox)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to reply inbox: %w", err)
+	}
+	defer func() { _ = sub.Unsubscribe() }()
+
+	msg := message.NewMessage(watermill.NewUUID(), payload)
+	for k, v := range o.metadata {
+		msg.Metadata.Set(k, v)
+	}
+	if msg.Metadata.Get(middleware.CorrelationIDMetadataKey) == "" {
+		middleware.SetCorrelationID(watermill.NewUUID(), msg)
+	}
+	msg.Metadata.Set(utils.MetadataReplyTo, inbox)
+	msg.Metadata.Set("topic", topic)
+	msg.SetContext(ctx)
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if err := bus.Publish(topic, msg); err != nil {
+		return nil, fmt.Errorf("failed to publish request to %s: %w", topic, err)
+	}
+
+	reply, err := sub.NextMsgWithContext(ctx)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			if timeout > 0 {
+				return nil, fmt.Errorf("request to %s: %w after %s", topic, ErrRequestTimeout, timeout)
+			}
+			return nil, fmt.Errorf("request to %s: %w", topic, ErrRequestTimeout)
+		}
+		return nil, fmt.Errorf("request to %s: %w", topic, err)
+	}
+
+	return decodeReply[Resp](reply, o.failedTopic)
+}
+
+// decodeReply maps an inbox reply to either a typed response or a RequestFailedError,
+// classified by its reply_topic metadata alone.
+func decodeReply[Resp any](reply *nc.Msg, failedTopic string) (*Resp, error) {
+	if replyTopic := reply.Header.Get(utils.MetadataReplyTopic); replyTopic != "" && replyTopic == failedTopic {
+		failure := &RequestFailedError{Topic: replyTopic, Payload: reply.Data}
+		var fields struct {
+			Reason string `json:"reason"`
+			Error  string `json:"error"`
+		}
+		if err := json.Unmarshal(reply.Data, &fields); err == nil {
+			failure.Reason = fields.Reason
+			if failure.Reason == "" {
+				failure.Reason = fields.Error
+			}
+		}
+		return nil, failure
+	}
+
+	resp := new(Resp)
+	if err := json.Unmarshal(reply.Data, resp); err != nil {
+		return nil, fmt.Errorf("failed to decode reply: %w", err)
+	}
+	return resp, nil
+}
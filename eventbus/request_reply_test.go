@@ -0,0 +1,80 @@
+package eventbus
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/utils"
+	nc "github.com/nats-io/nats.go"
+)
+
+func TestFailedTopicFor(t *testing.T) {
+	tests := []struct {
+		topic string
+		want  string
+	}{
+		{topic: "user.get.requested.v1", want: "user.get.failed.v1"},
+		{topic: "leaderboard.tag.list.requested.v1", want: "leaderboard.tag.list.failed.v1"},
+		{topic: "club.info.request.v1", want: "club.info.failed.v1"},
+		{topic: "round.created.v1", want: ""},
+	}
+
+	for _, tc := range tests {
+		if got := FailedTopicFor(tc.topic); got != tc.want {
+			t.Errorf("FailedTopicFor(%q) = %q, want %q", tc.topic, got, tc.want)
+		}
+	}
+}
+
+type testUserResponse struct {
+	UserID string `json:"user_id"`
+}
+
+func TestDecodeReply_SuccessResponse(t *testing.T) {
+	reply := &nc.Msg{Header: nc.Header{}, Data: []byte(`{"user_id":"123"}`)}
+	reply.Header.Set(utils.MetadataReplyTopic, "user.get.response.v1")
+
+	resp, err := decodeReply[testUserResponse](reply, "user.get.failed.v1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.UserID != "123" {
+		t.Fatalf("expected user_id 123, got %q", resp.UserID)
+	}
+}
+
+func TestDecodeReply_FailureTopicReturnsTypedError(t *testing.T) {
+	reply := &nc.Msg{Header: nc.Header{}, Data: []byte(`{"user_id":"123","reason":"user not found"}`)}
+	reply.Header.Set(utils.MetadataReplyTopic, "user.get.failed.v1")
+
+	resp, err := decodeReply[testUserResponse](reply, "user.get.failed.v1")
+	if resp != nil {
+		t.Fatalf("expected nil response on failure, got %+v", resp)
+	}
+
+	var failed *RequestFailedError
+	if !errors.As(err, &failed) {
+		t.Fatalf("expected RequestFailedError, got %v", err)
+	}
+	if failed.Reason != "user not found" {
+		t.Fatalf("expected reason to be extracted, got %q", failed.Reason)
+	}
+
+	var decoded testUserResponse
+	if err := failed.Decode(&decoded); err != nil || decoded.UserID != "123" {
+		t.Fatalf("expected failure payload to decode, got %+v err=%v", decoded, err)
+	}
+}
+
+// Replies are classified by reply_topic alone, however their payload looks.
+func TestDecodeReply_WithoutReplyTopicDecodesAsResponse(t *testing.T) {
+	reply := &nc.Msg{Header: nc.Header{}, Data: []byte(`{"user_id":"123","reason":"user not found"}`)}
+
+	resp, err := decodeReply[testUserResponse](reply, "user.get.failed.v1")
+	if err != nil {
+		t.Fatalf("expected a reply without reply_topic to decode as a response, got %v", err)
+	}
+	if resp.UserID != "123" {
+		t.Fatalf("expected user_id 123, got %q", resp.UserID)
+	}
+}
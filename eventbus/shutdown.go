@@ -0,0 +1,134 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/observability/attr"
+	nc "github.com/nats-io/nats.go"
+)
+
+const (
+	// defaultShutdownTimeout bounds Close; it matches the NATS client drain timeout.
+	defaultShutdownTimeout = 30 * time.Second
+
+	// drainGracePeriod lets the connection flush pending acks and naks when Shutdown's
+	// deadline has already passed.
+	drainGracePeriod = 2 * time.Second
+)
+
+// ShutdownError reports what Shutdown could not finish cleanly.
+type ShutdownError struct {
+	// Abandoned lists messages whose handlers had not finished by the deadline.
+	// They were nacked and will be redelivered.
+	Abandoned []AbandonedMessage
+	DrainErr  error
+}
+
+func (e *ShutdownError) Error() string {
+	var parts []string
+	if n := len(e.Abandoned); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d in-flight messages abandoned", n))
+	}
+	if e.DrainErr != nil {
+		parts = append(parts, e.DrainErr.Error())
+	}
+	return "eventbus shutdown incomplete: " + strings.Join(parts, "; ")
+}
+
+func (e *ShutdownError) Unwrap() error {
+	return e.DrainErr
+}
+
+// Shutdown stops the EventBus gracefully, suitable for a Kubernetes preStop hook:
+// it stops fetching, waits for in-flight handlers until ctx is done, nacks whatever is
+// still in flight with a short delay so another replica picks it up, waits for pending
+// PublishAsync acks, and then drains the NATS connection. Abandoned messages are reported in a *ShutdownError.
+func (eb *eventBus) Shutdown(ctx context.Context) error {
+	ctxLogger := eb.logger.With(attr.String("operation", "shutdown"))
+	start := time.Now()
+
+	ctxLogger.Info("Shutting down EventBus")
+
+	// Stop republishing scheduled and spooled messages before tearing down the publisher.
+	eb.stopScheduledMessageProcessor()
+	eb.stopSpoolFlusher()
+
+	var abandoned []AbandonedMessage
+	if eb.subscriberAdapter != nil {
+		abandoned = eb.subscriberAdapter.Shutdown(ctx)
+		for _, m := range abandoned {
+			ctxLogger.Warn("Abandoned in-flight message",
+				attr.Topic(m.Topic),
+				attr.String("consumer", m.Consumer),
+				attr.String("message_id", m.MessageID),
+				attr.String("correlation_id", m.CorrelationID),
+			)
+		}
+	}
+
+	// Close legacy subscriber if present (for backward compatibility)
+	if eb.subscriber != nil {
+		if err := eb.subscriber.Close(); err != nil {
+			ctxLogger.Error("Error closing subscriber", "component", "subscriber", "error", err)
+		}
+	}
+
+	// Close publisher only when it owns a separate NATS connection.
+	if eb.publisher != nil && !eb.sharedPublisher {
+		if err := eb.publisher.Close(); err != nil {
+			ctxLogger.Error("Error closing publisher", "component", "publisher", "error", err)
+		}
+	}
+
+	eb.waitAsyncPublishes(ctx, ctxLogger)
+
+	drainErr := eb.drainConnection(ctx)
+	if drainErr != nil {
+		ctxLogger.Warn("NATS connection did not drain cleanly", attr.Error(drainErr))
+	}
+
+	ctxLogger.Info("EventBus shut down",
+		attr.Duration("duration", time.Since(start)),
+		attr.Int("abandoned_messages", len(abandoned)),
+	)
+
+	if len(abandoned) > 0 || drainErr != nil {
+		return &ShutdownError{Abandoned: abandoned, DrainErr: drainErr}
+	}
+	return nil
+}
+
+// drainConnection drains the NATS connection and waits for it to close. If ctx is
+// already done, the drain still gets drainGracePeriod to flush acks and naks.
+func (eb *eventBus) drainConnection(ctx context.Context) error {
+	if eb.natsConn == nil || eb.natsConn.IsClosed() {
+		return nil
+	}
+
+	if ctx.Err() != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.Background(), drainGracePeriod)
+		defer cancel()
+	}
+
+	closed := make(chan struct{})
+	var once sync.Once
+	eb.natsConn.SetClosedHandler(func(*nc.Conn) { once.Do(func() { close(closed) }) })
+
+	if err := eb.natsConn.Drain(); err != nil {
+		eb.natsConn.Close()
+		return fmt.Errorf("failed to drain NATS connection: %w", err)
+	}
+
+	select {
+	case <-closed:
+		return nil
+	case <-ctx.Done():
+		eb.natsConn.Close()
+		return fmt.Errorf("NATS drain did not finish: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,231 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+const (
+	defaultSpoolMaxMessages   = 10_000
+	defaultSpoolMaxBytes      = 64 << 20
+	defaultSpoolFlushInterval = time.Second
+
+	spoolFileExt = ".json"
+)
+
+// ErrSpoolFull is returned by Publish when JetStream is unavailable and the spool has no
+// room left for the message.
+var ErrSpoolFull = errors.New("publish spool is full")
+
+// SpoolConfig configures the on-disk spool that buffers publishes while JetStream is
+// unreachable. Zero limits use the defaults.
+type SpoolConfig struct {
+	// Dir holds one file per spooled message. It survives restarts, so messages spooled
+	// before a crash are flushed by the next process using the same directory.
+	Dir string
+
+	// MaxMessages and MaxBytes bound the spool; messages beyond them are rejected.
+	MaxMessages int
+	MaxBytes    int64
+
+	// FlushInterval is how often a non-empty spool is retried, in addition to flushing
+	// as soon as the NATS connection is re-established.
+	FlushInterval time.Duration
+}
+
+// spooledMessage is the on-disk form of a message waiting to be published. Metadata
+// includes Nats-Msg-Id, so replays are dropped by the stream's duplicate window.
+type spooledMessage struct {
+	Topic    string            `json:"topic"`
+	UUID     string            `json:"uuid"`
+	Metadata map[string]string `json:"metadata"`
+	Payload  []byte            `json:"payload"`
+}
+
+func (m spooledMessage) toMessage() *message.Message {
+	msg := message.NewMessage(m.UUID, m.Payload)
+	for k, v := range m.Metadata {
+		msg.Metadata.Set(k, v)
+	}
+	return msg
+}
+
+type spoolEntry struct {
+	seq  uint64
+	size int64
+}
+
+// diskSpool is a bounded FIFO of messages stored as sequence-numbered files.
+type diskSpool struct {
+	dir         string
+	maxMessages int
+	maxBytes    int64
+
+	mu      sync.Mutex
+	entries []spoolEntry
+	bytes   int64
+	nextSeq uint64
+}
+
+// openDiskSpool creates cfg.Dir if needed and loads any messages left by a previous run.
+func openDiskSpool(cfg SpoolConfig) (*diskSpool, error) {
+	if cfg.Dir == "" {
+		return nil, errors.New("spool directory is required")
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create spool directory: %w", err)
+	}
+
+	s := &diskSpool{
+		dir:         cfg.Dir,
+		maxMessages: cfg.MaxMessages,
+		maxBytes:    cfg.MaxBytes,
+		nextSeq:     1,
+	}
+	if s.maxMessages <= 0 {
+		s.maxMessages = defaultSpoolMaxMessages
+	}
+	if s.maxBytes <= 0 {
+		s.maxBytes = defaultSpoolMaxBytes
+	}
+
+	files, err := os.ReadDir(cfg.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spool directory: %w", err)
+	}
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasSuffix(name, spoolFileExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, spoolFileExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat spool file %s: %w", name, err)
+		}
+		s.entries = append(s.entries, spoolEntry{seq: seq, size: info.Size()})
+		s.bytes += info.Size()
+	}
+	sort.Slice(s.entries, func(i, j int) bool { return s.entries[i].seq < s.entries[j].seq })
+	if n := len(s.entries); n > 0 {
+		s.nextSeq = s.entries[n-1].seq + 1
+	}
+	return s, nil
+}
+
+func (s *diskSpool) path(seq uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d%s", seq, spoolFileExt))
+}
+
+// Len returns the number of spooled messages.
+func (s *diskSpool) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// append writes msg to the tail of the spool, or returns ErrSpoolFull.
+func (s *diskSpool) append(topic string, msg *message.Message) error {
+	data, err := json.Marshal(spooledMessage{
+		Topic:    topic,
+		UUID:     msg.UUID,
+		Metadata: msg.Metadata,
+		Payload:  msg.Payload,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode spooled message: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.entries) >= s.maxMessages || s.bytes+int64(len(data)) > s.maxBytes {
+		return ErrSpoolFull
+	}
+
+	seq := s.nextSeq
+	if err := writeFileSync(s.path(seq), data); err != nil {
+		return err
+	}
+	s.nextSeq++
+	s.entries = append(s.entries, spoolEntry{seq: seq, size: int64(len(data))})
+	s.bytes += int64(len(data))
+	return nil
+}
+
+// peek returns the oldest spooled message without removing it.
+func (s *diskSpool) peek() (spooledMessage, uint64, bool, error) {
+	s.mu.Lock()
+	if len(s.entries) == 0 {
+		s.mu.Unlock()
+		return spooledMessage{}, 0, false, nil
+	}
+	seq := s.entries[0].seq
+	s.mu.Unlock()
+
+	var m spooledMessage
+	data, err := os.ReadFile(s.path(seq))
+	if err == nil {
+		err = json.Unmarshal(data, &m)
+	}
+	if err != nil {
+		return spooledMessage{}, seq, true, fmt.Errorf("failed to read spooled message %d: %w", seq, err)
+	}
+	return m, seq, true, nil
+}
+
+// remove deletes the oldest message once it has been published or discarded.
+func (s *diskSpool) remove(seq uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.entries) == 0 || s.entries[0].seq != seq {
+		return nil
+	}
+	if err := os.Remove(s.path(seq)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove spooled message %d: %w", seq, err)
+	}
+	s.bytes -= s.entries[0].size
+	s.entries = s.entries[1:]
+	return nil
+}
+
+// writeFileSync writes data via a temporary file and rename so a crash never leaves a
+// partially written message in the spool.
+func writeFileSync(path string, data []byte) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to create spool file: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write spool file: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to sync spool file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to close spool file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to commit spool file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package eventbus
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Application types that own streams in the default topology.
+const (
+	AppTypeBackend = "backend"
+	AppTypeDiscord = "discord"
+)
+
+// StreamDefinition describes a JetStream stream: its subjects, limits and owning apps.
+// Only owning apps create the stream at startup; other apps subscribe to it.
+type StreamDefinition struct {
+	Name     string
+	Subjects []string
+	Owners   []string
+
+	Retention         jetstream.RetentionPolicy
+	Storage           jetstream.StorageType
+	Discard           jetstream.DiscardPolicy
+	Duplicates        time.Duration
+	MaxAge            time.Duration
+	MaxBytes          int64
+	MaxMsgs           int64
+	MaxMsgsPerSubject int64
+	MaxMsgSize        int32
+	MaxConsumers      int
+	Replicas          int
+}
+
+// NewStreamDefinition returns a definition with the production defaults used by all
+// domain streams: 24h retention, 100MB / 100k messages, one replica.
+// Callers adjust individual limits before registering it.
+func NewStreamDefinition(name, owner string, subjects ...string) StreamDefinition {
+	return StreamDefinition{
+		Name:         name,
+		Subjects:     subjects,
+		Owners:       []string{owner},
+		Retention:    jetstream.LimitsPolicy,
+		Storage:      jetstream.FileStorage, // Persistent storage
+		Discard:      jetstream.DiscardOld,  // Discard old messages when limits hit
+		Duplicates:   10 * time.Minute,      // Extended deduplication window
+		MaxAge:       24 * time.Hour,        // Keep messages for 24 hours
+		MaxBytes:     1024 * 1024 * 100,     // 100MB max stream size
+		MaxMsgs:      100000,                // Max 100k messages
+		MaxMsgSize:   1024 * 1024,           // 1MB max message size
+		MaxConsumers: 100,                   // Prevent unbounded consumer growth
+		Replicas:     1,                     // Single replica for now
+	}
+}
+
+// StreamConfig converts the definition to a JetStream stream configuration.
+func (d StreamDefinition) StreamConfig() jetstream.StreamConfig {
+	return jetstream.StreamConfig{
+		Name:              d.Name,
+		Subjects:          append([]string(nil), d.Subjects...),
+		Retention:         d.Retention,
+		Duplicates:        d.Duplicates,
+		MaxAge:            d.MaxAge,
+		MaxBytes:          d.MaxBytes,
+		MaxMsgs:           d.MaxMsgs,
+		MaxMsgsPerSubject: d.MaxMsgsPerSubject,
+		NoAck:             false, // Require acknowledgments
+		Discard:           d.Discard,
+		Storage:           d.Storage,
+		Replicas:          d.Replicas,
+		MaxConsumers:      d.MaxConsumers,
+		MaxMsgSize:        d.MaxMsgSize,
+	}
+}
+
+// OwnedBy reports whether appType creates this stream at startup.
+func (d StreamDefinition) OwnedBy(appType string) bool {
+	for _, owner := range d.Owners {
+		if owner == appType {
+			return true
+		}
+	}
+	return false
+}
+
+// StreamTopology is the set of streams known to the EventBus.
+// It drives stream creation, per-app provisioning and topic → stream resolution.
+type StreamTopology struct {
+	mu      sync.RWMutex
+	streams map[string]StreamDefinition
+	order   []string
+}
+
+// NewStreamTopology creates a topology from the given definitions. It fails if two
+// definitions claim overlapping subjects.
+func NewStreamTopology(defs ...StreamDefinition) (*StreamTopology, error) {
+	t := &StreamTopology{streams: make(map[string]StreamDefinition)}
+	for _, def := range defs {
+		if err := t.Register(def); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// DefaultStreamDefinitions returns the built-in frolf-bot streams.
+// Backend owns the domain streams; discord owns its internal stream.
+func DefaultStreamDefinitions() []StreamDefinition {
+	defs := make([]StreamDefinition, 0, 9)
+	for _, domain := range []string{"user", "leaderboard", "round", "score", "guild", "auth", "club"} {
+		defs = append(defs, NewStreamDefinition(domain, AppTypeBackend, domain+".>"))
+	}
+
+	// Discord creates its own internal stream.
+	// It will subscribe to backend streams (user, guild, auth, etc) which backend creates.
+	// We avoid creating shared streams here to prevent ambiguity and ensure Backend owns the domain streams.
+	defs = append(defs, NewStreamDefinition("discord", AppTypeDiscord, "discord.>"))
+
+	// Scheduled messages must outlive the default retention window, and
+	// rescheduling under the same key replaces the pending entry.
+	delayed := NewStreamDefinition(DelayedMessagesStream, AppTypeBackend, DelayedMessagesStream+".>")
+	delayed.MaxAge = scheduledMessagesMaxAge
+	delayed.MaxMsgsPerSubject = 1
+	defs = append(defs, delayed)
+
+	return defs
+}
+
+// The default definitions have disjoint subjects, which the topology tests check.
+var defaultStreamTopology = newDefaultStreamTopology()
+
+func newDefaultStreamTopology() *StreamTopology {
+	t := &StreamTopology{streams: make(map[string]StreamDefinition)}
+	for _, def := range DefaultStreamDefinitions() {
+		t.order = append(t.order, def.Name)
+		t.streams[def.Name] = def
+	}
+	return t
+}
+
+// DefaultStreamTopology returns the process-wide topology used when no topology is
+// configured explicitly. Deployments may Register additional or overriding streams
+// on it before creating the EventBus.
+func DefaultStreamTopology() *StreamTopology {
+	return defaultStreamTopology
+}
+
+// Register adds a stream definition, replacing any existing definition with the same name.
+// JetStream refuses streams with overlapping subjects, so Register returns an error when
+// a subject of def overlaps a subject of another registered stream.
+func (t *StreamTopology) Register(def StreamDefinition) error {
+	if def.Name == "" {
+		return fmt.Errorf("stream definition requires a name")
+	}
+	if len(def.Subjects) == 0 {
+		return fmt.Errorf("stream %s requires at least one subject", def.Name)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, name := range t.order {
+		if name == def.Name {
+			continue
+		}
+		for _, existing := range t.streams[name].Subjects {
+			for _, subject := range def.Subjects {
+				if subjectsOverlap(existing, subject) {
+					return fmt.Errorf("stream %s subject %q overlaps stream %s subject %q", def.Name, subject, name, existing)
+				}
+			}
+		}
+	}
+
+	if _, exists := t.streams[def.Name]; !exists {
+		t.order = append(t.order, def.Name)
+	}
+	t.streams[def.Name] = def
+	return nil
+}
+
+// Get returns the definition for a stream name.
+func (t *StreamTopology) Get(name string) (StreamDefinition, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	def, ok := t.streams[name]
+	return def, ok
+}
+
+// Streams returns all definitions in registration order.
+func (t *StreamTopology) Streams() []StreamDefinition {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	out := make([]StreamDefinition, 0, len(t.order))
+	for _, name := range t.order {
+		out = append(out, t.streams[name])
+	}
+	return out
+}
+
+// StreamsForApp returns the definitions owned by appType in registration order.
+func (t *StreamTopology) StreamsForApp(appType string) []StreamDefinition {
+	var out []StreamDefinition
+	for _, def := range t.Streams() {
+		if def.OwnedBy(appType) {
+			out = append(out, def)
+		}
+	}
+	return out
+}
+
+// ResolveStream returns the stream whose subjects match topic. Register keeps stream
+// subjects disjoint, so at most one stream matches.
+func (t *StreamTopology) ResolveStream(topic string) (string, error) {
+	for _, def := range t.Streams() {
+		for _, pattern := range def.Subjects {
+			if SubjectMatches(pattern, topic) {
+				return def.Name, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unknown topic prefix: %s", topic)
+}
+
+// ResolveStreamFromTopic determines the stream name from a topic using the default topology.
+// This centralizes stream resolution logic.
+func ResolveStreamFromTopic(topic string) (string, error) {
+	return defaultStreamTopology.ResolveStream(topic)
+}
+
+// SubjectMatches reports whether a NATS subject matches a pattern with * and > wildcards.
+func SubjectMatches(pattern, subject string) bool {
+	pTokens := strings.Split(pattern, ".")
+	sTokens := strings.Split(subject, ".")
+	for i, p := range pTokens {
+		if p == ">" {
+			return len(sTokens) > i
+		}
+		if i >= len(sTokens) {
+			return false
+		}
+		if p != "*" && p != sTokens[i] {
+			return false
+		}
+	}
+	return len(pTokens) == len(sTokens)
+}
+
+// subjectsOverlap reports whether some subject matches both patterns under SubjectMatches.
+func subjectsOverlap(a, b string) bool {
+	aTokens := strings.Split(a, ".")
+	bTokens := strings.Split(b, ".")
+	for i := 0; ; i++ {
+		switch {
+		case i == len(aTokens) || i == len(bTokens):
+			return len(aTokens) == len(bTokens)
+		case aTokens[i] == ">" || bTokens[i] == ">":
+			// > needs at least one token, which the other pattern has from here on.
+			return true
+		case aTokens[i] != "*" && bTokens[i] != "*" && aTokens[i] != bTokens[i]:
+			return false
+		}
+	}
+}
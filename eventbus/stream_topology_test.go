@@ -0,0 +1,152 @@
+package eventbus
+
+import "testing"
+
+func mustDefaultTopology(t *testing.T) *StreamTopology {
+	t.Helper()
+	topology, err := NewStreamTopology(DefaultStreamDefinitions()...)
+	if err != nil {
+		t.Fatalf("unexpected topology error: %v", err)
+	}
+	return topology
+}
+
+func TestStreamTopology_ResolveStream(t *testing.T) {
+	topology := mustDefaultTopology(t)
+	if err := topology.Register(NewStreamDefinition("tournament", AppTypeBackend, "tournament.>")); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	tests := []struct {
+		topic   string
+		want    string
+		wantErr bool
+	}{
+		{topic: "user.created.v1", want: "user"},
+		{topic: "discord.round.created.v1", want: "discord"},
+		{topic: "delayed.messages.v1.abc", want: DelayedMessagesStream},
+		{topic: "tournament.bracket.updated.v1", want: "tournament"},
+		{topic: "round.created.v1", want: "round"},
+		{topic: "unknown.topic.v1", wantErr: true},
+		{topic: "round", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		got, err := topology.ResolveStream(tc.topic)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("ResolveStream(%q) expected error, got %q", tc.topic, got)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Errorf("ResolveStream(%q) = %q, %v; want %q", tc.topic, got, err, tc.want)
+		}
+	}
+}
+
+func TestStreamTopology_StreamsForApp(t *testing.T) {
+	topology := mustDefaultTopology(t)
+
+	discord := topology.StreamsForApp(AppTypeDiscord)
+	if len(discord) != 1 || discord[0].Name != "discord" {
+		t.Fatalf("expected discord to own only the discord stream, got %+v", discord)
+	}
+
+	backend := topology.StreamsForApp(AppTypeBackend)
+	if len(backend) != 8 {
+		t.Fatalf("expected 8 backend streams, got %d", len(backend))
+	}
+	if len(topology.StreamsForApp("unknown")) != 0 {
+		t.Fatal("expected no streams for unknown app type")
+	}
+}
+
+func TestStreamTopology_RegisterReplacesDefinition(t *testing.T) {
+	topology := mustDefaultTopology(t)
+
+	round := NewStreamDefinition("round", AppTypeBackend, "round.>")
+	round.Replicas = 3
+	if err := topology.Register(round); err != nil {
+		t.Fatalf("expected replacing a stream not to conflict with itself, got %v", err)
+	}
+
+	def, ok := topology.Get("round")
+	if !ok || def.Replicas != 3 {
+		t.Fatalf("expected replaced round definition, got %+v", def)
+	}
+	if got := len(topology.Streams()); got != len(DefaultStreamDefinitions()) {
+		t.Fatalf("expected replacement to keep stream count, got %d", got)
+	}
+}
+
+func TestStreamTopology_RegisterRejectsOverlappingSubjects(t *testing.T) {
+	topology := mustDefaultTopology(t)
+
+	for _, subject := range []string{"round.score.>", "round.*", "*.created.v1", ">"} {
+		if err := topology.Register(NewStreamDefinition("overlapping", AppTypeBackend, subject)); err == nil {
+			t.Errorf("expected %q to be rejected for overlapping an existing stream", subject)
+		}
+	}
+	if _, ok := topology.Get("overlapping"); ok {
+		t.Fatal("expected rejected definitions not to be registered")
+	}
+
+	if _, err := NewStreamTopology(
+		NewStreamDefinition("round", AppTypeBackend, "round.>"),
+		NewStreamDefinition("round-scores", AppTypeBackend, "round.score.>"),
+	); err == nil {
+		t.Fatal("expected NewStreamTopology to reject overlapping definitions")
+	}
+}
+
+func TestDefaultStreamTopologyMatchesDefinitions(t *testing.T) {
+	topology := mustDefaultTopology(t)
+	if got, want := len(DefaultStreamTopology().Streams()), len(topology.Streams()); got != want {
+		t.Fatalf("expected default topology to hold %d streams, got %d", want, got)
+	}
+}
+
+func TestSubjectsOverlap(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "round.>", b: "round.score.>", want: true},
+		{a: "round.*.v1", b: "round.created.*", want: true},
+		{a: "round.>", b: "round", want: false},
+		{a: "round.>", b: "user.>", want: false},
+		{a: "round.*", b: "round.created.v1", want: false},
+		{a: "round.created.v1", b: "round.created.v1", want: true},
+	}
+
+	for _, tc := range tests {
+		if got := subjectsOverlap(tc.a, tc.b); got != tc.want {
+			t.Errorf("subjectsOverlap(%q, %q) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+		if got := subjectsOverlap(tc.b, tc.a); got != tc.want {
+			t.Errorf("subjectsOverlap(%q, %q) = %v, want %v", tc.b, tc.a, got, tc.want)
+		}
+	}
+}
+
+func TestSubjectMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		subject string
+		want    bool
+	}{
+		{pattern: "round.>", subject: "round.created.v1", want: true},
+		{pattern: "round.>", subject: "round", want: false},
+		{pattern: "round.*.v1", subject: "round.created.v1", want: true},
+		{pattern: "round.*.v1", subject: "round.created.v2", want: false},
+		{pattern: "round.created.v1", subject: "round.created.v1", want: true},
+		{pattern: "round.created", subject: "round.created.v1", want: false},
+	}
+
+	for _, tc := range tests {
+		if got := SubjectMatches(tc.pattern, tc.subject); got != tc.want {
+			t.Errorf("SubjectMatches(%q, %q) = %v, want %v", tc.pattern, tc.subject, got, tc.want)
+		}
+	}
+}
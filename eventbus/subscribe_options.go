@@ -0,0 +1,94 @@
+package eventbus
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// OrderingMode controls how a subscription orders the messages it hands to handlers.
+type OrderingMode int
+
+const (
+	// OrderingNone hands messages to handlers as soon as a concurrency slot is free.
+	OrderingNone OrderingMode = iota
+
+	// OrderingPartitioned serializes messages that share a partition key while handling
+	// different keys in parallel. A message is not emitted until the previous message
+	// with the same key has been acked or nacked.
+	OrderingPartitioned
+)
+
+// String returns the mode name used in logs.
+func (m OrderingMode) String() string {
+	switch m {
+	case OrderingNone:
+		return "none"
+	case OrderingPartitioned:
+		return "partitioned"
+	default:
+		return fmt.Sprintf("OrderingMode(%d)", int(m))
+	}
+}
+
+// SubscribeOptions tunes how a subscription pulls and dispatches messages.
+// Zero fields fall back to the subscriber defaults.
+//
+// Ordering is per subscription: replicas sharing a durable consumer each order only the
+// messages they fetch, and a nacked message is redelivered after its backoff, possibly
+// behind later messages with the same key.
+type SubscribeOptions struct {
+	// BatchSize is the number of messages requested in each pull.
+	BatchSize int
+
+	// PullExpiry is how long each pull waits for messages before it is reissued.
+	PullExpiry time.Duration
+
+	// Concurrency is the maximum number of messages fetched and not yet settled.
+	// Set it to 1 to process the subscription strictly in order.
+	Concurrency int
+
+	// Ordering selects unordered or partitioned dispatch.
+	Ordering OrderingMode
+
+	// PartitionKey is the metadata key (e.g. "round_id" or "guild_id") that messages are
+	// serialized by in OrderingPartitioned mode. Messages without the key are unordered.
+	PartitionKey string
+}
+
+// Validate reports options that cannot be applied.
+func (o SubscribeOptions) Validate() error {
+	if o.BatchSize < 0 {
+		return fmt.Errorf("batch size must not be negative, got %d", o.BatchSize)
+	}
+	if o.PullExpiry < 0 {
+		return fmt.Errorf("pull expiry must not be negative, got %s", o.PullExpiry)
+	}
+	if o.Concurrency < 0 {
+		return fmt.Errorf("concurrency must not be negative, got %d", o.Concurrency)
+	}
+	switch o.Ordering {
+	case OrderingNone:
+	case OrderingPartitioned:
+		if o.PartitionKey == "" {
+			return errors.New("partitioned ordering requires a partition key")
+		}
+	default:
+		return fmt.Errorf("unknown ordering mode %s", o.Ordering)
+	}
+	return nil
+}
+
+// withDefaults fills zero fields, using maxConcurrency as the default concurrency.
+func (o SubscribeOptions) withDefaults(maxConcurrency int) SubscribeOptions {
+	if o.BatchSize == 0 {
+		o.BatchSize = pullBatchSize
+	}
+	if o.PullExpiry == 0 {
+		o.PullExpiry = pullExpiry
+	}
+	if o.Concurrency == 0 {
+		o.Concurrency = maxConcurrency
+	}
+	return o
+}
@@ -0,0 +1,73 @@
+package eventbus
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSubscribeOptions_Validate(t *testing.T) {
+	cases := []struct {
+		name    string
+		opts    SubscribeOptions
+		wantErr bool
+	}{
+		{name: "zero value", opts: SubscribeOptions{}},
+		{name: "partitioned", opts: SubscribeOptions{Ordering: OrderingPartitioned, PartitionKey: "round_id"}},
+		{name: "partitioned without key", opts: SubscribeOptions{Ordering: OrderingPartitioned}, wantErr: true},
+		{name: "negative batch", opts: SubscribeOptions{BatchSize: -1}, wantErr: true},
+		{name: "negative expiry", opts: SubscribeOptions{PullExpiry: -time.Second}, wantErr: true},
+		{name: "negative concurrency", opts: SubscribeOptions{Concurrency: -1}, wantErr: true},
+		{name: "unknown ordering", opts: SubscribeOptions{Ordering: OrderingMode(7)}, wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.opts.Validate()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestSubscribeOptions_WithDefaults(t *testing.T) {
+	got := SubscribeOptions{}.withDefaults(50)
+	if got.BatchSize != pullBatchSize || got.PullExpiry != pullExpiry || got.Concurrency != 50 {
+		t.Fatalf("unexpected defaults %+v", got)
+	}
+
+	custom := SubscribeOptions{BatchSize: 1, PullExpiry: time.Second, Concurrency: 4}
+	if got := custom.withDefaults(50); got != custom {
+		t.Fatalf("expected explicit options to be kept, got %+v", got)
+	}
+}
+
+func TestPartitions_QueuePerKey(t *testing.T) {
+	p := newPartitions()
+	first, second, other := &delivery{}, &delivery{}, &delivery{}
+
+	if !p.enqueue("r1", first) {
+		t.Fatal("expected idle key to need a worker")
+	}
+	if p.enqueue("r1", second) {
+		t.Fatal("expected active key to reuse its worker")
+	}
+	if !p.enqueue("r2", other) {
+		t.Fatal("expected a separate worker per key")
+	}
+
+	if d, ok := p.next("r1"); !ok || d != first {
+		t.Fatal("expected first delivery for r1")
+	}
+	if waiting := p.waiting("r1"); len(waiting) != 1 || waiting[0] != second {
+		t.Fatalf("expected second delivery to be waiting, got %v", waiting)
+	}
+	if d, ok := p.next("r1"); !ok || d != second {
+		t.Fatal("expected second delivery for r1")
+	}
+	if _, ok := p.next("r1"); ok {
+		t.Fatal("expected r1 to be drained")
+	}
+	if !p.enqueue("r1", first) {
+		t.Fatal("expected drained key to need a new worker")
+	}
+}
@@ -0,0 +1,126 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrSubscriptionNotFound is returned when pausing or resuming a topic with no active
+// subscription.
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
+// pauseGate holds a subscription's fetch loop while it is paused.
+type pauseGate struct {
+	mu sync.Mutex
+	// resumed is non-nil while paused and is closed on resume.
+	resumed chan struct{}
+}
+
+// pause reports whether the gate changed state.
+func (g *pauseGate) pause() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.resumed != nil {
+		return false
+	}
+	g.resumed = make(chan struct{})
+	return true
+}
+
+// resume reports whether the gate changed state.
+func (g *pauseGate) resume() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.resumed == nil {
+		return false
+	}
+	close(g.resumed)
+	g.resumed = nil
+	return true
+}
+
+// wait blocks while the gate is paused. It returns false if ctx ends first.
+func (g *pauseGate) wait(ctx context.Context) bool {
+	g.mu.Lock()
+	resumed := g.resumed
+	g.mu.Unlock()
+	if resumed == nil {
+		return true
+	}
+
+	select {
+	case <-resumed:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// PauseSubscription stops fetching for every subscription to topic. Messages already
+// fetched are still handled, and a pull in progress finishes first.
+func (s *JetStreamSubscriberAdapter) PauseSubscription(topic string) error {
+	return s.setPaused(topic, true)
+}
+
+// ResumeSubscription restarts fetching for every subscription to topic.
+func (s *JetStreamSubscriberAdapter) ResumeSubscription(topic string) error {
+	return s.setPaused(topic, false)
+}
+
+func (s *JetStreamSubscriberAdapter) setPaused(topic string, paused bool) error {
+	s.mu.Lock()
+	var subs []*subscription
+	for _, sub := range s.subscriptions {
+		if sub.topic == topic {
+			subs = append(subs, sub)
+		}
+	}
+	s.mu.Unlock()
+
+	if len(subs) == 0 {
+		return fmt.Errorf("%w for topic %s", ErrSubscriptionNotFound, topic)
+	}
+
+	for _, sub := range subs {
+		changed, state := false, "resumed"
+		if paused {
+			changed, state = sub.gate.pause(), "paused"
+		} else {
+			changed = sub.gate.resume()
+		}
+		if changed {
+			s.logger.Info("Subscription "+state,
+				"operation", "subscription_control",
+				"topic", topic,
+				"consumer", sub.consumerName,
+				"app_type", s.appType,
+			)
+		}
+	}
+	return nil
+}
+
+// PauseSubscription stops fetching new messages for topic without unsubscribing.
+func (eb *eventBus) PauseSubscription(topic string) error {
+	return eb.subscriberAdapter.PauseSubscription(topic)
+}
+
+// ResumeSubscription restarts fetching for a topic paused with PauseSubscription.
+func (eb *eventBus) ResumeSubscription(topic string) error {
+	return eb.subscriberAdapter.ResumeSubscription(topic)
+}
+
+// UpdateConsumerConfig sets cfg as the topic's consumer configuration and applies its
+// AckWait, BackOff and MaxAckPending to the durable consumer in place. The registry
+// keeps cfg as the desired state even if the update fails, so retrying applies it.
+func (eb *eventBus) UpdateConsumerConfig(ctx context.Context, topic string, cfg ConsumerConfig) error {
+	streamName, err := eb.streamTopology().ResolveStream(topic)
+	if err != nil {
+		return err
+	}
+
+	eb.consumerManager.GetRegistry().SetForTopic(topic, cfg)
+	return eb.consumerManager.ApplyConsumerConfig(ctx, streamName, topic, eb.appType)
+}
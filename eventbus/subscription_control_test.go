@@ -0,0 +1,40 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPauseGate_WaitBlocksUntilResumed(t *testing.T) {
+	var gate pauseGate
+	if !gate.wait(context.Background()) {
+		t.Fatal("expected an unpaused gate not to block")
+	}
+
+	if !gate.pause() || gate.pause() {
+		t.Fatal("expected only the first pause to change state")
+	}
+
+	done := make(chan bool)
+	go func() { done <- gate.wait(context.Background()) }()
+	select {
+	case <-done:
+		t.Fatal("expected wait to block while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if !gate.resume() || gate.resume() {
+		t.Fatal("expected only the first resume to change state")
+	}
+	if !<-done {
+		t.Fatal("expected wait to return true after resume")
+	}
+
+	gate.pause()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if gate.wait(ctx) {
+		t.Fatal("expected wait to return false when ctx ends")
+	}
+}
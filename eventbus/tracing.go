@@ -0,0 +1,125 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+
+	tracingfrolfbot "github.com/Black-And-White-Club/frolf-bot-shared/observability/otel/tracing"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startPublishSpans opens a producer span per message and injects its context into the
+// message metadata through the global propagator, so consumers continue the trace.
+// The returned func ends the spans with the publish outcome.
+func (eb *eventBus) startPublishSpans(topic string, messages []*message.Message) func(error) {
+	if eb.tracer == nil {
+		return func(error) {}
+	}
+
+	propagator := otel.GetTextMapPropagator()
+	spans := make([]trace.Span, 0, len(messages))
+	for _, msg := range messages {
+		ctx := msg.Context()
+		if !trace.SpanContextFromContext(ctx).IsValid() {
+			// Messages built without a traced context continue the trace they carry, if any.
+			ctx = propagator.Extract(ctx, propagation.MapCarrier(msg.Metadata))
+		}
+
+		ctx, span := eb.tracer.Start(ctx, topic+" publish",
+			trace.WithSpanKind(trace.SpanKindProducer),
+			trace.WithAttributes(
+				semconv.MessagingSystemKey.String(tracingfrolfbot.MessagingSystemJetStream),
+				semconv.MessagingOperationTypePublish,
+				semconv.MessagingDestinationName(topic),
+				semconv.MessagingMessageID(msg.UUID),
+				semconv.MessagingMessageBodySize(len(msg.Payload)),
+				attribute.String(tracingfrolfbot.AttrCorrelationID, msg.Metadata.Get("correlation_id")),
+			),
+		)
+		propagator.Inject(ctx, propagation.MapCarrier(msg.Metadata))
+		spans = append(spans, span)
+	}
+
+	return func(err error) {
+		for _, span := range spans {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}
+	}
+}
+
+// startConsumerSpan opens a consumer span for a delivered message, parented on and
+// linked to the producer span carried in its headers. The span context replaces the
+// propagation metadata on wmMsg, so TraceHandler nests the process span beneath it.
+func (s *JetStreamSubscriberAdapter) startConsumerSpan(jsMsg jetstream.Msg, wmMsg *message.Message) trace.Span {
+	if s.tracer == nil {
+		return trace.SpanFromContext(context.Background())
+	}
+
+	propagator := otel.GetTextMapPropagator()
+	parent := propagator.Extract(wmMsg.Context(), propagation.MapCarrier(wmMsg.Metadata))
+
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String(tracingfrolfbot.MessagingSystemJetStream),
+			semconv.MessagingOperationTypeReceive,
+			semconv.MessagingDestinationName(jsMsg.Subject()),
+			semconv.MessagingMessageID(wmMsg.UUID),
+			semconv.MessagingMessageBodySize(len(wmMsg.Payload)),
+			attribute.String(tracingfrolfbot.AttrCorrelationID, wmMsg.Metadata.Get("correlation_id")),
+		),
+	}
+	if meta, err := jsMsg.Metadata(); err == nil {
+		opts = append(opts, trace.WithAttributes(
+			attribute.String(tracingfrolfbot.AttrStream, meta.Stream),
+			attribute.String(tracingfrolfbot.AttrConsumerName, meta.Consumer),
+			attribute.Int64(tracingfrolfbot.AttrStreamSequence, int64(meta.Sequence.Stream)),
+			attribute.Int64(tracingfrolfbot.AttrRetryCount, int64(meta.NumDelivered)),
+		))
+	}
+	if producer := trace.SpanContextFromContext(parent); producer.IsValid() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: producer}))
+	}
+
+	ctx, span := s.tracer.Start(parent, jsMsg.Subject()+" receive", opts...)
+	propagator.Inject(ctx, propagation.MapCarrier(wmMsg.Metadata))
+	wmMsg.SetContext(ctx)
+	return span
+}
+
+// Settlement outcomes recorded on consumer spans.
+const (
+	settlementAck     = "ack"
+	settlementNak     = "nak"
+	settlementTerm    = "term"
+	settlementTimeout = "timeout"
+)
+
+// nackError describes why a message was nacked, for the consumer span.
+func nackError(msg *message.Message) error {
+	if lastErr := msg.Metadata.Get(LastErrorMetadataKey); lastErr != "" {
+		return errors.New(lastErr)
+	}
+	return errors.New("message nacked")
+}
+
+// endConsumerSpan records how a delivered message was settled.
+func endConsumerSpan(span trace.Span, outcome string, err error) {
+	span.SetAttributes(attribute.String("messaging.settlement", outcome))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,128 @@
+package eventbus
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/ThreeDotsLabs/watermill-nats/v2/pkg/nats"
+	"github.com/ThreeDotsLabs/watermill/message"
+	nc "github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestTracer(t *testing.T) (trace.Tracer, *tracetest.SpanRecorder) {
+	t.Helper()
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prev) })
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	return provider.Tracer("eventbus-test"), recorder
+}
+
+func TestPublish_StartsProducerSpanAndInjectsTraceparent(t *testing.T) {
+	tracer, recorder := newTestTracer(t)
+
+	fp := &fakePublisher{}
+	eb := &eventBus{
+		appType:   "backend",
+		publisher: fp,
+		logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		marshaler: &nats.NATSMarshaler{},
+		tracer:    tracer,
+	}
+
+	parentCtx, parent := tracer.Start(context.Background(), "handler")
+	msg := message.NewMessage("id", []byte(`{}`))
+	msg.SetContext(parentCtx)
+	if err := eb.Publish("round.created.v1", msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected producer and parent spans, got %d", len(spans))
+	}
+	producer := spans[0]
+	if producer.Name() != "round.created.v1 publish" || producer.SpanKind() != trace.SpanKindProducer {
+		t.Fatalf("unexpected producer span %s (%s)", producer.Name(), producer.SpanKind())
+	}
+	if producer.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Fatal("expected producer span to be a child of the message context span")
+	}
+
+	published := fp.calls[0].msgs[0]
+	carried := otel.GetTextMapPropagator().Extract(context.Background(), propagation.MapCarrier(published.Metadata))
+	if trace.SpanContextFromContext(carried).SpanID() != producer.SpanContext().SpanID() {
+		t.Fatalf("expected traceparent of the producer span, got %q", published.Metadata.Get("traceparent"))
+	}
+}
+
+func TestStartConsumerSpan_ContinuesProducerTrace(t *testing.T) {
+	tracer, recorder := newTestTracer(t)
+
+	_, producer := tracer.Start(context.Background(), "round.created.v1 publish")
+	producer.End()
+	// NATS headers are case-sensitive; the publisher writes lowercase propagation keys.
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(trace.ContextWithSpan(context.Background(), producer), carrier)
+	hdr := nc.Header{}
+	for k, v := range carrier {
+		hdr[k] = []string{v}
+	}
+
+	jsMsg := &fakeJSMsg{
+		subject: "round.created.v1",
+		headers: hdr,
+		data:    []byte(`{}`),
+		meta: &jetstream.MsgMetadata{
+			Stream:       "round",
+			Consumer:     "backend-round-created-v1",
+			NumDelivered: 2,
+			Sequence:     jetstream.SequencePair{Stream: 42},
+		},
+	}
+
+	s := &JetStreamSubscriberAdapter{tracer: tracer}
+	wmMsg, err := s.toWatermillMessage(context.Background(), jsMsg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	span := s.startConsumerSpan(jsMsg, wmMsg)
+	endConsumerSpan(span, settlementAck, nil)
+
+	consumer := recorder.Ended()[1]
+	if consumer.SpanKind() != trace.SpanKindConsumer || consumer.Parent().SpanID() != producer.SpanContext().SpanID() {
+		t.Fatalf("expected consumer span parented on the producer, got %s", consumer.Parent().SpanID())
+	}
+	if len(consumer.Links()) != 1 || consumer.Links()[0].SpanContext.SpanID() != producer.SpanContext().SpanID() {
+		t.Fatalf("expected a link to the producer span, got %v", consumer.Links())
+	}
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range consumer.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	if attrs["messaging.nats.stream.sequence"].AsInt64() != 42 || attrs["messaging.delivery_attempt"].AsInt64() != 2 {
+		t.Fatalf("unexpected delivery attributes %v", attrs)
+	}
+
+	// The handler sees the consumer span, both in its context and in the propagation metadata.
+	if trace.SpanContextFromContext(wmMsg.Context()).SpanID() != consumer.SpanContext().SpanID() {
+		t.Fatal("expected message context to carry the consumer span")
+	}
+	carried := otel.GetTextMapPropagator().Extract(context.Background(), propagation.MapCarrier(wmMsg.Metadata))
+	if trace.SpanContextFromContext(carried).SpanID() != consumer.SpanContext().SpanID() {
+		t.Fatal("expected traceparent metadata to point at the consumer span")
+	}
+}
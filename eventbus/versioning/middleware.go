@@ -0,0 +1,53 @@
+package versioning
+
+import (
+	"log/slog"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/observability/attr"
+	"github.com/Black-And-White-Club/frolf-bot-shared/utils"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+// UpcastMiddleware rewrites messages from older subjects into the newest registered
+// version before the handler runs. The version is read from the subject the message was
+// delivered on, falling back to its topic metadata. The original subject is kept in
+// upcast_from metadata and the subject and topic metadata are set to the new subject.
+// A failed upcast is returned as a handler error so the message is retried or dead-lettered.
+func UpcastMiddleware(registry *Registry, logger *slog.Logger) message.HandlerMiddleware {
+	return func(next message.HandlerFunc) message.HandlerFunc {
+		return func(msg *message.Message) ([]*message.Message, error) {
+			topic := msg.Metadata.Get(utils.MetadataSubject)
+			if topic == "" {
+				topic = msg.Metadata.Get("topic")
+			}
+			if topic == "" {
+				return next(msg)
+			}
+
+			upcastTopic, payload, err := registry.Upcast(topic, msg.Payload)
+			if err != nil {
+				logger.ErrorContext(msg.Context(), "Failed to upcast message",
+					attr.Topic(topic),
+					attr.String("message_id", msg.UUID),
+					attr.Error(err),
+				)
+				return nil, err
+			}
+
+			if upcastTopic != topic {
+				msg.Payload = payload
+				msg.Metadata.Set(UpcastFromMetadataKey, topic)
+				msg.Metadata.Set("topic", upcastTopic)
+				if msg.Metadata.Get(utils.MetadataSubject) != "" {
+					msg.Metadata.Set(utils.MetadataSubject, upcastTopic)
+				}
+				logger.DebugContext(msg.Context(), "Message upcast",
+					attr.String("from", topic),
+					attr.String("to", upcastTopic),
+					attr.String("message_id", msg.UUID),
+				)
+			}
+			return next(msg)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+// Package versioning evolves event payloads across subject versions (e.g. round.created.v1
+// to round.created.v2) without coordinated deploys.
+//
+// Consumers register upcasters and subscribe to both versions; UpcastMiddleware rewrites
+// older messages into the newest shape before the handler sees them:
+//
+//	reg := versioning.NewRegistry()
+//	reg.RegisterUpcaster(roundevents.RoundCreatedV1, roundevents.RoundCreatedV2,
+//	    versioning.Typed(func(v1 *roundevents.RoundCreatedPayloadV1) (*roundevents.RoundCreatedPayloadV2, error) {
+//	        return &roundevents.RoundCreatedPayloadV2{...}, nil
+//	    }))
+//	router.AddMiddleware(versioning.UpcastMiddleware(reg, logger))
+//
+// Producers that already publish the new version register a downcaster with a migration
+// window and pass the registry to eventbus.WithDualPublish, so consumers that still read
+// the old subject keep receiving it until the window closes.
+package versioning
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/google/uuid"
+)
+
+// Metadata keys recording a version conversion.
+const (
+	UpcastFromMetadataKey   = "upcast_from"
+	DowncastFromMetadataKey = "downcast_from"
+)
+
+// maxUpcastChain bounds upcaster chains so a registration cycle cannot loop forever.
+const maxUpcastChain = 16
+
+// Transform converts a JSON payload from one version to another.
+type Transform func(payload []byte) ([]byte, error)
+
+// Typed adapts a typed conversion function into a Transform using JSON encoding.
+func Typed[From, To any](convert func(*From) (*To, error)) Transform {
+	return func(payload []byte) ([]byte, error) {
+		from := new(From)
+		if err := json.Unmarshal(payload, from); err != nil {
+			return nil, fmt.Errorf("failed to decode %T: %w", from, err)
+		}
+		to, err := convert(from)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(to)
+	}
+}
+
+type conversion struct {
+	to        string
+	transform Transform
+	// until closes a downcast migration window; zero means open-ended.
+	until time.Time
+}
+
+// Registry holds upcasters (old → new) and downcasters (new → old) keyed by subject.
+type Registry struct {
+	mu          sync.RWMutex
+	upcasters   map[string]conversion
+	downcasters map[string][]conversion
+	now         func() time.Time
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		upcasters:   make(map[string]conversion),
+		downcasters: make(map[string][]conversion),
+		now:         time.Now,
+	}
+}
+
+// RegisterUpcaster converts messages on fromTopic into toTopic's payload. Upcasters chain,
+// so v1 → v2 and v2 → v3 upcast a v1 message to v3.
+func (r *Registry) RegisterUpcaster(fromTopic, toTopic string, transform Transform) error {
+	if fromTopic == "" || toTopic == "" || fromTopic == toTopic {
+		return fmt.Errorf("invalid upcaster %q -> %q", fromTopic, toTopic)
+	}
+	if transform == nil {
+		return errors.New("upcaster transform is required")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.upcasters[fromTopic]; ok {
+		return fmt.Errorf("upcaster from %s already registered (to %s)", fromTopic, existing.to)
+	}
+	r.upcasters[fromTopic] = conversion{to: toTopic, transform: transform}
+	return nil
+}
+
+// RegisterDowncaster dual-publishes messages on newTopic to oldTopic until the given
+// time. A zero until keeps dual-publishing until the downcaster is removed from code.
+// Downcasters chain when their copies are published, so a registration that would close
+// a cycle (v2 → v1 with v1 → v2 registered) is rejected.
+func (r *Registry) RegisterDowncaster(newTopic, oldTopic string, transform Transform, until time.Time) error {
+	if newTopic == "" || oldTopic == "" || newTopic == oldTopic {
+		return fmt.Errorf("invalid downcaster %q -> %q", newTopic, oldTopic)
+	}
+	if transform == nil {
+		return errors.New("downcaster transform is required")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.downcastsReach(oldTopic, newTopic) {
+		return fmt.Errorf("downcaster %s -> %s would form a cycle", newTopic, oldTopic)
+	}
+	r.downcasters[newTopic] = append(r.downcasters[newTopic], conversion{to: oldTopic, transform: transform, until: until})
+	return nil
+}
+
+// downcastsReach reports whether a message on from is downcast, directly or through a
+// chain, to target. The caller holds r.mu.
+func (r *Registry) downcastsReach(from, target string) bool {
+	seen := map[string]bool{from: true}
+	queue := []string{from}
+	for len(queue) > 0 {
+		topic := queue[0]
+		queue = queue[1:]
+		if topic == target {
+			return true
+		}
+		for _, conv := range r.downcasters[topic] {
+			if !seen[conv.to] {
+				seen[conv.to] = true
+				queue = append(queue, conv.to)
+			}
+		}
+	}
+	return false
+}
+
+// Upcast converts payload on topic to the newest registered version. It returns the
+// final topic and payload; both are unchanged when no upcaster applies.
+func (r *Registry) Upcast(topic string, payload []byte) (string, []byte, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := 0; i < maxUpcastChain; i++ {
+		conv, ok := r.upcasters[topic]
+		if !ok {
+			return topic, payload, nil
+		}
+		next, err := conv.transform(payload)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to upcast %s to %s: %w", topic, conv.to, err)
+		}
+		topic, payload = conv.to, next
+	}
+	return "", nil, fmt.Errorf("upcaster chain from %s exceeds %d steps", topic, maxUpcastChain)
+}
+
+// Downcasts builds the old-version copies of msg to publish alongside it on topic.
+// Expired migration windows are skipped. Each copy's Nats-Msg-Id and UUID derive from
+// msg's Nats-Msg-Id (or its UUID) and the old subject, so a retried publish of msg
+// produces copies JetStream deduplicates.
+func (r *Registry) Downcasts(topic string, msg *message.Message) (map[string]*message.Message, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	convs := r.downcasters[topic]
+	if len(convs) == 0 {
+		return nil, nil
+	}
+
+	now := r.now()
+	out := make(map[string]*message.Message, len(convs))
+	for _, conv := range convs {
+		if !conv.until.IsZero() && !now.Before(conv.until) {
+			continue
+		}
+		payload, err := conv.transform(msg.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to downcast %s to %s: %w", topic, conv.to, err)
+		}
+
+		msgID := DowncastMsgID(msg, conv.to)
+		old := message.NewMessage(uuid.NewSHA1(uuid.NameSpaceOID, []byte(msgID)).String(), payload)
+		for k, v := range msg.Metadata {
+			old.Metadata.Set(k, v)
+		}
+		old.Metadata.Set("Nats-Msg-Id", msgID)
+		old.Metadata.Set("topic", conv.to)
+		old.Metadata.Set(DowncastFromMetadataKey, topic)
+		old.SetContext(msg.Context())
+		out[conv.to] = old
+	}
+	return out, nil
+}
+
+// DowncastMsgID derives the Nats-Msg-Id of msg's downcast copy on topic.
+func DowncastMsgID(msg *message.Message, topic string) string {
+	id := msg.Metadata.Get("Nats-Msg-Id")
+	if id == "" {
+		id = msg.UUID
+	}
+	// Include topic so copies on different old subjects do not collide.
+	sum := sha256.Sum256([]byte(topic + "|" + id))
+	return "downcast-" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,170 @@
+package versioning
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/utils"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+type roundCreatedV1 struct {
+	Title string `json:"title"`
+}
+
+type roundCreatedV2 struct {
+	Name string `json:"name"`
+}
+
+type roundCreatedV3 struct {
+	Name  string `json:"name"`
+	Notes string `json:"notes"`
+}
+
+func newTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+	reg := NewRegistry()
+	if err := reg.RegisterUpcaster("round.created.v1", "round.created.v2", Typed(func(v1 *roundCreatedV1) (*roundCreatedV2, error) {
+		return &roundCreatedV2{Name: v1.Title}, nil
+	})); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := reg.RegisterUpcaster("round.created.v2", "round.created.v3", Typed(func(v2 *roundCreatedV2) (*roundCreatedV3, error) {
+		return &roundCreatedV3{Name: v2.Name, Notes: "migrated"}, nil
+	})); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return reg
+}
+
+func TestUpcastMiddleware_ChainsToNewestVersion(t *testing.T) {
+	reg := newTestRegistry(t)
+
+	var seen *message.Message
+	handler := UpcastMiddleware(reg, slog.New(slog.NewTextHandler(io.Discard, nil)))(
+		func(msg *message.Message) ([]*message.Message, error) {
+			seen = msg
+			return nil, nil
+		},
+	)
+
+	msg := message.NewMessage("id", []byte(`{"title":"Doubles"}`))
+	msg.Metadata.Set("topic", "round.created.v1")
+	if _, err := handler(msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := string(seen.Payload); got != `{"name":"Doubles","notes":"migrated"}` {
+		t.Fatalf("unexpected upcast payload %s", got)
+	}
+	if seen.Metadata.Get("topic") != "round.created.v3" || seen.Metadata.Get(UpcastFromMetadataKey) != "round.created.v1" {
+		t.Fatalf("unexpected metadata %v", seen.Metadata)
+	}
+}
+
+func TestUpcastMiddleware_UsesDeliveredSubject(t *testing.T) {
+	reg := newTestRegistry(t)
+
+	var seen *message.Message
+	handler := UpcastMiddleware(reg, slog.New(slog.NewTextHandler(io.Discard, nil)))(
+		func(msg *message.Message) ([]*message.Message, error) {
+			seen = msg
+			return nil, nil
+		},
+	)
+
+	// The producer's topic metadata is missing, as on messages from other publishers.
+	msg := message.NewMessage("id", []byte(`{"name":"Doubles"}`))
+	msg.Metadata.Set(utils.MetadataSubject, "round.created.v2")
+	if _, err := handler(msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := string(seen.Payload); got != `{"name":"Doubles","notes":"migrated"}` {
+		t.Fatalf("unexpected upcast payload %s", got)
+	}
+	if seen.Metadata.Get(utils.MetadataSubject) != "round.created.v3" || seen.Metadata.Get("topic") != "round.created.v3" {
+		t.Fatalf("expected subject and topic to name the new version, got %v", seen.Metadata)
+	}
+	if seen.Metadata.Get(UpcastFromMetadataKey) != "round.created.v2" {
+		t.Fatalf("expected upcast_from to be the delivered subject, got %v", seen.Metadata)
+	}
+}
+
+func TestRegisterUpcaster_RejectsDuplicates(t *testing.T) {
+	reg := newTestRegistry(t)
+	if err := reg.RegisterUpcaster("round.created.v1", "round.created.v4", func(p []byte) ([]byte, error) { return p, nil }); err == nil {
+		t.Fatal("expected duplicate upcaster to be rejected")
+	}
+}
+
+func TestRegisterDowncaster_RejectsCycles(t *testing.T) {
+	reg := NewRegistry()
+	identity := func(p []byte) ([]byte, error) { return p, nil }
+	if err := reg.RegisterDowncaster("round.created.v3", "round.created.v2", identity, time.Time{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := reg.RegisterDowncaster("round.created.v2", "round.created.v1", identity, time.Time{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := reg.RegisterDowncaster("round.created.v2", "round.created.v3", identity, time.Time{}); err == nil {
+		t.Fatal("expected a direct cycle to be rejected")
+	}
+	if err := reg.RegisterDowncaster("round.created.v1", "round.created.v3", identity, time.Time{}); err == nil {
+		t.Fatal("expected a chained cycle to be rejected")
+	}
+	if err := reg.RegisterDowncaster("round.created.v3", "round.created.v1", identity, time.Time{}); err != nil {
+		t.Fatalf("expected a second downcaster without a cycle to register, got %v", err)
+	}
+}
+
+func TestDowncasts_RespectMigrationWindow(t *testing.T) {
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	reg := NewRegistry()
+	reg.now = func() time.Time { return now }
+	if err := reg.RegisterDowncaster("round.created.v2", "round.created.v1", Typed(func(v2 *roundCreatedV2) (*roundCreatedV1, error) {
+		return &roundCreatedV1{Title: v2.Name}, nil
+	}), now.Add(time.Hour)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msg := message.NewMessage("id", []byte(`{"name":"Doubles"}`))
+	msg.Metadata.Set("Nats-Msg-Id", "idem-new")
+	msg.Metadata.Set("idempotency_key", "round-1")
+
+	out, err := reg.Downcasts("round.created.v2", msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	old, ok := out["round.created.v1"]
+	if !ok {
+		t.Fatalf("expected downcast to round.created.v1, got %v", out)
+	}
+	if string(old.Payload) != `{"title":"Doubles"}` || old.UUID == msg.UUID {
+		t.Fatalf("unexpected downcast message %s %s", old.UUID, old.Payload)
+	}
+	msgID := old.Metadata.Get("Nats-Msg-Id")
+	if msgID == "" || msgID == "idem-new" || msgID != DowncastMsgID(msg, "round.created.v1") {
+		t.Fatalf("expected a Nats-Msg-Id derived from the original, got %v", old.Metadata)
+	}
+	if old.Metadata.Get("idempotency_key") != "round-1" {
+		t.Fatalf("expected idempotency_key to be kept, got %v", old.Metadata)
+	}
+
+	// A retried publish of the same message yields the same copy.
+	again, err := reg.Downcasts("round.created.v2", msg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if retry := again["round.created.v1"]; retry.UUID != old.UUID || retry.Metadata.Get("Nats-Msg-Id") != msgID {
+		t.Fatalf("expected a deterministic copy, got %s %s", retry.UUID, retry.Metadata.Get("Nats-Msg-Id"))
+	}
+
+	now = now.Add(time.Hour)
+	if out, _ := reg.Downcasts("round.created.v2", msg); len(out) != 0 {
+		t.Fatalf("expected no downcasts after the window closes, got %v", out)
+	}
+}
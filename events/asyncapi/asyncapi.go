@@ -0,0 +1,279 @@
+// Package asyncapi generates an AsyncAPI 3.0 document and per-payload JSON Schemas
+// from the event registries (GetV1Registry) in events/*.
+//
+// Usage:
+//
+//	gen := asyncapi.NewGenerator(asyncapi.DefaultDomains(), asyncapi.WithVersion("1.4.0"))
+//	doc, err := gen.Document()
+//	schemas, err := gen.PayloadSchemas()
+//
+// The cmd/asyncapi-gen command (make asyncapi) writes both to disk.
+package asyncapi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/events/catalog"
+	sharedevents "github.com/Black-And-White-Club/frolf-bot-shared/events/shared"
+)
+
+// Version is the AsyncAPI specification version emitted.
+const Version = "3.0.0"
+
+const (
+	schemaRefPrefix  = "#/components/schemas/"
+	messageRefPrefix = "#/components/messages/"
+	channelRefPrefix = "#/channels/"
+	defsRefPrefix    = "#/$defs/"
+	contentTypeJSON  = "application/json"
+)
+
+// Document is an AsyncAPI 3.0 document.
+type Document struct {
+	AsyncAPI           string                `json:"asyncapi"`
+	Info               Info                  `json:"info"`
+	DefaultContentType string                `json:"defaultContentType"`
+	Channels           map[string]*Channel   `json:"channels"`
+	Operations         map[string]*Operation `json:"operations"`
+	Components         Components            `json:"components"`
+}
+
+// Info is the document's info object.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Reference is a JSON reference.
+type Reference struct {
+	Ref string `json:"$ref"`
+}
+
+// Channel is a NATS subject.
+type Channel struct {
+	Address     string               `json:"address"`
+	Title       string               `json:"title,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Messages    map[string]Reference `json:"messages"`
+}
+
+// Operation is a send (producer) or receive (consumer) of a channel by a service module.
+type Operation struct {
+	Action   string      `json:"action"`
+	Channel  Reference   `json:"channel"`
+	Summary  string      `json:"summary,omitempty"`
+	Messages []Reference `json:"messages"`
+	Service  string      `json:"x-service,omitempty"`
+	Module   string      `json:"x-module,omitempty"`
+}
+
+// Message describes an event payload.
+type Message struct {
+	Name        string  `json:"name"`
+	Title       string  `json:"title,omitempty"`
+	Summary     string  `json:"summary,omitempty"`
+	Description string  `json:"description,omitempty"`
+	ContentType string  `json:"contentType"`
+	Payload     *Schema `json:"payload"`
+	Domain      string  `json:"x-domain,omitempty"`
+	Producer    *Actor  `json:"x-producer,omitempty"`
+	Consumers   []Actor `json:"x-consumers,omitempty"`
+}
+
+// Actor mirrors sharedevents.Actor with JSON names.
+type Actor struct {
+	Service string `json:"service"`
+	Module  string `json:"module"`
+}
+
+// Components holds reusable messages and schemas.
+type Components struct {
+	Messages map[string]*Message `json:"messages"`
+	Schemas  map[string]*Schema  `json:"schemas"`
+}
+
+// Option configures a Generator.
+type Option func(*Generator)
+
+// WithTitle sets the document title.
+func WithTitle(title string) Option {
+	return func(g *Generator) {
+		g.info.Title = title
+	}
+}
+
+// WithVersion sets the document (API) version.
+func WithVersion(version string) Option {
+	return func(g *Generator) {
+		g.info.Version = version
+	}
+}
+
+// WithDescription sets the document description.
+func WithDescription(description string) Option {
+	return func(g *Generator) {
+		g.info.Description = description
+	}
+}
+
+// WithTypeSchema overrides the schema emitted for a Go type, e.g. for enums or
+// types with a custom JSON encoding.
+func WithTypeSchema(t reflect.Type, schema Schema) Option {
+	return func(g *Generator) {
+		g.typeSchemas[t] = schema
+	}
+}
+
+// Generator builds AsyncAPI and JSON Schema output from event registries.
+type Generator struct {
+	domains     []Domain
+	info        Info
+	typeSchemas map[reflect.Type]Schema
+}
+
+// NewGenerator creates a generator over the given domains.
+func NewGenerator(domains []Domain, opts ...Option) *Generator {
+	g := &Generator{
+		domains:     domains,
+		info:        Info{Title: "Frolf Bot Events", Version: "1.0.0"},
+		typeSchemas: defaultTypeSchemas(),
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// event is a registry entry tagged with its domain.
+type event struct {
+	subject string
+	domain  string
+	info    sharedevents.EventInfo
+}
+
+// events merges all registries through the catalog, sorted by subject.
+func (g *Generator) events() ([]event, error) {
+	var out []event
+	for _, entry := range catalog.New(g.domains...).Entries() {
+		if entry.Info.Payload == nil {
+			return nil, fmt.Errorf("event %s in %s registry has no payload", entry.Subject, entry.Domains[0])
+		}
+		info := entry.Info
+		info.Consumers = entry.Consumers
+		out = append(out, event{subject: entry.Subject, domain: entry.Domains[0], info: info})
+	}
+	return out, nil
+}
+
+// Document builds the AsyncAPI document.
+func (g *Generator) Document() (*Document, error) {
+	events, err := g.events()
+	if err != nil {
+		return nil, err
+	}
+
+	builder := newSchemaBuilder(schemaRefPrefix, g.typeSchemas)
+	doc := &Document{
+		AsyncAPI:           Version,
+		Info:               g.info,
+		DefaultContentType: contentTypeJSON,
+		Channels:           make(map[string]*Channel),
+		Operations:         make(map[string]*Operation),
+		Components: Components{
+			Messages: make(map[string]*Message),
+		},
+	}
+
+	for _, ev := range events {
+		msgRef := Reference{Ref: messageRefPrefix + ev.subject}
+		channelRef := Reference{Ref: channelRefPrefix + ev.subject}
+
+		doc.Channels[ev.subject] = &Channel{
+			Address:     ev.subject,
+			Title:       ev.info.Summary,
+			Description: ev.info.Description,
+			Messages:    map[string]Reference{ev.subject: msgRef},
+		}
+
+		msg := &Message{
+			Name:        ev.subject,
+			Title:       ev.info.Summary,
+			Summary:     ev.info.Summary,
+			Description: ev.info.Description,
+			ContentType: contentTypeJSON,
+			Payload:     builder.schemaFor(reflect.TypeOf(ev.info.Payload)),
+			Domain:      ev.domain,
+		}
+		if p := ev.info.Producer; p.Service != "" {
+			msg.Producer = &Actor{Service: p.Service, Module: p.Module}
+			doc.Operations[operationID(p, "send", ev.subject)] = &Operation{
+				Action:   "send",
+				Channel:  channelRef,
+				Summary:  ev.info.Summary,
+				Messages: []Reference{{Ref: channelRefPrefix + ev.subject + "/messages/" + ev.subject}},
+				Service:  p.Service,
+				Module:   p.Module,
+			}
+		}
+		for _, c := range ev.info.Consumers {
+			msg.Consumers = append(msg.Consumers, Actor{Service: c.Service, Module: c.Module})
+			doc.Operations[operationID(c, "receive", ev.subject)] = &Operation{
+				Action:   "receive",
+				Channel:  channelRef,
+				Summary:  ev.info.Summary,
+				Messages: []Reference{{Ref: channelRefPrefix + ev.subject + "/messages/" + ev.subject}},
+				Service:  c.Service,
+				Module:   c.Module,
+			}
+		}
+		doc.Components.Messages[ev.subject] = msg
+	}
+
+	doc.Components.Schemas = builder.defs
+	return doc, nil
+}
+
+// PayloadSchemas returns a standalone JSON Schema per payload type, keyed by schema
+// name (e.g. "roundevents.CreateRoundRequestedPayloadV1"). Each schema carries the
+// definitions it references under $defs.
+func (g *Generator) PayloadSchemas() (map[string]*Schema, error) {
+	events, err := g.events()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]*Schema)
+	for _, ev := range events {
+		builder := newSchemaBuilder(defsRefPrefix, g.typeSchemas)
+		root := builder.schemaFor(reflect.TypeOf(ev.info.Payload))
+
+		// Named payloads are keyed by type; anonymous ones by their subject.
+		name := ev.subject
+		if strings.HasPrefix(root.Ref, defsRefPrefix) {
+			name = strings.TrimPrefix(root.Ref, defsRefPrefix)
+		}
+		if _, done := out[name]; done {
+			continue
+		}
+
+		root.SchemaURI = JSONSchemaDialect
+		root.ID = name + ".schema.json"
+		root.Title = name
+		if len(builder.defs) > 0 {
+			root.Defs = builder.defs
+		}
+		out[name] = root
+	}
+	return out, nil
+}
+
+func operationID(actor sharedevents.Actor, action, subject string) string {
+	id := actor.Service
+	if actor.Module != "" {
+		id += "." + actor.Module
+	}
+	return id + "." + action + "." + subject
+}
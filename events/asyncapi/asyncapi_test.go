@@ -0,0 +1,111 @@
+package asyncapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	sharedevents "github.com/Black-And-White-Club/frolf-bot-shared/events/shared"
+	sharedtypes "github.com/Black-And-White-Club/frolf-bot-shared/types/shared"
+)
+
+type testPayload struct {
+	RoundID   sharedtypes.RoundID    `json:"round_id"`
+	StartTime *sharedtypes.StartTime `json:"start_time,omitempty"`
+	TagNumber sharedtypes.TagNumber  `json:"tag_number"`
+	Role      sharedtypes.UserRoleEnum
+	Internal  string       `json:"-"`
+	Child     *testPayload `json:"child,omitempty"`
+}
+
+func TestSchemaFor_CustomTypes(t *testing.T) {
+	b := newSchemaBuilder(defsRefPrefix, defaultTypeSchemas())
+	root := b.schemaFor(reflect.TypeOf(&testPayload{}))
+
+	def := b.defs[strings.TrimPrefix(root.Ref, defsRefPrefix)]
+	if def == nil {
+		t.Fatalf("expected definition for %q, got %v", root.Ref, b.defs)
+	}
+
+	if got := def.Properties["round_id"]; got.Type != "string" || got.Format != "uuid" {
+		t.Fatalf("expected RoundID as uuid string, got %+v", got)
+	}
+	if got := def.Properties["start_time"]; got.Type != "string" || got.Format != "date-time" {
+		t.Fatalf("expected StartTime as date-time string, got %+v", got)
+	}
+	if got := def.Properties["tag_number"]; got.Type != "integer" {
+		t.Fatalf("expected TagNumber as integer, got %+v", got)
+	}
+	if got := def.Properties["Role"]; len(got.Enum) != 3 {
+		t.Fatalf("expected role enum, got %+v", got)
+	}
+	if _, ok := def.Properties["Internal"]; ok {
+		t.Fatal("expected json:\"-\" field to be skipped")
+	}
+	if got := def.Properties["child"]; got.Ref != root.Ref {
+		t.Fatalf("expected recursive reference, got %+v", got)
+	}
+	if !reflect.DeepEqual(def.Required, []string{"round_id", "tag_number", "Role"}) {
+		t.Fatalf("unexpected required fields %v", def.Required)
+	}
+}
+
+func TestDocument_ReferencesResolve(t *testing.T) {
+	gen := NewGenerator(DefaultDomains())
+	doc, err := gen.Document()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if doc.AsyncAPI != Version {
+		t.Fatalf("expected asyncapi %s, got %s", Version, doc.AsyncAPI)
+	}
+	if _, ok := doc.Channels[sharedevents.PointsAwardedV1]; !ok {
+		t.Fatalf("expected channel for %s", sharedevents.PointsAwardedV1)
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("failed to marshal document: %v", err)
+	}
+	for _, m := range regexp.MustCompile(`"\$ref":"([^"]+)"`).FindAllStringSubmatch(string(data), -1) {
+		ref := m[1]
+		switch {
+		case strings.HasPrefix(ref, schemaRefPrefix):
+			if _, ok := doc.Components.Schemas[strings.TrimPrefix(ref, schemaRefPrefix)]; !ok {
+				t.Fatalf("dangling schema reference %s", ref)
+			}
+		case strings.HasPrefix(ref, messageRefPrefix):
+			if _, ok := doc.Components.Messages[strings.TrimPrefix(ref, messageRefPrefix)]; !ok {
+				t.Fatalf("dangling message reference %s", ref)
+			}
+		case strings.HasPrefix(ref, channelRefPrefix):
+			channel := strings.SplitN(strings.TrimPrefix(ref, channelRefPrefix), "/", 2)[0]
+			if _, ok := doc.Channels[channel]; !ok {
+				t.Fatalf("dangling channel reference %s", ref)
+			}
+		default:
+			t.Fatalf("unexpected reference %s", ref)
+		}
+	}
+}
+
+func TestPayloadSchemas_AreSelfContained(t *testing.T) {
+	schemas, err := NewGenerator(DefaultDomains()).PayloadSchemas()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	schema, ok := schemas["sharedevents.PointsAwardedPayloadV1"]
+	if !ok {
+		t.Fatal("expected schema for sharedevents.PointsAwardedPayloadV1")
+	}
+	if schema.SchemaURI != JSONSchemaDialect {
+		t.Fatalf("expected $schema %s, got %s", JSONSchemaDialect, schema.SchemaURI)
+	}
+	if _, ok := schema.Defs["roundtypes.Participant"]; !ok {
+		t.Fatalf("expected referenced definitions to be embedded, got %v", schema.Defs)
+	}
+}
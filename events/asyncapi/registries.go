@@ -0,0 +1,11 @@
+package asyncapi
+
+import "github.com/Black-And-White-Club/frolf-bot-shared/events/catalog"
+
+// Domain is a named event registry.
+type Domain = catalog.Domain
+
+// DefaultDomains returns every registry in events/*, see catalog.DefaultDomains.
+func DefaultDomains() []Domain {
+	return catalog.DefaultDomains()
+}
@@ -0,0 +1,214 @@
+package asyncapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	sharedtypes "github.com/Black-And-White-Club/frolf-bot-shared/types/shared"
+	"github.com/google/uuid"
+)
+
+// JSONSchemaDialect is the JSON Schema draft emitted for standalone payload schemas.
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is the subset of JSON Schema produced by the generator.
+type Schema struct {
+	SchemaURI            string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// defaultTypeSchemas maps types with custom JSON encodings to their wire schema.
+func defaultTypeSchemas() map[reflect.Type]Schema {
+	return map[reflect.Type]Schema{
+		reflect.TypeOf(time.Time{}):                  {Type: "string", Format: "date-time"},
+		reflect.TypeOf(uuid.UUID{}):                  {Type: "string", Format: "uuid"},
+		reflect.TypeOf(json.RawMessage{}):            {Description: "Arbitrary JSON"},
+		reflect.TypeOf(sharedtypes.RoundID{}):        {Type: "string", Format: "uuid"},
+		reflect.TypeOf(sharedtypes.EventMessageID{}): {Type: "string", Format: "uuid"},
+		reflect.TypeOf(sharedtypes.StartTime{}):      {Type: "string", Format: "date-time"},
+		reflect.TypeOf(sharedtypes.UserRoleEnum("")): {
+			Type: "string",
+			Enum: []any{
+				string(sharedtypes.UserRoleUser),
+				string(sharedtypes.UserRoleEditor),
+				string(sharedtypes.UserRoleAdmin),
+			},
+		},
+	}
+}
+
+// schemaBuilder reflects Go types into JSON Schema. Named structs are emitted once
+// as definitions and referenced through refPrefix.
+type schemaBuilder struct {
+	refPrefix string
+	overrides map[reflect.Type]Schema
+	defs      map[string]*Schema
+	names     map[reflect.Type]string
+}
+
+func newSchemaBuilder(refPrefix string, overrides map[reflect.Type]Schema) *schemaBuilder {
+	return &schemaBuilder{
+		refPrefix: refPrefix,
+		overrides: overrides,
+		defs:      make(map[string]*Schema),
+		names:     make(map[reflect.Type]string),
+	}
+}
+
+// schemaFor returns the schema of t, registering struct definitions as a side effect.
+func (b *schemaBuilder) schemaFor(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if s, ok := b.overrides[t]; ok {
+		return &s
+	}
+
+	// Types that encode themselves: text marshalers are strings, anything else is opaque.
+	if t.Name() != "" {
+		if implements(t, textMarshalerType) {
+			return &Schema{Type: "string"}
+		}
+		if implements(t, jsonMarshalerType) {
+			return &Schema{Description: "Custom JSON encoding of " + t.String()}
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if t.Name() == "" {
+			// Anonymous structs (e.g. &struct{}{} payloads) are inlined.
+			def := &Schema{Type: "object"}
+			b.fillStruct(def, t)
+			return def
+		}
+		return b.refFor(t)
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", ContentEncoding: "base64"}
+		}
+		return &Schema{Type: "array", Items: b.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schemaFor(t.Elem())}
+	default:
+		// Interfaces and anything else accept arbitrary JSON.
+		return &Schema{}
+	}
+}
+
+// refFor registers the struct definition for t and returns a reference to it.
+func (b *schemaBuilder) refFor(t reflect.Type) *Schema {
+	if name, ok := b.names[t]; ok {
+		return &Schema{Ref: b.refPrefix + name}
+	}
+
+	name := b.uniqueName(t)
+	b.names[t] = name
+	// Reserve the name before descending so recursive types terminate.
+	def := &Schema{Type: "object", Title: t.Name()}
+	b.defs[name] = def
+	b.fillStruct(def, t)
+	return &Schema{Ref: b.refPrefix + name}
+}
+
+func (b *schemaBuilder) fillStruct(def *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		name, omitempty, skip := jsonFieldName(field)
+		if skip {
+			continue
+		}
+
+		// Embedded structs without a JSON name are flattened, as encoding/json does.
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && !implements(ft, jsonMarshalerType) && !implements(ft, textMarshalerType) {
+				b.fillStruct(def, ft)
+				continue
+			}
+		}
+
+		if def.Properties == nil {
+			def.Properties = make(map[string]*Schema)
+		}
+		def.Properties[name] = b.schemaFor(field.Type)
+		if !omitempty && field.Type.Kind() != reflect.Pointer {
+			def.Required = append(def.Required, name)
+		}
+	}
+}
+
+// uniqueName returns a definition name such as "roundtypes.Participant".
+func (b *schemaBuilder) uniqueName(t reflect.Type) string {
+	// Type.String uses the package name, e.g. roundtypes.Participant.
+	base := t.String()
+	base = strings.NewReplacer("[", "_", "]", "_", "*", "", "/", "_", " ", "").Replace(base)
+
+	name := base
+	for i := 2; ; i++ {
+		if _, taken := b.defs[name]; !taken {
+			return name
+		}
+		name = base + "_" + strconv.Itoa(i)
+	}
+}
+
+func jsonFieldName(field reflect.StructField) (name string, omitempty, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" || opt == "omitzero" {
+			omitempty = true
+		}
+	}
+	return name, omitempty, false
+}
+
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PointerTo(t).Implements(iface)
+}
@@ -0,0 +1,319 @@
+// Package catalog merges every event registry (GetV1Registry) into a single
+// catalog and checks it for cross-registry inconsistencies.
+//
+// Usage at service startup:
+//
+//	report := catalog.Default().Validate(catalog.WithStreamResolver(eventbus.ResolveStreamFromTopic))
+//	if err := report.Err(); err != nil {
+//	    return err
+//	}
+//
+// and in tests:
+//
+//	if err := catalog.Default().Validate().Err(); err != nil {
+//	    t.Fatal(err)
+//	}
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	sharedevents "github.com/Black-And-White-Club/frolf-bot-shared/events/shared"
+)
+
+// Entry is a subject with its merged registrations.
+type Entry struct {
+	Subject string
+	// Info is the EventInfo from the first domain registering the subject.
+	Info sharedevents.EventInfo
+	// PayloadType is the reflected type of Info.Payload (nil if missing).
+	PayloadType reflect.Type
+	// Domains lists every registry that registers the subject.
+	Domains []string
+	// Producers and Consumers are merged across all registrations.
+	Producers []sharedevents.Actor
+	Consumers []sharedevents.Actor
+}
+
+// registration is a single domain's view of a subject, kept for validation.
+type registration struct {
+	domain      string
+	payloadType reflect.Type
+}
+
+// Catalog is an immutable, merged view of event registries.
+type Catalog struct {
+	entries       map[string]*Entry
+	subjects      []string
+	registrations map[string][]registration
+	// conflicts are subjects registered with more than one payload type.
+	conflicts map[string]bool
+}
+
+// New builds a catalog from the given domains.
+func New(domains ...Domain) *Catalog {
+	c := &Catalog{
+		entries:       make(map[string]*Entry),
+		registrations: make(map[string][]registration),
+		conflicts:     make(map[string]bool),
+	}
+
+	for _, d := range domains {
+		for subject, info := range d.Registry() {
+			var payloadType reflect.Type
+			if info.Payload != nil {
+				payloadType = reflect.TypeOf(info.Payload)
+			}
+			c.registrations[subject] = append(c.registrations[subject], registration{domain: d.Name, payloadType: payloadType})
+
+			entry, exists := c.entries[subject]
+			if !exists {
+				entry = &Entry{Subject: subject, Info: info, PayloadType: payloadType}
+				c.entries[subject] = entry
+				c.subjects = append(c.subjects, subject)
+			} else if payloadType != nil && entry.PayloadType != nil && payloadType != entry.PayloadType {
+				c.conflicts[subject] = true
+			}
+			entry.Domains = append(entry.Domains, d.Name)
+			entry.Producers = appendActor(entry.Producers, info.Producer)
+			for _, consumer := range info.Consumers {
+				entry.Consumers = appendActor(entry.Consumers, consumer)
+			}
+		}
+	}
+
+	sort.Strings(c.subjects)
+	return c
+}
+
+var (
+	defaultOnce    sync.Once
+	defaultCatalog *Catalog
+)
+
+// Default returns the catalog of DefaultDomains, built once per process.
+func Default() *Catalog {
+	defaultOnce.Do(func() {
+		defaultCatalog = New(DefaultDomains()...)
+	})
+	return defaultCatalog
+}
+
+// Lookup returns the entry for a subject.
+func (c *Catalog) Lookup(subject string) (Entry, bool) {
+	entry, ok := c.entries[subject]
+	if !ok {
+		return Entry{}, false
+	}
+	return *entry, true
+}
+
+// PayloadFor returns the registered payload prototype for a subject.
+// It lets a Catalog serve as an eventbus.PayloadRegistry. Subjects registered
+// with conflicting payload types have no single prototype and are not returned.
+func (c *Catalog) PayloadFor(subject string) (any, bool) {
+	entry, ok := c.entries[subject]
+	if !ok || entry.Info.Payload == nil || c.conflicts[subject] {
+		return nil, false
+	}
+	return entry.Info.Payload, true
+}
+
+// Subjects returns all subjects in lexical order.
+func (c *Catalog) Subjects() []string {
+	return append([]string(nil), c.subjects...)
+}
+
+// Entries returns all entries ordered by subject.
+func (c *Catalog) Entries() []Entry {
+	out := make([]Entry, 0, len(c.subjects))
+	for _, subject := range c.subjects {
+		out = append(out, *c.entries[subject])
+	}
+	return out
+}
+
+// ByProducer returns the entries produced by service. An empty module matches every module.
+func (c *Catalog) ByProducer(service, module string) []Entry {
+	return c.filter(func(e *Entry) bool { return containsActor(e.Producers, service, module) })
+}
+
+// ByConsumer returns the entries consumed by service. An empty module matches every module.
+func (c *Catalog) ByConsumer(service, module string) []Entry {
+	return c.filter(func(e *Entry) bool { return containsActor(e.Consumers, service, module) })
+}
+
+func (c *Catalog) filter(keep func(*Entry) bool) []Entry {
+	var out []Entry
+	for _, subject := range c.subjects {
+		if entry := c.entries[subject]; keep(entry) {
+			out = append(out, *entry)
+		}
+	}
+	return out
+}
+
+// IssueKind classifies a validation finding.
+type IssueKind string
+
+const (
+	// IssuePayloadConflict: the subject is registered with different payload types.
+	IssuePayloadConflict IssueKind = "payload_conflict"
+	// IssueMissingPayload: a registration has no payload.
+	IssueMissingPayload IssueKind = "missing_payload"
+	// IssueUnroutable: no stream matches the subject.
+	IssueUnroutable IssueKind = "unroutable_subject"
+	// IssueNoConsumers: nothing is registered to consume the subject.
+	IssueNoConsumers IssueKind = "no_consumers"
+)
+
+// Warning reports whether the issue is informational rather than an error.
+func (k IssueKind) Warning() bool {
+	return k == IssueNoConsumers
+}
+
+// Issue is a single validation finding.
+type Issue struct {
+	Kind    IssueKind
+	Subject string
+	Message string
+	// Known marks a payload conflict listed in knownPayloadConflicts; it is
+	// still reported but does not fail Err.
+	Known bool
+}
+
+// Warning reports whether the issue is informational rather than an error.
+func (i Issue) Warning() bool {
+	return i.Known || i.Kind.Warning()
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s: %s", i.Kind, i.Subject, i.Message)
+}
+
+// Report is the result of Validate.
+type Report struct {
+	Issues []Issue
+}
+
+// OfKind returns the issues of the given kind.
+func (r Report) OfKind(kind IssueKind) []Issue {
+	var out []Issue
+	for _, issue := range r.Issues {
+		if issue.Kind == kind {
+			out = append(out, issue)
+		}
+	}
+	return out
+}
+
+// Err joins every non-warning issue into an error, or returns nil.
+func (r Report) Err() error {
+	var errs []error
+	for _, issue := range r.Issues {
+		if !issue.Warning() {
+			errs = append(errs, errors.New(issue.String()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateOption configures Validate.
+type ValidateOption func(*validateOptions)
+
+type validateOptions struct {
+	resolveStream func(topic string) (string, error)
+}
+
+// WithStreamResolver checks every subject against a topic → stream resolver,
+// typically eventbus.ResolveStreamFromTopic or StreamTopology.ResolveStream.
+func WithStreamResolver(resolve func(topic string) (string, error)) ValidateOption {
+	return func(o *validateOptions) {
+		o.resolveStream = resolve
+	}
+}
+
+// Validate checks the catalog for payload conflicts, missing payloads, subjects that
+// map to no stream (when a resolver is given) and subjects without consumers.
+// Issues are ordered by subject.
+func (c *Catalog) Validate(opts ...ValidateOption) Report {
+	var o validateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var report Report
+	for _, subject := range c.subjects {
+		entry := c.entries[subject]
+		regs := c.registrations[subject]
+
+		for _, reg := range regs {
+			if reg.payloadType == nil {
+				report.Issues = append(report.Issues, Issue{
+					Kind:    IssueMissingPayload,
+					Subject: subject,
+					Message: fmt.Sprintf("registered without payload in %s", reg.domain),
+				})
+			}
+		}
+
+		for _, reg := range regs[1:] {
+			if reg.payloadType != nil && entry.PayloadType != nil && reg.payloadType != entry.PayloadType {
+				issue := Issue{
+					Kind:    IssuePayloadConflict,
+					Subject: subject,
+					Message: fmt.Sprintf("%s registers %v but %s registers %v", regs[0].domain, entry.PayloadType, reg.domain, reg.payloadType),
+				}
+				if reason, ok := knownPayloadConflicts[subject]; ok {
+					issue.Known = true
+					issue.Message += " (known: " + reason + ")"
+				}
+				report.Issues = append(report.Issues, issue)
+			}
+		}
+
+		if o.resolveStream != nil {
+			if _, err := o.resolveStream(subject); err != nil {
+				report.Issues = append(report.Issues, Issue{
+					Kind:    IssueUnroutable,
+					Subject: subject,
+					Message: err.Error(),
+				})
+			}
+		}
+
+		if len(entry.Consumers) == 0 {
+			report.Issues = append(report.Issues, Issue{
+				Kind:    IssueNoConsumers,
+				Subject: subject,
+				Message: fmt.Sprintf("no consumers registered (domains: %v)", entry.Domains),
+			})
+		}
+	}
+	return report
+}
+
+func appendActor(actors []sharedevents.Actor, actor sharedevents.Actor) []sharedevents.Actor {
+	if actor.Service == "" {
+		return actors
+	}
+	for _, existing := range actors {
+		if existing == actor {
+			return actors
+		}
+	}
+	return append(actors, actor)
+}
+
+func containsActor(actors []sharedevents.Actor, service, module string) bool {
+	for _, a := range actors {
+		if a.Service == service && (module == "" || a.Module == module) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,216 @@
+package catalog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Black-And-White-Club/frolf-bot-shared/eventbus"
+	"github.com/Black-And-White-Club/frolf-bot-shared/events/catalog"
+	leaderboardevents "github.com/Black-And-White-Club/frolf-bot-shared/events/leaderboard"
+	roundevents "github.com/Black-And-White-Club/frolf-bot-shared/events/round"
+	sharedevents "github.com/Black-And-White-Club/frolf-bot-shared/events/shared"
+	sharedtypes "github.com/Black-And-White-Club/frolf-bot-shared/types/shared"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+type payloadA struct{}
+type payloadB struct{}
+
+func registry(entries map[string]sharedevents.EventInfo) func() map[string]sharedevents.EventInfo {
+	return func() map[string]sharedevents.EventInfo { return entries }
+}
+
+func TestDefaultCatalogIsConsistent(t *testing.T) {
+	report := catalog.Default().Validate(catalog.WithStreamResolver(eventbus.ResolveStreamFromTopic))
+	if err := report.Err(); err != nil {
+		t.Fatalf("event catalog is inconsistent:\n%v", err)
+	}
+
+	conflicts := report.OfKind(catalog.IssuePayloadConflict)
+	if len(conflicts) != 1 || conflicts[0].Subject != sharedevents.SyncRoundsTagRequestV1 || !conflicts[0].Known {
+		t.Fatalf("expected only the known conflict on %s, got %v", sharedevents.SyncRoundsTagRequestV1, conflicts)
+	}
+	if _, ok := catalog.Default().PayloadFor(sharedevents.SyncRoundsTagRequestV1); ok {
+		t.Fatal("expected no single payload prototype for a conflicting subject")
+	}
+}
+
+func TestValidate_ReportsCrossRegistryIssues(t *testing.T) {
+	backend := sharedevents.Actor{Service: sharedevents.ServiceBackend, Module: "round"}
+	discord := sharedevents.Actor{Service: sharedevents.ServiceDiscord, Module: "round"}
+
+	c := catalog.New(
+		catalog.Domain{Name: "a", Registry: registry(map[string]sharedevents.EventInfo{
+			"round.created.v1": {Payload: &payloadA{}, Producer: backend, Consumers: []sharedevents.Actor{discord}},
+			"mystery.event.v1": {Payload: &payloadA{}, Producer: backend},
+		})},
+		catalog.Domain{Name: "b", Registry: registry(map[string]sharedevents.EventInfo{
+			"round.created.v1": {Payload: &payloadB{}, Producer: backend},
+		})},
+	)
+
+	report := c.Validate(catalog.WithStreamResolver(eventbus.ResolveStreamFromTopic))
+
+	if issues := report.OfKind(catalog.IssuePayloadConflict); len(issues) != 1 || issues[0].Subject != "round.created.v1" {
+		t.Fatalf("expected payload conflict on round.created.v1, got %v", issues)
+	}
+	if issues := report.OfKind(catalog.IssueUnroutable); len(issues) != 1 || issues[0].Subject != "mystery.event.v1" {
+		t.Fatalf("expected unroutable mystery.event.v1, got %v", issues)
+	}
+	if issues := report.OfKind(catalog.IssueNoConsumers); len(issues) != 1 || issues[0].Subject != "mystery.event.v1" {
+		t.Fatalf("expected no-consumer warning for mystery.event.v1, got %v", issues)
+	}
+	if report.Err() == nil {
+		t.Fatal("expected conflicts to be reported as errors")
+	}
+}
+
+func TestLookups(t *testing.T) {
+	backend := sharedevents.Actor{Service: sharedevents.ServiceBackend, Module: "round"}
+	discord := sharedevents.Actor{Service: sharedevents.ServiceDiscord, Module: "round"}
+
+	c := catalog.New(
+		catalog.Domain{Name: "a", Registry: registry(map[string]sharedevents.EventInfo{
+			"round.created.v1": {Payload: &payloadA{}, Producer: backend, Consumers: []sharedevents.Actor{discord}},
+		})},
+		catalog.Domain{Name: "b", Registry: registry(map[string]sharedevents.EventInfo{
+			"round.created.v1": {Payload: &payloadA{}, Producer: backend, Consumers: []sharedevents.Actor{backend}},
+		})},
+	)
+
+	entry, ok := c.Lookup("round.created.v1")
+	if !ok {
+		t.Fatal("expected subject to be found")
+	}
+	if len(entry.Domains) != 2 || len(entry.Consumers) != 2 || len(entry.Producers) != 1 {
+		t.Fatalf("expected merged registrations, got %+v", entry)
+	}
+
+	if got := c.ByProducer(sharedevents.ServiceBackend, ""); len(got) != 1 {
+		t.Fatalf("expected 1 event produced by backend, got %d", len(got))
+	}
+	if got := c.ByConsumer(sharedevents.ServiceDiscord, "round"); len(got) != 1 {
+		t.Fatalf("expected 1 event consumed by discord round, got %d", len(got))
+	}
+	if got := c.ByConsumer(sharedevents.ServiceDiscord, "score"); len(got) != 0 {
+		t.Fatalf("expected no events consumed by discord score, got %d", len(got))
+	}
+}
+
+func TestCatalogServesAsPayloadRegistry(t *testing.T) {
+	var registry eventbus.PayloadRegistry = catalog.Default()
+
+	payload, ok := registry.PayloadFor(sharedevents.PointsAwardedV1)
+	if !ok {
+		t.Fatalf("expected payload for %s", sharedevents.PointsAwardedV1)
+	}
+	if _, ok := payload.(*sharedevents.PointsAwardedPayloadV1); !ok {
+		t.Fatalf("unexpected payload type %T", payload)
+	}
+	if _, ok := registry.PayloadFor("unknown.topic.v1"); ok {
+		t.Fatal("expected no payload for unknown topic")
+	}
+}
+
+func TestStrictPayloadValidationRejectsMissingRequiredFields(t *testing.T) {
+	called := false
+	handler := eventbus.ValidatePayloads(catalog.Default(), eventbus.PayloadValidationStrict, slog.New(slog.NewTextHandler(io.Discard, nil)), nil)(
+		func(*message.Message) ([]*message.Message, error) {
+			called = true
+			return nil, nil
+		},
+	)
+
+	// RoundDeletedPayloadV1 requires discord_message_id.
+	msg := message.NewMessage("id", []byte(`{"guild_id":"guild-1","round_id":"00000000-0000-0000-0000-000000000001"}`))
+	msg.Metadata.Set(eventbus.SubjectMetadataKey, roundevents.RoundDeletedV1)
+	if _, err := handler(msg); !errors.Is(err, eventbus.ErrPayloadValidation) {
+		t.Fatalf("expected payload validation error, got %v", err)
+	}
+	if called {
+		t.Fatal("expected handler not to run for an incomplete payload")
+	}
+
+	complete, err := json.Marshal(roundevents.RoundDeletedPayloadV1{GuildID: "guild-1", EventMessageID: "msg-1"})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	msg = message.NewMessage("id", complete)
+	msg.Metadata.Set(eventbus.SubjectMetadataKey, roundevents.RoundDeletedV1)
+	if _, err := handler(msg); err != nil || !called {
+		t.Fatalf("expected a complete payload to reach the handler, got %v", err)
+	}
+}
+
+// TestProducerPayloadsMatchRegisteredTypes marshals what each domain's producer
+// actually sends and checks it round-trips through the type that domain registers.
+func TestProducerPayloadsMatchRegisteredTypes(t *testing.T) {
+	updatedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		domain  string
+		subject string
+		payload any
+	}{
+		{
+			domain:  "leaderboard",
+			subject: leaderboardevents.TagUpdateForScheduledRoundsV1,
+			payload: &leaderboardevents.TagUpdateForScheduledRoundsPayloadV1{
+				GuildID:   "guild-1",
+				RoundID:   sharedtypes.RoundID{1},
+				UpdatedAt: updatedAt,
+			},
+		},
+		{
+			domain:  "shared",
+			subject: sharedevents.SyncRoundsTagRequestV1,
+			payload: &sharedevents.SyncRoundsTagRequestPayloadV1{
+				GuildID:     "guild-1",
+				ChangedTags: map[sharedtypes.DiscordID]sharedtypes.TagNumber{"user-1": 7},
+				UpdatedAt:   updatedAt,
+				Source:      sharedtypes.ServiceUpdateSourceProcessScores,
+			},
+		},
+	}
+
+	domains := make(map[string]catalog.Domain)
+	for _, d := range catalog.DefaultDomains() {
+		domains[d.Name] = d
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.domain+"/"+tt.subject, func(t *testing.T) {
+			info, ok := domains[tt.domain].Registry()[tt.subject]
+			if !ok {
+				t.Fatalf("%s does not register %s", tt.domain, tt.subject)
+			}
+			payloadType := reflect.TypeOf(info.Payload)
+			if payloadType != reflect.TypeOf(tt.payload) {
+				t.Fatalf("%s registers %v for %s, but its producer sends %T", tt.domain, payloadType, tt.subject, tt.payload)
+			}
+
+			data, err := json.Marshal(tt.payload)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			decoded := reflect.New(payloadType.Elem()).Interface()
+			dec := json.NewDecoder(bytes.NewReader(data))
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(decoded); err != nil {
+				t.Fatalf("producer payload %s does not decode into %v: %v", data, payloadType, err)
+			}
+			roundTrip, err := json.Marshal(decoded)
+			if err != nil {
+				t.Fatalf("marshal decoded: %v", err)
+			}
+			if !bytes.Equal(data, roundTrip) {
+				t.Fatalf("payload changed through %v:\n got %s\nwant %s", payloadType, roundTrip, data)
+			}
+		})
+	}
+}
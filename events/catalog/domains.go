@@ -0,0 +1,52 @@
+package catalog
+
+import (
+	authevents "github.com/Black-And-White-Club/frolf-bot-shared/events/auth"
+	clubevents "github.com/Black-And-White-Club/frolf-bot-shared/events/club"
+	discordevents "github.com/Black-And-White-Club/frolf-bot-shared/events/discord"
+	discordleaderboardevents "github.com/Black-And-White-Club/frolf-bot-shared/events/discord/leaderboard"
+	discordroundevents "github.com/Black-And-White-Club/frolf-bot-shared/events/discord/round"
+	discordscoreevents "github.com/Black-And-White-Club/frolf-bot-shared/events/discord/score"
+	discorduserevents "github.com/Black-And-White-Club/frolf-bot-shared/events/discord/user"
+	guildevents "github.com/Black-And-White-Club/frolf-bot-shared/events/guild"
+	leaderboardevents "github.com/Black-And-White-Club/frolf-bot-shared/events/leaderboard"
+	roundevents "github.com/Black-And-White-Club/frolf-bot-shared/events/round"
+	scoreevents "github.com/Black-And-White-Club/frolf-bot-shared/events/score"
+	sharedevents "github.com/Black-And-White-Club/frolf-bot-shared/events/shared"
+	userevents "github.com/Black-And-White-Club/frolf-bot-shared/events/user"
+)
+
+// Domain is a named event registry.
+type Domain struct {
+	Name     string
+	Registry func() map[string]sharedevents.EventInfo
+}
+
+// DefaultDomains returns every GetV1Registry in events/*. Order matters: when a
+// subject is registered by several domains, the first one provides its EventInfo.
+func DefaultDomains() []Domain {
+	return []Domain{
+		{Name: "shared", Registry: sharedevents.GetV1Registry},
+		{Name: "auth", Registry: authevents.GetV1Registry},
+		{Name: "club", Registry: clubevents.GetV1Registry},
+		{Name: "guild", Registry: guildevents.GetV1Registry},
+		{Name: "leaderboard", Registry: leaderboardevents.GetV1Registry},
+		{Name: "round", Registry: roundevents.GetV1Registry},
+		{Name: "score", Registry: scoreevents.GetV1Registry},
+		{Name: "user", Registry: userevents.GetV1Registry},
+		{Name: "discord", Registry: discordevents.GetV1Registry},
+		{Name: "discord.leaderboard", Registry: discordleaderboardevents.GetV1Registry},
+		{Name: "discord.round", Registry: discordroundevents.GetV1Registry},
+		{Name: "discord.score", Registry: discordscoreevents.GetV1Registry},
+		{Name: "discord.user", Registry: discorduserevents.GetV1Registry},
+	}
+}
+
+// knownPayloadConflicts lists live subjects that two registries publish with
+// different payload types, with the reason they are tolerated. Validate still
+// reports them as IssuePayloadConflict, marked Known so they do not fail startup.
+// Moving a producer to its own subject goes through a versioning dual-publish
+// migration, after which the entry is removed.
+var knownPayloadConflicts = map[string]string{
+	sharedevents.SyncRoundsTagRequestV1: "leaderboard publishes TagUpdateForScheduledRoundsPayloadV1 and the scheduled-rounds sync trigger SyncRoundsTagRequestPayloadV1 on the same subject",
+}
@@ -3,6 +3,7 @@ package observability
 
 import (
 	"log/slog"
+	"strings"
 
 	"go.opentelemetry.io/otel/attribute"
 )
@@ -12,25 +13,49 @@ type Config struct {
 	Environment string
 	Version     string
 
-	LokiURL         string
-	MetricsAddress  string
+	LokiURL        string
+	MetricsAddress string
+	// MetricsExporter selects how metrics are exported ("otlp" or "prometheus").
+	// Default is "otlp" when empty, pushing to OTLPEndpoint or MetricsAddress.
+	// "prometheus" serves a scrape endpoint instead, so no collector is needed.
+	MetricsExporter string
+	// PrometheusAddress is the scrape endpoint's listen address (default ":9464").
+	PrometheusAddress string
+	// PrometheusPath is the scrape endpoint's path (default "/metrics").
+	PrometheusPath  string
 	TempoEndpoint   string
 	TempoInsecure   bool
 	TempoSampleRate float64
 	OTLPEndpoint    string
-	// OTLPInsecure enables plaintext OTLP gRPC transport. Keep false in non-local environments.
+	// OTLPInsecure enables plaintext OTLP transport (gRPC or HTTP). Keep false in non-local environments.
 	OTLPInsecure bool
 	// OTLPTransport selects the OTLP transport ("grpc" or "http").
 	// Default is "grpc" when empty. Value is case-insensitive.
-	// Note: current build supports gRPC only; "http" will return an error.
 	OTLPTransport string
-	LogsEnabled   bool
+	// OTLPHeaders are sent with every OTLP export, e.g. collector auth headers.
+	OTLPHeaders map[string]string
+	// OTLPCompression selects OTLP export compression ("gzip" or "none").
+	// Default is "none" when empty. Value is case-insensitive.
+	OTLPCompression string
+	// OTLP/HTTP URL path overrides for collectors behind path-routing proxies.
+	// Empty uses the OTLP defaults (/v1/traces, /v1/metrics, /v1/logs).
+	OTLPTracesPath  string
+	OTLPMetricsPath string
+	OTLPLogsPath    string
+	LogsEnabled     bool
 
 	// OTEL log batching (optional; zeros use sensible defaults)
 	LogBatchMaxQueueSize       int // e.g., 256 dev, 2048 prod
 	LogBatchMaxExportBatchSize int // e.g., 64 dev, 512 prod
 	LogBatchTimeoutSeconds     int // e.g., 2 dev, 5 prod
 	LogExportTimeoutSeconds    int // e.g., 3 dev, 10 prod
+
+	// TraceSampleRules override TempoSampleRate for matching spans. Rules are checked in
+	// order against each span's messaging topic and name; the first match sets its rate.
+	TraceSampleRules []TraceSampleRule
+	// TraceErrorBiased also exports spans that end with an error status from traces the
+	// sampler dropped, at the cost of recording every span.
+	TraceErrorBiased bool
 }
 
 func (c Config) LokiEnabled() bool {
@@ -42,7 +67,11 @@ func (c Config) TracingEnabled() bool {
 }
 
 func (c Config) MetricsEnabled() bool {
-	return c.MetricsAddress != "" || c.OTLPEndpoint != ""
+	return c.MetricsAddress != "" || c.OTLPEndpoint != "" || c.PrometheusEnabled()
+}
+
+func (c Config) PrometheusEnabled() bool {
+	return strings.EqualFold(strings.TrimSpace(c.MetricsExporter), "prometheus")
 }
 
 func parseLogLevel(c Config) slog.Level {
@@ -16,7 +16,11 @@ func Init(ctx context.Context, cfg Config) (*Observability, error) {
 		return nil, err
 	}
 
-	registry := NewRegistry(provider, cfg)
+	// Metrics that fail to register fall back to no-ops; report them without failing startup.
+	registry, err := NewRegistry(provider, cfg)
+	if err != nil {
+		provider.Logger.WarnContext(ctx, "Some metrics are disabled", "error", err)
+	}
 
 	return &Observability{
 		Provider: provider,
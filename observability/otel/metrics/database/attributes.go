@@ -0,0 +1,31 @@
+// observability/otel/metrics/database/attributes.go
+package databasemetrics
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Common attributes
+func operationAttr(operation string) attribute.KeyValue {
+	return attribute.String("operation", operation)
+}
+
+func successAttr(success bool) attribute.KeyValue {
+	return attribute.Bool("success", success)
+}
+
+func queryTypeAttr(queryType string) attribute.KeyValue {
+	return attribute.String("query_type", queryType)
+}
+
+func stateAttr(state string) attribute.KeyValue {
+	return attribute.String("state", state)
+}
+
+// Combined attribute sets
+func operationSuccessAttrs(operation string, success bool) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		operationAttr(operation),
+		successAttr(success),
+	}
+}
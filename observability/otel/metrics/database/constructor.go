@@ -0,0 +1,78 @@
+// observability/otel/metrics/database/constructor.go
+package databasemetrics
+
+import "go.opentelemetry.io/otel/metric"
+
+// NewDatabaseMetrics creates a new DatabaseMetrics implementation using OpenTelemetry.
+// It requires an OTEL Meter instance and a prefix for metric names.
+func NewDatabaseMetrics(meter metric.Meter, prefix string) (DatabaseMetrics, error) {
+	// Helper function to create metric names with prefix
+	metricName := func(name string) string {
+		if prefix != "" {
+			return prefix + "_db_" + name
+		}
+		return "db_" + name
+	}
+
+	var err error
+	m := &databaseMetrics{meter: meter}
+
+	// Query Metrics
+	m.queryDurationHistogram, err = meter.Float64Histogram(
+		metricName("query_duration_seconds"),
+		metric.WithDescription("Time taken to execute database queries"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m.queryResultCounter, err = meter.Int64Counter(
+		metricName("query_results_total"),
+		metric.WithDescription("Number of database query results, partitioned by operation and success"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m.queryTypeCounter, err = meter.Int64Counter(
+		metricName("query_types_total"),
+		metric.WithDescription("Number of queries executed, partitioned by query type"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Connection Pool Metrics
+	m.connectionPoolGauge, err = meter.Int64Gauge(
+		metricName("connection_pool_status"),
+		metric.WithDescription("Current number of database connections, partitioned by state (open, idle, used)"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Transaction Metrics
+	m.transactionCounter, err = meter.Int64Counter(
+		metricName("transactions_total"),
+		metric.WithDescription("Number of database transactions, partitioned by operation and success"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m.transactionDurationHistogram, err = meter.Float64Histogram(
+		metricName("transaction_duration_seconds"),
+		metric.WithDescription("Time taken to execute database transactions"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
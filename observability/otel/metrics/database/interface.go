@@ -0,0 +1,21 @@
+// observability/otel/metrics/database/interface.go
+package databasemetrics
+
+import (
+	"context"
+	"time"
+)
+
+// DatabaseMetrics defines metrics specific to database operations using OpenTelemetry
+type DatabaseMetrics interface {
+	// Query metrics
+	RecordQueryDuration(ctx context.Context, duration time.Duration)
+	RecordQueryResult(ctx context.Context, operation string, success bool)
+	RecordQueryType(ctx context.Context, queryType string)
+
+	// Connection pool metrics
+	RecordConnectionPoolStatus(ctx context.Context, open, idle, used int)
+
+	// Transaction metrics
+	RecordTransaction(ctx context.Context, operation string, success bool, duration time.Duration)
+}
@@ -1,145 +1,37 @@
-// observability/prometheus/database/metrics.go
+// observability/otel/metrics/database/metrics.go
 package databasemetrics
 
 import (
-	"fmt"
+	"context"
+	"time"
 
-	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/metric"
 )
 
-// DatabaseMetrics defines metrics specific to database operations
-type DatabaseMetrics interface {
-	// Record query duration
-	RecordQueryDuration(seconds float64)
-
-	// Record query result
-	RecordQueryResult(operation string, success bool)
-
-	// Record connection pool status
-	RecordConnectionPoolStatus(open, idle, used int)
-
-	// Record transaction operations
-	RecordTransaction(operation string, success bool, durationSeconds float64)
-
-	// Record query type execution
-	RecordQueryType(queryType string)
-}
-
-// databaseMetrics implements DatabaseMetrics
-type databaseMetrics struct {
-	queryDurationHistogram       *prometheus.Histogram
-	queryResultCounter           *prometheus.CounterVec
-	connectionPoolGauge          *prometheus.GaugeVec
-	transactionCounter           *prometheus.CounterVec
-	transactionDurationHistogram *prometheus.Histogram
-	queryTypeCounter             *prometheus.CounterVec
-}
-
-// NewDatabaseMetrics creates a new DatabaseMetrics implementation
-func NewDatabaseMetrics(registry *prometheus.Registry, prefix string) DatabaseMetrics {
-	queryDurationHistogram := prometheus.NewHistogram(
-		prometheus.HistogramOpts{
-			Namespace: prefix,
-			Subsystem: "db",
-			Name:      "query_duration_seconds",
-			Help:      "Time taken to execute database queries.",
-			Buckets:   prometheus.DefBuckets,
-		},
-	)
-
-	queryResultCounter := prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Namespace: prefix,
-			Subsystem: "db",
-			Name:      "query_results_total",
-			Help:      "Total number of database query results, partitioned by operation and success.",
-		},
-		[]string{"operation", "success"},
-	)
-
-	connectionPoolGauge := prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: prefix,
-			Subsystem: "db",
-			Name:      "connection_pool_status",
-			Help:      "Current status of database connection pool.",
-		},
-		[]string{"state"}, // open, idle, used
-	)
-
-	transactionCounter := prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Namespace: prefix,
-			Subsystem: "db",
-			Name:      "transactions_total",
-			Help:      "Total number of database transactions, partitioned by operation and success.",
-		},
-		[]string{"operation", "success"},
-	)
-
-	transactionDurationHistogram := prometheus.NewHistogram(
-		prometheus.HistogramOpts{
-			Namespace: prefix,
-			Subsystem: "db",
-			Name:      "transaction_duration_seconds",
-			Help:      "Time taken to execute database transactions.",
-			Buckets:   prometheus.DefBuckets,
-		},
-	)
-
-	queryTypeCounter := prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Namespace: prefix,
-			Subsystem: "db",
-			Name:      "query_types_total",
-			Help:      "Total number of queries by type, partitioned by query type.",
-		},
-		[]string{"query_type"}, // select, insert, update, delete, etc.
-	)
-
-	registry.MustRegister(
-		queryDurationHistogram,
-		queryResultCounter,
-		connectionPoolGauge,
-		transactionCounter,
-		transactionDurationHistogram,
-		queryTypeCounter,
-	)
-
-	return &databaseMetrics{
-		queryDurationHistogram:       &queryDurationHistogram,
-		queryResultCounter:           queryResultCounter,
-		connectionPoolGauge:          connectionPoolGauge,
-		transactionCounter:           transactionCounter,
-		transactionDurationHistogram: &transactionDurationHistogram,
-		queryTypeCounter:             queryTypeCounter,
-	}
-}
-
 // RecordQueryDuration records the time taken for a database query
-func (m *databaseMetrics) RecordQueryDuration(seconds float64) {
-	(*m.queryDurationHistogram).Observe(seconds)
+func (m *databaseMetrics) RecordQueryDuration(ctx context.Context, duration time.Duration) {
+	m.queryDurationHistogram.Record(ctx, duration.Seconds())
 }
 
 // RecordQueryResult records a database query result
-func (m *databaseMetrics) RecordQueryResult(operation string, success bool) {
-	m.queryResultCounter.WithLabelValues(operation, fmt.Sprintf("%t", success)).Inc()
+func (m *databaseMetrics) RecordQueryResult(ctx context.Context, operation string, success bool) {
+	m.queryResultCounter.Add(ctx, 1, metric.WithAttributes(operationSuccessAttrs(operation, success)...))
 }
 
-// RecordConnectionPoolStatus records the current status of the connection pool
-func (m *databaseMetrics) RecordConnectionPoolStatus(open, idle, used int) {
-	m.connectionPoolGauge.WithLabelValues("open").Set(float64(open))
-	m.connectionPoolGauge.WithLabelValues("idle").Set(float64(idle))
-	m.connectionPoolGauge.WithLabelValues("used").Set(float64(used))
+// RecordQueryType records execution of a specific query type
+func (m *databaseMetrics) RecordQueryType(ctx context.Context, queryType string) {
+	m.queryTypeCounter.Add(ctx, 1, metric.WithAttributes(queryTypeAttr(queryType)))
 }
 
-// RecordTransaction records a database transaction operation
-func (m *databaseMetrics) RecordTransaction(operation string, success bool, durationSeconds float64) {
-	m.transactionCounter.WithLabelValues(operation, fmt.Sprintf("%t", success)).Inc()
-	(*m.transactionDurationHistogram).Observe(durationSeconds)
+// RecordConnectionPoolStatus records the current status of the connection pool
+func (m *databaseMetrics) RecordConnectionPoolStatus(ctx context.Context, open, idle, used int) {
+	m.connectionPoolGauge.Record(ctx, int64(open), metric.WithAttributes(stateAttr("open")))
+	m.connectionPoolGauge.Record(ctx, int64(idle), metric.WithAttributes(stateAttr("idle")))
+	m.connectionPoolGauge.Record(ctx, int64(used), metric.WithAttributes(stateAttr("used")))
 }
 
-// RecordQueryType records execution of a specific query type
-func (m *databaseMetrics) RecordQueryType(queryType string) {
-	m.queryTypeCounter.WithLabelValues(queryType).Inc()
+// RecordTransaction records a database transaction operation
+func (m *databaseMetrics) RecordTransaction(ctx context.Context, operation string, success bool, duration time.Duration) {
+	m.transactionCounter.Add(ctx, 1, metric.WithAttributes(operationSuccessAttrs(operation, success)...))
+	m.transactionDurationHistogram.Record(ctx, duration.Seconds(), metric.WithAttributes(operationAttr(operation)))
 }
@@ -0,0 +1,35 @@
+// observability/otel/metrics/database/noop.go
+package databasemetrics
+
+import (
+	"context"
+	"time"
+)
+
+// NoOpMetrics is a metrics collector that does nothing. Useful for unit tests.
+type NoOpMetrics struct{}
+
+// NewNoop returns a no-operation implementation of DatabaseMetrics
+func NewNoop() DatabaseMetrics {
+	return &NoOpMetrics{}
+}
+
+// RecordQueryDuration does nothing
+func (n *NoOpMetrics) RecordQueryDuration(ctx context.Context, duration time.Duration) {
+}
+
+// RecordQueryResult does nothing
+func (n *NoOpMetrics) RecordQueryResult(ctx context.Context, operation string, success bool) {
+}
+
+// RecordQueryType does nothing
+func (n *NoOpMetrics) RecordQueryType(ctx context.Context, queryType string) {
+}
+
+// RecordConnectionPoolStatus does nothing
+func (n *NoOpMetrics) RecordConnectionPoolStatus(ctx context.Context, open, idle, used int) {
+}
+
+// RecordTransaction does nothing
+func (n *NoOpMetrics) RecordTransaction(ctx context.Context, operation string, success bool, duration time.Duration) {
+}
@@ -0,0 +1,21 @@
+// observability/otel/metrics/database/struct.go
+package databasemetrics
+
+import "go.opentelemetry.io/otel/metric"
+
+// databaseMetrics implements DatabaseMetrics using OpenTelemetry
+type databaseMetrics struct {
+	meter metric.Meter // OTEL Meter
+
+	// Query Metrics
+	queryDurationHistogram metric.Float64Histogram // Seconds
+	queryResultCounter     metric.Int64Counter
+	queryTypeCounter       metric.Int64Counter
+
+	// Connection Pool Metrics
+	connectionPoolGauge metric.Int64Gauge
+
+	// Transaction Metrics
+	transactionCounter           metric.Int64Counter
+	transactionDurationHistogram metric.Float64Histogram // Seconds
+}
@@ -10,6 +10,22 @@ func topicAttr(topic string) attribute.KeyValue {
 	return attribute.String("topic", topic)
 }
 
+func consumerAttr(consumer string) attribute.KeyValue {
+	return attribute.String("consumer", consumer)
+}
+
+func directionAttr(direction string) attribute.KeyValue {
+	return attribute.String("direction", direction)
+}
+
+func outcomeAttr(outcome string) attribute.KeyValue {
+	return attribute.String("outcome", outcome)
+}
+
+func stateAttr(state string) attribute.KeyValue {
+	return attribute.String("state", state)
+}
+
 func successAttr(success bool) attribute.KeyValue {
 	return attribute.Bool("success", success)
 }
@@ -25,3 +41,25 @@ func topicSuccessAttrs(topic string, success bool) []attribute.KeyValue {
 		successAttr(success),
 	}
 }
+
+func topicConsumerAttrs(topic, consumer string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		topicAttr(topic),
+		consumerAttr(consumer),
+	}
+}
+
+func topicConsumerOutcomeAttrs(topic, consumer, outcome string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		topicAttr(topic),
+		consumerAttr(consumer),
+		outcomeAttr(outcome),
+	}
+}
+
+func topicDirectionAttrs(topic, direction string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		topicAttr(topic),
+		directionAttr(direction),
+	}
+}
@@ -74,5 +74,164 @@ func NewEventBusMetrics(meter metric.Meter, prefix string) (EventBusMetrics, err
 		return nil, err
 	}
 
+	// Consumer-side Deduplication Metrics
+	m.duplicateMessageCounter, err = meter.Int64Counter(
+		metricName("messages_duplicate_total"),
+		metric.WithDescription("Number of redelivered messages skipped because they were already processed, partitioned by topic and consumer"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Payload Validation Metrics
+	m.payloadValidationFailureCounter, err = meter.Int64Counter(
+		metricName("payload_validation_failures_total"),
+		metric.WithDescription("Number of payloads rejected for not matching the registered type, partitioned by topic and direction"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m.payloadValidationWarningCounter, err = meter.Int64Counter(
+		metricName("payload_validation_warnings_total"),
+		metric.WithDescription("Number of payloads accepted with unknown or missing fields because their type is not strict, partitioned by topic and direction"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Publish Retry Metrics
+	m.publishRetryCounter, err = meter.Int64Counter(
+		metricName("publish_retries_total"),
+		metric.WithDescription("Number of publish attempts retried after a retryable error, partitioned by topic"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Consumer Lag Metrics
+	m.consumerPendingGauge, err = meter.Int64Gauge(
+		metricName("consumer_pending_messages"),
+		metric.WithDescription("Messages in the stream not yet delivered to the consumer, partitioned by topic and consumer"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m.consumerAckPendingGauge, err = meter.Int64Gauge(
+		metricName("consumer_ack_pending_messages"),
+		metric.WithDescription("Messages delivered to the consumer and awaiting acknowledgment, partitioned by topic and consumer"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m.consumerMaxAckPendingGauge, err = meter.Int64Gauge(
+		metricName("consumer_max_ack_pending"),
+		metric.WithDescription("Ack-pending ceiling past which JetStream stops delivering to the consumer, partitioned by topic and consumer"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Delivery Metrics
+	m.redeliveryCounter, err = meter.Int64Counter(
+		metricName("messages_redelivered_total"),
+		metric.WithDescription("Number of deliveries beyond the first, partitioned by topic and consumer"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m.settlementCounter, err = meter.Int64Counter(
+		metricName("messages_settled_total"),
+		metric.WithDescription("Number of delivered messages settled, partitioned by topic, consumer and outcome"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m.ackLatencyHistogram, err = meter.Float64Histogram(
+		metricName("message_ack_latency_seconds"),
+		metric.WithDescription("Time from a message being stored in the stream to its acknowledgment, partitioned by topic and consumer"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m.ackHeartbeatCounter, err = meter.Int64Counter(
+		metricName("ack_heartbeats_total"),
+		metric.WithDescription("Number of in-progress ack deadline extensions for slow handlers, partitioned by topic and consumer"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m.inFlightUpDownCounter, err = meter.Int64UpDownCounter(
+		metricName("messages_in_flight"),
+		metric.WithDescription("Messages handed to handlers and not yet settled, partitioned by topic and consumer"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m.ackSemaphoreSaturations, err = meter.Int64Counter(
+		metricName("ack_semaphore_saturated_total"),
+		metric.WithDescription("Number of times the subscriber waited for a free in-flight slot, partitioned by topic and consumer"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Publish Circuit Breaker and Spool Metrics
+	m.circuitStateChangeCounter, err = meter.Int64Counter(
+		metricName("publish_circuit_state_changes_total"),
+		metric.WithDescription("Number of publish circuit breaker transitions, partitioned by the state entered"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m.spooledMessageCounter, err = meter.Int64Counter(
+		metricName("publish_spooled_total"),
+		metric.WithDescription("Number of messages written to the local publish spool while JetStream was unavailable, partitioned by topic"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m.spoolDroppedCounter, err = meter.Int64Counter(
+		metricName("publish_spool_dropped_total"),
+		metric.WithDescription("Number of messages rejected because the publish spool was full, partitioned by topic"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m.spoolDepthGauge, err = meter.Int64Gauge(
+		metricName("publish_spool_depth"),
+		metric.WithDescription("Messages waiting in the local publish spool"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	return m, nil
 }
@@ -19,4 +19,34 @@ type EventBusMetrics interface {
 	// Subscription metrics
 	RecordMessageSubscribe(ctx context.Context, topic string)
 	RecordMessageSubscribeError(ctx context.Context, topic string)
+
+	// Consumer-side deduplication metrics
+	RecordDuplicateMessage(ctx context.Context, topic string, consumer string)
+
+	// Payload validation metrics; direction is "publish" or "consume"
+	RecordPayloadValidationFailure(ctx context.Context, topic string, direction string)
+	RecordPayloadValidationWarning(ctx context.Context, topic string, direction string)
+
+	// Publish retry metrics
+	RecordPublishRetry(ctx context.Context, topic string)
+
+	// Consumer lag metrics, polled from JetStream consumer info
+	RecordConsumerLag(ctx context.Context, topic string, consumer string, pending uint64, ackPending uint64)
+	RecordConsumerMaxAckPending(ctx context.Context, topic string, consumer string, limit int)
+
+	// Delivery metrics; outcome is "ack", "nak", "term" or "timeout"
+	RecordRedelivery(ctx context.Context, topic string, consumer string, deliveryCount uint64)
+	RecordMessageSettlement(ctx context.Context, topic string, consumer string, outcome string)
+	RecordAckLatency(ctx context.Context, topic string, consumer string, latency time.Duration)
+	RecordAckHeartbeat(ctx context.Context, topic string, consumer string)
+
+	// Subscriber concurrency metrics
+	RecordInFlightMessages(ctx context.Context, topic string, consumer string, delta int64)
+	RecordAckSemaphoreSaturation(ctx context.Context, topic string, consumer string)
+
+	// Publish circuit breaker and spool metrics; state is "closed", "open" or "half_open"
+	RecordCircuitStateChange(ctx context.Context, state string)
+	RecordSpooledMessage(ctx context.Context, topic string)
+	RecordSpoolDropped(ctx context.Context, topic string)
+	RecordSpoolDepth(ctx context.Context, depth int64)
 }
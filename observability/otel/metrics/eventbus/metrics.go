@@ -37,3 +37,89 @@ func (m *eventBusMetrics) RecordMessageSubscribe(ctx context.Context, topic stri
 func (m *eventBusMetrics) RecordMessageSubscribeError(ctx context.Context, topic string) {
 	m.messageSubscribeErrorCounter.Add(ctx, 1, metric.WithAttributes(topicAttrs(topic)))
 }
+
+// RecordDuplicateMessage records a redelivered message that was skipped as already processed
+func (m *eventBusMetrics) RecordDuplicateMessage(ctx context.Context, topic string, consumer string) {
+	m.duplicateMessageCounter.Add(ctx, 1, metric.WithAttributes(topicConsumerAttrs(topic, consumer)...))
+}
+
+// RecordPayloadValidationFailure records a payload rejected by registry validation
+func (m *eventBusMetrics) RecordPayloadValidationFailure(ctx context.Context, topic string, direction string) {
+	m.payloadValidationFailureCounter.Add(ctx, 1, metric.WithAttributes(topicDirectionAttrs(topic, direction)...))
+}
+
+// RecordPayloadValidationWarning records a payload let through despite unknown or missing fields
+func (m *eventBusMetrics) RecordPayloadValidationWarning(ctx context.Context, topic string, direction string) {
+	m.payloadValidationWarningCounter.Add(ctx, 1, metric.WithAttributes(topicDirectionAttrs(topic, direction)...))
+}
+
+// RecordPublishRetry records a publish attempt retried after a retryable error
+func (m *eventBusMetrics) RecordPublishRetry(ctx context.Context, topic string) {
+	m.publishRetryCounter.Add(ctx, 1, metric.WithAttributes(topicAttrs(topic)))
+}
+
+// RecordConsumerLag records the pending and ack-pending counts of a consumer
+func (m *eventBusMetrics) RecordConsumerLag(ctx context.Context, topic string, consumer string, pending uint64, ackPending uint64) {
+	attrs := metric.WithAttributes(topicConsumerAttrs(topic, consumer)...)
+	m.consumerPendingGauge.Record(ctx, int64(pending), attrs)
+	m.consumerAckPendingGauge.Record(ctx, int64(ackPending), attrs)
+}
+
+// RecordConsumerMaxAckPending records the ack-pending ceiling of a consumer, past which
+// JetStream stops delivering to it
+func (m *eventBusMetrics) RecordConsumerMaxAckPending(ctx context.Context, topic string, consumer string, limit int) {
+	m.consumerMaxAckPendingGauge.Record(ctx, int64(limit), metric.WithAttributes(topicConsumerAttrs(topic, consumer)...))
+}
+
+// RecordRedelivery records a delivery beyond the first
+func (m *eventBusMetrics) RecordRedelivery(ctx context.Context, topic string, consumer string, deliveryCount uint64) {
+	if deliveryCount <= 1 {
+		return
+	}
+	m.redeliveryCounter.Add(ctx, 1, metric.WithAttributes(topicConsumerAttrs(topic, consumer)...))
+}
+
+// RecordMessageSettlement records how a delivered message was settled
+func (m *eventBusMetrics) RecordMessageSettlement(ctx context.Context, topic string, consumer string, outcome string) {
+	m.settlementCounter.Add(ctx, 1, metric.WithAttributes(topicConsumerOutcomeAttrs(topic, consumer, outcome)...))
+}
+
+// RecordAckLatency records the time from a message being stored to its acknowledgment
+func (m *eventBusMetrics) RecordAckLatency(ctx context.Context, topic string, consumer string, latency time.Duration) {
+	m.ackLatencyHistogram.Record(ctx, latency.Seconds(), metric.WithAttributes(topicConsumerAttrs(topic, consumer)...))
+}
+
+// RecordAckHeartbeat records an in-progress ack deadline extension
+func (m *eventBusMetrics) RecordAckHeartbeat(ctx context.Context, topic string, consumer string) {
+	m.ackHeartbeatCounter.Add(ctx, 1, metric.WithAttributes(topicConsumerAttrs(topic, consumer)...))
+}
+
+// RecordInFlightMessages adjusts the number of messages awaiting settlement
+func (m *eventBusMetrics) RecordInFlightMessages(ctx context.Context, topic string, consumer string, delta int64) {
+	m.inFlightUpDownCounter.Add(ctx, delta, metric.WithAttributes(topicConsumerAttrs(topic, consumer)...))
+}
+
+// RecordAckSemaphoreSaturation records the subscriber waiting for a free in-flight slot
+func (m *eventBusMetrics) RecordAckSemaphoreSaturation(ctx context.Context, topic string, consumer string) {
+	m.ackSemaphoreSaturations.Add(ctx, 1, metric.WithAttributes(topicConsumerAttrs(topic, consumer)...))
+}
+
+// RecordCircuitStateChange records the publish circuit breaker entering state
+func (m *eventBusMetrics) RecordCircuitStateChange(ctx context.Context, state string) {
+	m.circuitStateChangeCounter.Add(ctx, 1, metric.WithAttributes(stateAttr(state)))
+}
+
+// RecordSpooledMessage records a message written to the publish spool
+func (m *eventBusMetrics) RecordSpooledMessage(ctx context.Context, topic string) {
+	m.spooledMessageCounter.Add(ctx, 1, metric.WithAttributes(topicAttrs(topic)))
+}
+
+// RecordSpoolDropped records a message rejected because the publish spool was full
+func (m *eventBusMetrics) RecordSpoolDropped(ctx context.Context, topic string) {
+	m.spoolDroppedCounter.Add(ctx, 1, metric.WithAttributes(topicAttrs(topic)))
+}
+
+// RecordSpoolDepth records the number of messages waiting in the publish spool
+func (m *eventBusMetrics) RecordSpoolDepth(ctx context.Context, depth int64) {
+	m.spoolDepthGauge.Record(ctx, depth)
+}
@@ -37,3 +37,67 @@ func (n *NoOpMetrics) RecordMessageSubscribe(ctx context.Context, topic string)
 // RecordMessageSubscribeError does nothing
 func (n *NoOpMetrics) RecordMessageSubscribeError(ctx context.Context, topic string) {
 }
+
+// RecordDuplicateMessage does nothing
+func (n *NoOpMetrics) RecordDuplicateMessage(ctx context.Context, topic string, consumer string) {
+}
+
+// RecordPayloadValidationFailure does nothing
+func (n *NoOpMetrics) RecordPayloadValidationFailure(ctx context.Context, topic string, direction string) {
+}
+
+// RecordPayloadValidationWarning does nothing
+func (n *NoOpMetrics) RecordPayloadValidationWarning(ctx context.Context, topic string, direction string) {
+}
+
+// RecordPublishRetry does nothing
+func (n *NoOpMetrics) RecordPublishRetry(ctx context.Context, topic string) {
+}
+
+// RecordConsumerLag does nothing
+func (n *NoOpMetrics) RecordConsumerLag(ctx context.Context, topic string, consumer string, pending uint64, ackPending uint64) {
+}
+
+// RecordConsumerMaxAckPending does nothing
+func (n *NoOpMetrics) RecordConsumerMaxAckPending(ctx context.Context, topic string, consumer string, limit int) {
+}
+
+// RecordRedelivery does nothing
+func (n *NoOpMetrics) RecordRedelivery(ctx context.Context, topic string, consumer string, deliveryCount uint64) {
+}
+
+// RecordMessageSettlement does nothing
+func (n *NoOpMetrics) RecordMessageSettlement(ctx context.Context, topic string, consumer string, outcome string) {
+}
+
+// RecordAckLatency does nothing
+func (n *NoOpMetrics) RecordAckLatency(ctx context.Context, topic string, consumer string, latency time.Duration) {
+}
+
+// RecordAckHeartbeat does nothing
+func (n *NoOpMetrics) RecordAckHeartbeat(ctx context.Context, topic string, consumer string) {
+}
+
+// RecordInFlightMessages does nothing
+func (n *NoOpMetrics) RecordInFlightMessages(ctx context.Context, topic string, consumer string, delta int64) {
+}
+
+// RecordAckSemaphoreSaturation does nothing
+func (n *NoOpMetrics) RecordAckSemaphoreSaturation(ctx context.Context, topic string, consumer string) {
+}
+
+// RecordCircuitStateChange does nothing
+func (n *NoOpMetrics) RecordCircuitStateChange(ctx context.Context, state string) {
+}
+
+// RecordSpooledMessage does nothing
+func (n *NoOpMetrics) RecordSpooledMessage(ctx context.Context, topic string) {
+}
+
+// RecordSpoolDropped does nothing
+func (n *NoOpMetrics) RecordSpoolDropped(ctx context.Context, topic string) {
+}
+
+// RecordSpoolDepth does nothing
+func (n *NoOpMetrics) RecordSpoolDepth(ctx context.Context, depth int64) {
+}
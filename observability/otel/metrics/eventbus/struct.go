@@ -18,4 +18,33 @@ type eventBusMetrics struct {
 	// Message Subscribe Metrics
 	messageSubscribeCounter      metric.Int64Counter
 	messageSubscribeErrorCounter metric.Int64Counter
+
+	// Consumer-side Deduplication Metrics
+	duplicateMessageCounter metric.Int64Counter
+
+	// Payload Validation Metrics
+	payloadValidationFailureCounter metric.Int64Counter
+	payloadValidationWarningCounter metric.Int64Counter
+
+	// Publish Retry Metrics
+	publishRetryCounter metric.Int64Counter
+
+	// Consumer Lag Metrics
+	consumerPendingGauge       metric.Int64Gauge
+	consumerAckPendingGauge    metric.Int64Gauge
+	consumerMaxAckPendingGauge metric.Int64Gauge
+
+	// Delivery Metrics
+	redeliveryCounter       metric.Int64Counter
+	settlementCounter       metric.Int64Counter
+	ackLatencyHistogram     metric.Float64Histogram // Duration in seconds
+	ackHeartbeatCounter     metric.Int64Counter
+	inFlightUpDownCounter   metric.Int64UpDownCounter
+	ackSemaphoreSaturations metric.Int64Counter
+
+	// Publish Circuit Breaker and Spool Metrics
+	circuitStateChangeCounter metric.Int64Counter
+	spooledMessageCounter     metric.Int64Counter
+	spoolDroppedCounter       metric.Int64Counter
+	spoolDepthGauge           metric.Int64Gauge
 }
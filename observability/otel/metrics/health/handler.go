@@ -0,0 +1,54 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// NewHandler serves the service's checks for Kubernetes-style probes:
+//
+//   - /livez reports that the process is serving and never runs checkers, so a failing
+//     dependency does not get the pod restarted.
+//   - /readyz returns 503 while any critical checker fails.
+//   - /healthz returns the full JSON Report, with 503 while unhealthy.
+func NewHandler(service *Service) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/livez", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		report := service.Report(r.Context())
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if report.Status == StatusUnhealthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			for _, c := range report.Checks {
+				if c.Status == StatusUnhealthy {
+					_, _ = w.Write([]byte(c.Name + ": " + c.Error + "\n"))
+				}
+			}
+			return
+		}
+		_, _ = w.Write([]byte(string(report.Status) + "\n"))
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		report := service.Report(r.Context())
+		w.Header().Set("Content-Type", "application/json")
+		if report.Status == StatusUnhealthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(report)
+	})
+	return mux
+}
+
+// NewServer returns an HTTP server for NewHandler listening on addr. Start it with
+// ListenAndServe and stop it with Shutdown.
+func NewServer(addr string, service *Service) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           NewHandler(service),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+}
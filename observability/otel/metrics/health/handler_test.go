@@ -0,0 +1,128 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHandler_ReportsCriticalAndDegradedCheckers(t *testing.T) {
+	var cacheFailing, dbFailing atomic.Bool
+	service := NewService()
+	service.RegisterChecker(NewSimpleChecker("database", func(ctx context.Context) error {
+		if dbFailing.Load() {
+			return errors.New("connection refused")
+		}
+		return nil
+	}))
+	service.RegisterChecker(NewSimpleChecker("cache", func(ctx context.Context) error {
+		if cacheFailing.Load() {
+			return errors.New("cache miss storm")
+		}
+		return nil
+	}), DegradedOnly())
+	handler := NewHandler(service)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	if rec := get("/readyz"); rec.Code != http.StatusOK {
+		t.Fatalf("expected ready, got %d", rec.Code)
+	}
+
+	cacheFailing.Store(true)
+	if rec := get("/readyz"); rec.Code != http.StatusOK || rec.Body.String() != "degraded\n" {
+		t.Fatalf("expected degraded but ready, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	dbFailing.Store(true)
+	if rec := get("/readyz"); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected not ready, got %d", rec.Code)
+	}
+	if rec := get("/livez"); rec.Code != http.StatusOK {
+		t.Fatalf("expected live regardless of checkers, got %d", rec.Code)
+	}
+
+	rec := get("/healthz")
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 from healthz, got %d", rec.Code)
+	}
+	var report struct {
+		Status Status `json:"status"`
+		Checks []struct {
+			Name     string `json:"name"`
+			Status   Status `json:"status"`
+			Critical bool   `json:"critical"`
+			Error    string `json:"error"`
+			Latency  string `json:"latency"`
+		} `json:"checks"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("invalid healthz body: %v", err)
+	}
+	if report.Status != StatusUnhealthy || len(report.Checks) != 2 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	cache, db := report.Checks[0], report.Checks[1]
+	if cache.Name != "cache" || cache.Status != StatusDegraded || cache.Critical {
+		t.Fatalf("unexpected cache result %+v", cache)
+	}
+	if db.Name != "database" || db.Status != StatusUnhealthy || db.Error != "connection refused" || db.Latency == "" {
+		t.Fatalf("unexpected database result %+v", db)
+	}
+}
+
+func TestService_CachesResultsAndKeepsLastError(t *testing.T) {
+	var calls atomic.Int32
+	var failing atomic.Bool
+	failing.Store(true)
+
+	service := NewService(WithCacheTTL(time.Hour))
+	now := time.Now()
+	service.now = func() time.Time { return now }
+	service.RegisterChecker(NewSimpleChecker("nats", func(ctx context.Context) error {
+		calls.Add(1)
+		if failing.Load() {
+			return errors.New("no servers available")
+		}
+		return nil
+	}))
+
+	service.Report(context.Background())
+	service.Report(context.Background())
+	if calls.Load() != 1 {
+		t.Fatalf("expected the cached result to be reused, got %d calls", calls.Load())
+	}
+
+	failing.Store(false)
+	now = now.Add(2 * time.Hour)
+	report := service.Report(context.Background())
+	check := report.Checks[0]
+	if report.Status != StatusHealthy || check.Error != "" || check.LastError != "no servers available" || check.LastErrorAt == nil {
+		t.Fatalf("expected recovery with last error kept, got %+v", check)
+	}
+}
+
+func TestService_TimesOutSlowCheckers(t *testing.T) {
+	service := NewService()
+	block := make(chan struct{})
+	defer close(block)
+	service.RegisterChecker(NewSimpleChecker("slow", func(ctx context.Context) error {
+		<-block
+		return nil
+	}), WithTimeout(20*time.Millisecond))
+
+	report := service.Report(context.Background())
+	if report.Status != StatusUnhealthy || !strings.Contains(report.Checks[0].Error, "deadline exceeded") {
+		t.Fatalf("expected the slow checker to time out, got %+v", report)
+	}
+}
@@ -2,28 +2,90 @@ package health
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"sync"
+	"time"
 )
 
+// defaultCheckTimeout bounds each checker when no timeout is configured.
+const defaultCheckTimeout = 5 * time.Second
+
 // Service manages health checkers and performs health checks
 type Service struct {
-	mu       sync.RWMutex
-	checkers map[string]Checker
+	mu             sync.RWMutex
+	checkers       map[string]Checker
+	entries        map[string]*entry
+	cacheTTL       time.Duration
+	defaultTimeout time.Duration
+	now            func() time.Time
+}
+
+// ServiceOption configures a Service.
+type ServiceOption func(*Service)
+
+// WithCacheTTL reuses each checker's result for ttl, so frequent probes do not hammer
+// the dependencies being checked. Results are not cached by default.
+func WithCacheTTL(ttl time.Duration) ServiceOption {
+	return func(s *Service) {
+		s.cacheTTL = ttl
+	}
+}
+
+// WithDefaultTimeout sets the timeout for checkers registered without WithTimeout.
+func WithDefaultTimeout(timeout time.Duration) ServiceOption {
+	return func(s *Service) {
+		if timeout > 0 {
+			s.defaultTimeout = timeout
+		}
+	}
 }
 
 // NewService creates a new health service
-func NewService() *Service {
-	return &Service{
-		checkers: make(map[string]Checker),
+func NewService(opts ...ServiceOption) *Service {
+	s := &Service{
+		checkers:       make(map[string]Checker),
+		entries:        make(map[string]*entry),
+		defaultTimeout: defaultCheckTimeout,
+		now:            time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// CheckerOption configures how a registered checker is run and reported.
+type CheckerOption func(*entry)
+
+// WithTimeout bounds the checker's Check call.
+func WithTimeout(timeout time.Duration) CheckerOption {
+	return func(e *entry) {
+		e.timeout = timeout
 	}
 }
 
-// RegisterChecker adds a health checker
-func (s *Service) RegisterChecker(checker Checker) {
+// DegradedOnly marks the checker as non-critical: when it fails the service reports
+// StatusDegraded instead of StatusUnhealthy and stays ready.
+func DegradedOnly() CheckerOption {
+	return func(e *entry) {
+		e.critical = false
+	}
+}
+
+// RegisterChecker adds a health checker. Checkers are critical unless registered
+// with DegradedOnly.
+func (s *Service) RegisterChecker(checker Checker, opts ...CheckerOption) {
+	e := &entry{checker: checker, critical: true, timeout: s.defaultTimeout}
+	for _, opt := range opts {
+		opt(e)
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.checkers[checker.Name()] = checker
+	s.entries[checker.Name()] = e
 }
 
 // GetChecker retrieves a health checker by name
@@ -46,3 +108,136 @@ func (s *Service) CheckAll(ctx context.Context) error {
 	}
 	return nil
 }
+
+// CheckResult is the outcome of one checker.
+type CheckResult struct {
+	Name     string `json:"name"`
+	Status   Status `json:"status"`
+	Critical bool   `json:"critical"`
+	// Error is why the latest check failed; empty when it passed.
+	Error     string        `json:"error,omitempty"`
+	Latency   time.Duration `json:"-"`
+	CheckedAt time.Time     `json:"checked_at"`
+	// LastError and LastErrorAt keep the most recent failure after the checker recovers.
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+}
+
+// MarshalJSON renders Latency as a duration string such as "12.5ms".
+func (r CheckResult) MarshalJSON() ([]byte, error) {
+	type result CheckResult
+	return json.Marshal(struct {
+		result
+		Latency string `json:"latency"`
+	}{result(r), r.Latency.String()})
+}
+
+// Report is the combined outcome of every checker.
+type Report struct {
+	Status    Status        `json:"status"`
+	Checks    []CheckResult `json:"checks"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+// Report runs every checker concurrently, reusing results younger than the cache TTL.
+// The status is unhealthy if a critical checker fails, degraded if only DegradedOnly
+// checkers fail, and healthy otherwise.
+func (s *Service) Report(ctx context.Context) Report {
+	s.mu.RLock()
+	entries := make([]*entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		entries = append(entries, e)
+	}
+	s.mu.RUnlock()
+
+	results := make([]CheckResult, len(entries))
+	var wg sync.WaitGroup
+	for i, e := range entries {
+		wg.Add(1)
+		go func(i int, e *entry) {
+			defer wg.Done()
+			results[i] = e.check(ctx, s.cacheTTL, s.now)
+		}(i, e)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	status := StatusHealthy
+	for _, r := range results {
+		switch {
+		case r.Status == StatusUnhealthy:
+			status = StatusUnhealthy
+		case r.Status == StatusDegraded && status == StatusHealthy:
+			status = StatusDegraded
+		}
+	}
+	return Report{Status: status, Checks: results, CheckedAt: s.now()}
+}
+
+// entry is a registered checker with its settings and latest result.
+type entry struct {
+	checker  Checker
+	critical bool
+	timeout  time.Duration
+
+	// mu serializes checks, so concurrent reports share one run of a slow checker.
+	mu     sync.Mutex
+	result CheckResult
+	ran    bool
+}
+
+func (e *entry) check(ctx context.Context, cacheTTL time.Duration, now func() time.Time) CheckResult {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.ran && cacheTTL > 0 && now().Sub(e.result.CheckedAt) < cacheTTL {
+		return e.result
+	}
+
+	checkCtx := ctx
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		checkCtx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+
+	start := now()
+	err := runCheck(checkCtx, e.checker)
+
+	result := CheckResult{
+		Name:        e.checker.Name(),
+		Status:      StatusHealthy,
+		Critical:    e.critical,
+		Latency:     now().Sub(start),
+		CheckedAt:   start,
+		LastError:   e.result.LastError,
+		LastErrorAt: e.result.LastErrorAt,
+	}
+	if err != nil {
+		result.Status = StatusUnhealthy
+		if !e.critical {
+			result.Status = StatusDegraded
+		}
+		result.Error = err.Error()
+		result.LastError = err.Error()
+		result.LastErrorAt = &start
+	}
+
+	e.result, e.ran = result, true
+	return result
+}
+
+// runCheck returns when the checker does or ctx ends, whichever is first, so a checker
+// that ignores its context cannot stall a probe.
+func runCheck(ctx context.Context, checker Checker) error {
+	done := make(chan error, 1)
+	go func() { done <- checker.Check(ctx) }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("check did not finish: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,23 @@
+// observability/otel/metrics/outbox/attributes.go
+package outboxmetrics
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Common attributes
+func topicAttr(topic string) attribute.KeyValue {
+	return attribute.String("topic", topic)
+}
+
+func successAttr(success bool) attribute.KeyValue {
+	return attribute.Bool("success", success)
+}
+
+// Combined attribute sets
+func topicSuccessAttrs(topic string, success bool) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		topicAttr(topic),
+		successAttr(success),
+	}
+}
@@ -0,0 +1,68 @@
+// observability/otel/metrics/outbox/constructor.go
+package outboxmetrics
+
+import "go.opentelemetry.io/otel/metric"
+
+// NewOutboxMetrics creates a new OutboxMetrics implementation using OpenTelemetry.
+// It requires an OTEL Meter instance.
+func NewOutboxMetrics(meter metric.Meter, prefix string) (OutboxMetrics, error) {
+	// Helper function to create metric names with prefix
+	metricName := func(name string) string {
+		if prefix != "" {
+			return prefix + "_outbox_" + name
+		}
+		return "outbox_" + name
+	}
+
+	var err error
+	m := &outboxMetrics{meter: meter}
+
+	// Relay Publish Metrics
+	m.relayPublishCounter, err = meter.Int64Counter(
+		metricName("records_published_total"),
+		metric.WithDescription("Number of outbox records relayed to the event bus, partitioned by topic and success"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m.relayLagHistogram, err = meter.Float64Histogram(
+		metricName("relay_lag_seconds"),
+		metric.WithDescription("Time between a record being written to the outbox and its publication, partitioned by topic"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m.parkedRecordsCounter, err = meter.Int64Counter(
+		metricName("records_parked_total"),
+		metric.WithDescription("Number of outbox records the relay stopped retrying after a permanent error or too many attempts, partitioned by topic"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Backlog Metrics
+	m.pendingRecordsGauge, err = meter.Int64Gauge(
+		metricName("pending_records"),
+		metric.WithDescription("Number of unpublished outbox records seen by the last relay pass"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	m.oldestPendingAgeGauge, err = meter.Float64Gauge(
+		metricName("oldest_pending_age_seconds"),
+		metric.WithDescription("Age of the oldest unpublished outbox record"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
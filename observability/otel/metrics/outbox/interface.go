@@ -0,0 +1,19 @@
+// observability/otel/metrics/outbox/interface.go
+package outboxmetrics
+
+import (
+	"context"
+	"time"
+)
+
+// OutboxMetrics defines metrics for the transactional outbox relay using OpenTelemetry
+type OutboxMetrics interface {
+	// Relay publishing metrics
+	RecordRelayPublish(ctx context.Context, topic string, success bool)
+	RecordRelayLag(ctx context.Context, topic string, lag time.Duration)
+	RecordParkedRecord(ctx context.Context, topic string)
+
+	// Backlog metrics, sampled on every relay pass
+	RecordPendingRecords(ctx context.Context, count int)
+	RecordOldestPendingAge(ctx context.Context, age time.Duration)
+}
@@ -0,0 +1,34 @@
+// observability/otel/metrics/outbox/metrics.go
+package outboxmetrics
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+)
+
+// RecordRelayPublish records a relay publish attempt
+func (m *outboxMetrics) RecordRelayPublish(ctx context.Context, topic string, success bool) {
+	m.relayPublishCounter.Add(ctx, 1, metric.WithAttributes(topicSuccessAttrs(topic, success)...))
+}
+
+// RecordRelayLag records how long a record waited in the outbox before being published
+func (m *outboxMetrics) RecordRelayLag(ctx context.Context, topic string, lag time.Duration) {
+	m.relayLagHistogram.Record(ctx, lag.Seconds(), metric.WithAttributes(topicAttr(topic)))
+}
+
+// RecordParkedRecord records a record the relay gave up on
+func (m *outboxMetrics) RecordParkedRecord(ctx context.Context, topic string) {
+	m.parkedRecordsCounter.Add(ctx, 1, metric.WithAttributes(topicAttr(topic)))
+}
+
+// RecordPendingRecords records the current outbox backlog size
+func (m *outboxMetrics) RecordPendingRecords(ctx context.Context, count int) {
+	m.pendingRecordsGauge.Record(ctx, int64(count))
+}
+
+// RecordOldestPendingAge records the age of the oldest unpublished record
+func (m *outboxMetrics) RecordOldestPendingAge(ctx context.Context, age time.Duration) {
+	m.oldestPendingAgeGauge.Record(ctx, age.Seconds())
+}
@@ -0,0 +1,35 @@
+// observability/otel/metrics/outbox/noop.go
+package outboxmetrics
+
+import (
+	"context"
+	"time"
+)
+
+// NoOpMetrics is a metrics collector that does nothing. Useful for unit tests.
+type NoOpMetrics struct{}
+
+// NewNoop returns a no-operation implementation of OutboxMetrics
+func NewNoop() OutboxMetrics {
+	return &NoOpMetrics{}
+}
+
+// RecordRelayPublish does nothing
+func (n *NoOpMetrics) RecordRelayPublish(ctx context.Context, topic string, success bool) {
+}
+
+// RecordRelayLag does nothing
+func (n *NoOpMetrics) RecordRelayLag(ctx context.Context, topic string, lag time.Duration) {
+}
+
+// RecordParkedRecord does nothing
+func (n *NoOpMetrics) RecordParkedRecord(ctx context.Context, topic string) {
+}
+
+// RecordPendingRecords does nothing
+func (n *NoOpMetrics) RecordPendingRecords(ctx context.Context, count int) {
+}
+
+// RecordOldestPendingAge does nothing
+func (n *NoOpMetrics) RecordOldestPendingAge(ctx context.Context, age time.Duration) {
+}
@@ -0,0 +1,18 @@
+// observability/otel/metrics/outbox/struct.go
+package outboxmetrics
+
+import "go.opentelemetry.io/otel/metric"
+
+// outboxMetrics implements OutboxMetrics using OpenTelemetry
+type outboxMetrics struct {
+	meter metric.Meter // OTEL Meter
+
+	// Relay Publish Metrics
+	relayPublishCounter  metric.Int64Counter
+	relayLagHistogram    metric.Float64Histogram // Seconds between enqueue and publish
+	parkedRecordsCounter metric.Int64Counter
+
+	// Backlog Metrics
+	pendingRecordsGauge   metric.Int64Gauge
+	oldestPendingAgeGauge metric.Float64Gauge // Seconds
+}
@@ -52,6 +52,7 @@ func (e *ErrorStats) Reset() {
 }
 
 // NewRegistryMetrics creates and registers all registry metrics instruments.
+// cacheSizeFunc reports the cache size gauge; it may be nil.
 func NewRegistryMetrics(meter metric.Meter, cacheSizeFunc func() int64) (RegistryMetrics, error) {
 	configRequests, err := meter.Int64Counter(
 		"registry_config_requests_total",
@@ -110,16 +111,18 @@ func NewRegistryMetrics(meter metric.Meter, cacheSizeFunc func() int64) (Registr
 	if err != nil {
 		return nil, err
 	}
-	// Register callback for cache size gauge
-	_, err = meter.RegisterCallback(
-		func(ctx context.Context, o metric.Observer) error {
-			o.ObserveInt64(cacheSize, cacheSizeFunc())
-			return nil
-		},
-		cacheSize,
-	)
-	if err != nil {
-		return nil, err
+	// Register callback for cache size gauge; a nil cacheSizeFunc leaves it unreported
+	if cacheSizeFunc != nil {
+		_, err = meter.RegisterCallback(
+			func(ctx context.Context, o metric.Observer) error {
+				o.ObserveInt64(cacheSize, cacheSizeFunc())
+				return nil
+			},
+			cacheSize,
+		)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return &registryMetrics{
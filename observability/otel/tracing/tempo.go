@@ -24,6 +24,11 @@ const (
 	AttrDeadLetter    = "messaging.dead_letter"
 	AttrRetryCount    = "messaging.delivery_attempt"
 	AttrHandlerName   = "messaging.handler.name"
+
+	// JetStream delivery attributes set on consumer spans.
+	AttrStream         = "messaging.nats.stream"
+	AttrStreamSequence = "messaging.nats.stream.sequence"
+	AttrConsumerName   = "messaging.consumer.group.name"
 )
 
 // MessagingOperationType values per OTEL semconv
@@ -88,7 +93,7 @@ func TraceHandler(tracer trace.Tracer) message.HandlerMiddleware {
 					attribute.String(AttrCorrelationID, msg.Metadata.Get("correlation_id")),
 					attribute.String(AttrHandlerName, handlerName),
 					attribute.Bool(AttrDeadLetter, msg.Metadata.Get("dead_letter") == "true"),
-					attribute.Int64(AttrRetryCount, parseInt64(msg.Metadata.Get("jetstream.delivery_attempt"))),
+					attribute.Int64(AttrRetryCount, deliveryAttempt(msg)),
 				),
 			)
 			defer span.End()
@@ -118,6 +123,14 @@ func parseInt64(value string) int64 {
 	return val
 }
 
+// deliveryAttempt reads the delivery count set by the JetStream subscriber.
+func deliveryAttempt(msg *message.Message) int64 {
+	if v := msg.Metadata.Get("jetstream.delivery_attempt"); v != "" {
+		return parseInt64(v)
+	}
+	return parseInt64(msg.Metadata.Get("_js_num_delivered"))
+}
+
 // StartSpan creates a new span with the given name and includes common attributes.
 func (t *TempoTracer) StartSpan(ctx context.Context, name string, msg *message.Message) (context.Context, trace.Span) {
 	topic := msg.Metadata.Get("topic")
@@ -0,0 +1,79 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+const (
+	// defaultPrometheusAddress is the port registered for the OTEL Prometheus exporter.
+	defaultPrometheusAddress = ":9464"
+	defaultPrometheusPath    = "/metrics"
+)
+
+// setupPrometheusMetrics exports metrics through an OTEL Prometheus exporter served on
+// cfg.PrometheusAddress, alongside Go runtime and process collectors. logger reports
+// the scrape endpoint stopping unexpectedly.
+func setupPrometheusMetrics(cfg Config, res *resource.Resource, logger *slog.Logger) (metric.MeterProvider, *prometheus.Registry, func(context.Context) error, error) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	// Metric names already carry their unit (_seconds, _total); without this, unit "1"
+	// instruments would gain a _ratio suffix.
+	exporter, err := otelprometheus.New(
+		otelprometheus.WithRegisterer(registry),
+		otelprometheus.WithoutUnits(),
+	)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create Prometheus exporter: %w", err)
+	}
+
+	addr := firstNonEmpty(cfg.PrometheusAddress, defaultPrometheusAddress)
+	path := firstNonEmpty(cfg.PrometheusPath, defaultPrometheusPath)
+
+	// Listen up front so a taken port fails Setup instead of a background goroutine.
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to listen for Prometheus scrapes on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(path, promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry}))
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Prometheus scrape endpoint stopped", "address", addr, "error", err)
+		}
+	}()
+
+	mp := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(exporter),
+		sdkmetric.WithResource(res),
+	)
+
+	shutdown := func(ctx context.Context) error {
+		return errors.Join(server.Shutdown(ctx), mp.Shutdown(ctx))
+	}
+
+	return mp, registry, shutdown, nil
+}
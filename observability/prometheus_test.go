@@ -0,0 +1,76 @@
+package observability
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	databasemetrics "github.com/Black-And-White-Club/frolf-bot-shared/observability/otel/metrics/database"
+)
+
+func freeAddress(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to find a free port: %v", err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
+
+func TestSetup_PrometheusExporterServesDatabaseMetrics(t *testing.T) {
+	addr := freeAddress(t)
+	cfg := Config{
+		ServiceName:       "frolf",
+		MetricsExporter:   "Prometheus",
+		PrometheusAddress: addr,
+		PrometheusPath:    "/scrape",
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	provider, err := Setup(ctx, cfg)
+	if err != nil {
+		t.Fatalf("unexpected setup error: %v", err)
+	}
+	defer provider.Shutdown(ctx)
+	if provider.PrometheusRegistry == nil {
+		t.Fatal("expected the Prometheus registry to be exposed")
+	}
+
+	dbMetrics, err := databasemetrics.NewDatabaseMetrics(provider.MeterProvider.Meter(cfg.ServiceName), cfg.ServiceName)
+	if err != nil {
+		t.Fatalf("unexpected database metrics error: %v", err)
+	}
+	dbMetrics.RecordQueryResult(ctx, "select_round", true)
+	dbMetrics.RecordConnectionPoolStatus(ctx, 4, 3, 1)
+
+	resp, err := http.Get("http://" + addr + "/scrape")
+	if err != nil {
+		t.Fatalf("unexpected scrape error: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	for _, want := range []string{
+		`frolf_db_query_results_total{operation="select_round"`,
+		`frolf_db_connection_pool_status{`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Fatalf("expected scrape to contain %q, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestSetup_RejectsUnknownMetricsExporter(t *testing.T) {
+	cfg := Config{OTLPEndpoint: "localhost:4317", MetricsExporter: "statsd"}
+	if _, err := Setup(context.Background(), cfg); err == nil || !strings.Contains(err.Error(), "MetricsExporter") {
+		t.Fatalf("expected metrics exporter error, got %v", err)
+	}
+}
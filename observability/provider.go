@@ -9,10 +9,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/log"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/trace"
 
@@ -21,6 +23,13 @@ import (
 	otlpmetricgrpc "go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	otlptracegrpc "go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"google.golang.org/grpc/credentials"
+	// Registers the gzip compressor used by the gRPC exporters.
+	_ "google.golang.org/grpc/encoding/gzip"
+
+	// OTEL exporters (HTTP)
+	otlploghttp "go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	otlpmetrichttp "go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	otlptracehttp "go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 
 	// OTEL SDK
 	sdklog "go.opentelemetry.io/otel/sdk/log"
@@ -33,6 +42,11 @@ type Provider struct {
 	MeterProvider  metric.MeterProvider
 	Logger         *slog.Logger
 	Shutdown       func(ctx context.Context) error
+
+	// PrometheusRegistry backs the scrape endpoint when Config.MetricsExporter is
+	// "prometheus", so client_golang collectors can be registered alongside the OTEL
+	// metrics. It is nil for other exporters.
+	PrometheusRegistry *prometheus.Registry
 }
 
 func Setup(ctx context.Context, cfg Config) (*Provider, error) {
@@ -57,26 +71,33 @@ func Setup(ctx context.Context, cfg Config) (*Provider, error) {
 	}
 
 	otel.SetTracerProvider(tracerProvider)
+	// W3C traceparent/baggage carry traces across services through message metadata.
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	// ========== Logging ==========
+	// Set up before metrics so the Prometheus endpoint can report failures through it.
+	logger, logShutdown, err := setupLogging(ctx, cfg, res)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup logging: %w", err)
+	}
 
 	// ========== Metrics ==========
 	var meterProvider metric.MeterProvider = noop.NewMeterProvider()
+	var promRegistry *prometheus.Registry
 
 	if cfg.MetricsEnabled() {
-		mp, shutdown, err := setupMetrics(ctx, cfg, res)
+		mp, registry, shutdown, err := setupMetrics(ctx, cfg, res, logger)
 		if err != nil {
 			return nil, fmt.Errorf("failed to setup metrics: %w", err)
 		}
 		meterProvider = mp
+		promRegistry = registry
 		shutdownFuncs = append(shutdownFuncs, shutdown)
 	}
 
 	otel.SetMeterProvider(meterProvider)
 
-	// ========== Logging ==========
-	logger, logShutdown, err := setupLogging(ctx, cfg, res)
-	if err != nil {
-		return nil, fmt.Errorf("failed to setup logging: %w", err)
-	}
+	// Logging shuts down last so the other providers can still log while stopping.
 	shutdownFuncs = append(shutdownFuncs, logShutdown)
 
 	// Combined shutdown function
@@ -94,6 +115,8 @@ func Setup(ctx context.Context, cfg Config) (*Provider, error) {
 		MeterProvider:  meterProvider,
 		Logger:         logger,
 		Shutdown:       shutdown,
+
+		PrometheusRegistry: promRegistry,
 	}, nil
 }
 
@@ -127,12 +150,16 @@ func setupOTLPLogging(ctx context.Context, cfg Config, res *resource.Resource) (
 	if err != nil {
 		return nil, nil, err
 	}
+	if _, err := compressionFromConfig(cfg); err != nil {
+		return nil, nil, err
+	}
 
+	var exporter sdklog.Exporter
 	if transport == "http" {
-		return nil, nil, fmt.Errorf("OTLPTransport=\"http\" is not supported for logging; use \"grpc\"")
+		exporter, err = otlploghttp.New(ctx, otlploghttpOptions(endpoint, cfg)...)
+	} else {
+		exporter, err = otlploggrpc.New(ctx, otlploggrpcOptions(endpoint, cfg)...)
 	}
-
-	exporter, err := otlploggrpc.New(ctx, otlploggrpcOptions(endpoint, cfg)...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create OTLP log exporter: %w", err)
 	}
@@ -255,20 +282,33 @@ func setupTracing(ctx context.Context, cfg Config, res *resource.Resource) (trac
 	if err != nil {
 		return nil, nil, err
 	}
-	if transport == "http" {
-		return nil, nil, fmt.Errorf("OTLPTransport=\"http\" is not supported for tracing in this build; use \"grpc\"")
+	if _, err := compressionFromConfig(cfg); err != nil {
+		return nil, nil, err
+	}
+	sampler, err := traceSamplerFromConfig(cfg)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	exporter, err := otlptracegrpc.New(ctx, otlptracegrpcOptions(endpoint, cfg)...)
+	var exporter sdktrace.SpanExporter
+	if transport == "http" {
+		exporter, err = otlptracehttp.New(ctx, otlptracehttpOptions(endpoint, cfg)...)
+	} else {
+		exporter, err = otlptracegrpc.New(ctx, otlptracegrpcOptions(endpoint, cfg)...)
+	}
 	if err != nil {
 		return nil, nil, err
 	}
 
+	var processor sdktrace.SpanProcessor = sdktrace.NewBatchSpanProcessor(exporter)
+	if cfg.TraceErrorBiased {
+		processor = errorBiasedProcessor{next: processor}
+	}
 	tpOptions := []sdktrace.TracerProviderOption{
-		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSpanProcessor(processor),
 		sdktrace.WithResource(res),
 	}
-	if sampler := buildTraceSampler(cfg.TempoSampleRate); sampler != nil {
+	if sampler != nil {
 		tpOptions = append(tpOptions, sdktrace.WithSampler(sampler))
 	}
 
@@ -277,23 +317,36 @@ func setupTracing(ctx context.Context, cfg Config, res *resource.Resource) (trac
 	return tp, tp.Shutdown, nil
 }
 
-func setupMetrics(ctx context.Context, cfg Config, res *resource.Resource) (metric.MeterProvider, func(context.Context) error, error) {
+func setupMetrics(ctx context.Context, cfg Config, res *resource.Resource, logger *slog.Logger) (metric.MeterProvider, *prometheus.Registry, func(context.Context) error, error) {
+	exporterName, err := metricsExporterFromConfig(cfg)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if exporterName == "prometheus" {
+		return setupPrometheusMetrics(cfg, res, logger)
+	}
+
 	endpoint := firstNonEmpty(cfg.OTLPEndpoint, cfg.MetricsAddress)
 	if endpoint == "" {
 		// metrics disabled
-		return noop.NewMeterProvider(), func(context.Context) error { return nil }, nil
+		return noop.NewMeterProvider(), nil, func(context.Context) error { return nil }, nil
 	}
 	transport, err := transportFromConfig(cfg)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
-	if transport == "http" {
-		return nil, nil, fmt.Errorf("OTLPTransport=\"http\" is not supported for metrics in this build; use \"grpc\"")
+	if _, err := compressionFromConfig(cfg); err != nil {
+		return nil, nil, nil, err
 	}
 
-	exporter, err := otlpmetricgrpc.New(ctx, otlpmetricgrpcOptions(endpoint, cfg)...)
+	var exporter sdkmetric.Exporter
+	if transport == "http" {
+		exporter, err = otlpmetrichttp.New(ctx, otlpmetrichttpOptions(endpoint, cfg)...)
+	} else {
+		exporter, err = otlpmetricgrpc.New(ctx, otlpmetricgrpcOptions(endpoint, cfg)...)
+	}
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	mp := sdkmetric.NewMeterProvider(
@@ -301,7 +354,7 @@ func setupMetrics(ctx context.Context, cfg Config, res *resource.Resource) (metr
 		sdkmetric.WithResource(res),
 	)
 
-	return mp, mp.Shutdown, nil
+	return mp, nil, mp.Shutdown, nil
 }
 
 func firstNonEmpty(vals ...string) string {
@@ -326,6 +379,42 @@ func transportFromConfig(cfg Config) (string, error) {
 	}
 }
 
+func metricsExporterFromConfig(cfg Config) (string, error) {
+	e := strings.TrimSpace(strings.ToLower(cfg.MetricsExporter))
+	if e == "" {
+		return "otlp", nil
+	}
+	switch e {
+	case "otlp", "prometheus":
+		return e, nil
+	default:
+		return "", fmt.Errorf("unsupported MetricsExporter value: %q (supported: \"otlp\", \"prometheus\")", cfg.MetricsExporter)
+	}
+}
+
+func compressionFromConfig(cfg Config) (string, error) {
+	c := strings.TrimSpace(strings.ToLower(cfg.OTLPCompression))
+	if c == "" {
+		return "none", nil
+	}
+	switch c {
+	case "gzip", "none":
+		return c, nil
+	default:
+		return "", fmt.Errorf("unsupported OTLPCompression value: %q (supported: \"gzip\", \"none\")", cfg.OTLPCompression)
+	}
+}
+
+// gzipEnabled reports whether exports are gzip-compressed. Setup validates the value first.
+func gzipEnabled(cfg Config) bool {
+	c, err := compressionFromConfig(cfg)
+	return err == nil && c == "gzip"
+}
+
+func otlpTLSConfig() *tls.Config {
+	return &tls.Config{MinVersion: tls.VersionTLS12}
+}
+
 func newStdoutLogger(cfg Config) *slog.Logger {
 	l := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: parseLogLevel(cfg)}))
 	return l.With("service", cfg.ServiceName, "version", cfg.Version, "environment", cfg.Environment)
@@ -333,29 +422,95 @@ func newStdoutLogger(cfg Config) *slog.Logger {
 
 func otlploggrpcOptions(endpoint string, cfg Config) []otlploggrpc.Option {
 	opts := []otlploggrpc.Option{otlploggrpc.WithEndpoint(endpoint)}
+	if len(cfg.OTLPHeaders) > 0 {
+		opts = append(opts, otlploggrpc.WithHeaders(cfg.OTLPHeaders))
+	}
+	if gzipEnabled(cfg) {
+		opts = append(opts, otlploggrpc.WithCompressor("gzip"))
+	}
 	if cfg.OTLPInsecure {
 		return append(opts, otlploggrpc.WithInsecure())
 	}
-	creds := credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
-	return append(opts, otlploggrpc.WithTLSCredentials(creds))
+	return append(opts, otlploggrpc.WithTLSCredentials(credentials.NewTLS(otlpTLSConfig())))
 }
 
 func otlptracegrpcOptions(endpoint string, cfg Config) []otlptracegrpc.Option {
 	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(endpoint)}
+	if len(cfg.OTLPHeaders) > 0 {
+		opts = append(opts, otlptracegrpc.WithHeaders(cfg.OTLPHeaders))
+	}
+	if gzipEnabled(cfg) {
+		opts = append(opts, otlptracegrpc.WithCompressor("gzip"))
+	}
 	if cfg.OTLPInsecure {
 		return append(opts, otlptracegrpc.WithInsecure())
 	}
-	creds := credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
-	return append(opts, otlptracegrpc.WithTLSCredentials(creds))
+	return append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(otlpTLSConfig())))
 }
 
 func otlpmetricgrpcOptions(endpoint string, cfg Config) []otlpmetricgrpc.Option {
 	opts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpoint(endpoint)}
+	if len(cfg.OTLPHeaders) > 0 {
+		opts = append(opts, otlpmetricgrpc.WithHeaders(cfg.OTLPHeaders))
+	}
+	if gzipEnabled(cfg) {
+		opts = append(opts, otlpmetricgrpc.WithCompressor("gzip"))
+	}
 	if cfg.OTLPInsecure {
 		return append(opts, otlpmetricgrpc.WithInsecure())
 	}
-	creds := credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
-	return append(opts, otlpmetricgrpc.WithTLSCredentials(creds))
+	return append(opts, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(otlpTLSConfig())))
+}
+
+func otlploghttpOptions(endpoint string, cfg Config) []otlploghttp.Option {
+	opts := []otlploghttp.Option{otlploghttp.WithEndpoint(endpoint)}
+	if cfg.OTLPLogsPath != "" {
+		opts = append(opts, otlploghttp.WithURLPath(cfg.OTLPLogsPath))
+	}
+	if len(cfg.OTLPHeaders) > 0 {
+		opts = append(opts, otlploghttp.WithHeaders(cfg.OTLPHeaders))
+	}
+	if gzipEnabled(cfg) {
+		opts = append(opts, otlploghttp.WithCompression(otlploghttp.GzipCompression))
+	}
+	if cfg.OTLPInsecure {
+		return append(opts, otlploghttp.WithInsecure())
+	}
+	return append(opts, otlploghttp.WithTLSClientConfig(otlpTLSConfig()))
+}
+
+func otlptracehttpOptions(endpoint string, cfg Config) []otlptracehttp.Option {
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint)}
+	if cfg.OTLPTracesPath != "" {
+		opts = append(opts, otlptracehttp.WithURLPath(cfg.OTLPTracesPath))
+	}
+	if len(cfg.OTLPHeaders) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(cfg.OTLPHeaders))
+	}
+	if gzipEnabled(cfg) {
+		opts = append(opts, otlptracehttp.WithCompression(otlptracehttp.GzipCompression))
+	}
+	if cfg.OTLPInsecure {
+		return append(opts, otlptracehttp.WithInsecure())
+	}
+	return append(opts, otlptracehttp.WithTLSClientConfig(otlpTLSConfig()))
+}
+
+func otlpmetrichttpOptions(endpoint string, cfg Config) []otlpmetrichttp.Option {
+	opts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(endpoint)}
+	if cfg.OTLPMetricsPath != "" {
+		opts = append(opts, otlpmetrichttp.WithURLPath(cfg.OTLPMetricsPath))
+	}
+	if len(cfg.OTLPHeaders) > 0 {
+		opts = append(opts, otlpmetrichttp.WithHeaders(cfg.OTLPHeaders))
+	}
+	if gzipEnabled(cfg) {
+		opts = append(opts, otlpmetrichttp.WithCompression(otlpmetrichttp.GzipCompression))
+	}
+	if cfg.OTLPInsecure {
+		return append(opts, otlpmetrichttp.WithInsecure())
+	}
+	return append(opts, otlpmetrichttp.WithTLSClientConfig(otlpTLSConfig()))
 }
 
 func redactLogValue(key, value string) string {
@@ -0,0 +1,83 @@
+package observability
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSetup_OTLPHTTPExportsAllSignals(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests = map[string]*http.Request{}
+	)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path] = r
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	cfg := Config{
+		ServiceName:     "test",
+		Environment:     "dev",
+		OTLPEndpoint:    strings.TrimPrefix(collector.URL, "http://"),
+		OTLPInsecure:    true,
+		OTLPTransport:   "HTTP",
+		OTLPHeaders:     map[string]string{"X-Scope-OrgID": "frolf"},
+		OTLPCompression: "gzip",
+		OTLPTracesPath:  "/otlp/traces",
+		OTLPMetricsPath: "/otlp/metrics",
+		OTLPLogsPath:    "/otlp/logs",
+		LokiURL:         "unused",
+		LogsEnabled:     true,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	provider, err := Setup(ctx, cfg)
+	if err != nil {
+		t.Fatalf("unexpected setup error: %v", err)
+	}
+
+	_, span := provider.TracerProvider.Tracer("test").Start(ctx, "span")
+	span.End()
+	counter, err := provider.MeterProvider.Meter("test").Int64Counter("test_total")
+	if err != nil {
+		t.Fatalf("unexpected counter error: %v", err)
+	}
+	counter.Add(ctx, 1)
+	provider.Logger.InfoContext(ctx, "hello")
+
+	if err := provider.Shutdown(ctx); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, path := range []string{"/otlp/traces", "/otlp/metrics", "/otlp/logs"} {
+		r, ok := requests[path]
+		if !ok {
+			t.Fatalf("expected an export to %s, got %v", path, requests)
+		}
+		if got := r.Header.Get("X-Scope-OrgID"); got != "frolf" {
+			t.Fatalf("expected custom header on %s, got %q", path, got)
+		}
+		if got := r.Header.Get("Content-Encoding"); got != "gzip" {
+			t.Fatalf("expected gzip export on %s, got %q", path, got)
+		}
+	}
+}
+
+func TestSetup_RejectsUnknownCompression(t *testing.T) {
+	cfg := Config{OTLPEndpoint: "localhost:4318", OTLPTransport: "http", OTLPCompression: "zstd"}
+	if _, err := Setup(context.Background(), cfg); err == nil || !strings.Contains(err.Error(), "OTLPCompression") {
+		t.Fatalf("expected compression error, got %v", err)
+	}
+}
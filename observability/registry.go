@@ -1,15 +1,22 @@
 package observability
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
 
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/trace"
 
+	clubmetrics "github.com/Black-And-White-Club/frolf-bot-shared/observability/otel/metrics/club"
+	databasemetrics "github.com/Black-And-White-Club/frolf-bot-shared/observability/otel/metrics/database"
 	discordmetrics "github.com/Black-And-White-Club/frolf-bot-shared/observability/otel/metrics/discord"
 	eventbusmetrics "github.com/Black-And-White-Club/frolf-bot-shared/observability/otel/metrics/eventbus"
 	guildmetrics "github.com/Black-And-White-Club/frolf-bot-shared/observability/otel/metrics/guild"
+	importermetrics "github.com/Black-And-White-Club/frolf-bot-shared/observability/otel/metrics/importer"
 	leaderboardmetrics "github.com/Black-And-White-Club/frolf-bot-shared/observability/otel/metrics/leaderboard"
+	outboxmetrics "github.com/Black-And-White-Club/frolf-bot-shared/observability/otel/metrics/outbox"
+	registrymetrics "github.com/Black-And-White-Club/frolf-bot-shared/observability/otel/metrics/registry"
 	roundmetrics "github.com/Black-And-White-Club/frolf-bot-shared/observability/otel/metrics/round"
 	scoremetrics "github.com/Black-And-White-Club/frolf-bot-shared/observability/otel/metrics/score"
 	usermetrics "github.com/Black-And-White-Club/frolf-bot-shared/observability/otel/metrics/user"
@@ -28,41 +35,92 @@ type Registry struct {
 	EventBusMetrics    eventbusmetrics.EventBusMetrics
 	DiscordMetrics     discordmetrics.DiscordMetrics
 	GuildMetrics       guildmetrics.GuildMetrics
+	OutboxMetrics      outboxmetrics.OutboxMetrics
+	ClubMetrics        clubmetrics.ClubMetrics
+	ImporterMetrics    importermetrics.ImporterMetrics
+	DatabaseMetrics    databasemetrics.DatabaseMetrics
+	// RegistryMetrics does not report the cache size gauge; services that own a
+	// registry cache construct their own with registrymetrics.New.
+	RegistryMetrics registrymetrics.RegistryMetrics
 }
 
-func NewRegistry(provider *Provider, cfg Config) *Registry {
+// NewRegistry builds every metrics package from the provider's meter. A package whose
+// constructor fails falls back to its no-op implementation, and the failures are
+// returned together, so the Registry is always usable.
+func NewRegistry(provider *Provider, cfg Config) (*Registry, error) {
 	meter := provider.MeterProvider.Meter(cfg.ServiceName)
 	tracer := provider.TracerProvider.Tracer(cfg.ServiceName)
 
-	userMetrics := usermetrics.NewNoop() // fallback
-	scoreMetrics := scoremetrics.NewNoop()
-	roundMetrics := roundmetrics.NewNoop()
-	leaderboardMetrics := leaderboardmetrics.NewNoop()
-	eventbusMetrics := eventbusmetrics.NewNoop()
-	discordMetrics := discordmetrics.NewNoop()
-	guildMetrics := guildmetrics.NewNoop()
-
-	if cfg.MetricsEnabled() {
-		userMetrics, _ = usermetrics.NewUserMetrics(meter, cfg.ServiceName)
-		scoreMetrics, _ = scoremetrics.NewScoreMetrics(meter, cfg.ServiceName)
-		roundMetrics, _ = roundmetrics.NewRoundMetrics(meter, cfg.ServiceName)
-		leaderboardMetrics, _ = leaderboardmetrics.NewLeaderboardMetrics(meter, cfg.ServiceName)
-		eventbusMetrics, _ = eventbusmetrics.NewEventBusMetrics(meter, cfg.ServiceName)
-		discordMetrics, _ = discordmetrics.NewDiscordMetrics(meter, cfg.ServiceName)
-		guildMetrics, _ = guildmetrics.NewGuildMetrics(meter, cfg.ServiceName)
-	}
-
-	return &Registry{
+	r := &Registry{
 		Logger: provider.Logger,
 		Tracer: tracer,
 		Meter:  meter,
 
-		UserMetrics:        userMetrics,
-		ScoreMetrics:       scoreMetrics,
-		RoundMetrics:       roundMetrics,
-		LeaderboardMetrics: leaderboardMetrics,
-		EventBusMetrics:    eventbusMetrics,
-		DiscordMetrics:     discordMetrics,
-		GuildMetrics:       guildMetrics,
+		UserMetrics:        usermetrics.NewNoop(), // fallback
+		ScoreMetrics:       scoremetrics.NewNoop(),
+		RoundMetrics:       roundmetrics.NewNoop(),
+		LeaderboardMetrics: leaderboardmetrics.NewNoop(),
+		EventBusMetrics:    eventbusmetrics.NewNoop(),
+		DiscordMetrics:     discordmetrics.NewNoop(),
+		GuildMetrics:       guildmetrics.NewNoop(),
+		OutboxMetrics:      outboxmetrics.NewNoop(),
+		ClubMetrics:        clubmetrics.NewNoop(),
+		ImporterMetrics:    importermetrics.NewNoOpMetrics(),
+		DatabaseMetrics:    databasemetrics.NewNoop(),
+		RegistryMetrics:    registrymetrics.NewNoop(),
+	}
+
+	if !cfg.MetricsEnabled() {
+		return r, nil
+	}
+
+	var errs []error
+	r.UserMetrics = construct(&errs, "user", r.UserMetrics, func() (usermetrics.UserMetrics, error) {
+		return usermetrics.NewUserMetrics(meter, cfg.ServiceName)
+	})
+	r.ScoreMetrics = construct(&errs, "score", r.ScoreMetrics, func() (scoremetrics.ScoreMetrics, error) {
+		return scoremetrics.NewScoreMetrics(meter, cfg.ServiceName)
+	})
+	r.RoundMetrics = construct(&errs, "round", r.RoundMetrics, func() (roundmetrics.RoundMetrics, error) {
+		return roundmetrics.NewRoundMetrics(meter, cfg.ServiceName)
+	})
+	r.LeaderboardMetrics = construct(&errs, "leaderboard", r.LeaderboardMetrics, func() (leaderboardmetrics.LeaderboardMetrics, error) {
+		return leaderboardmetrics.NewLeaderboardMetrics(meter, cfg.ServiceName)
+	})
+	r.EventBusMetrics = construct(&errs, "eventbus", r.EventBusMetrics, func() (eventbusmetrics.EventBusMetrics, error) {
+		return eventbusmetrics.NewEventBusMetrics(meter, cfg.ServiceName)
+	})
+	r.DiscordMetrics = construct(&errs, "discord", r.DiscordMetrics, func() (discordmetrics.DiscordMetrics, error) {
+		return discordmetrics.NewDiscordMetrics(meter, cfg.ServiceName)
+	})
+	r.GuildMetrics = construct(&errs, "guild", r.GuildMetrics, func() (guildmetrics.GuildMetrics, error) {
+		return guildmetrics.NewGuildMetrics(meter, cfg.ServiceName)
+	})
+	r.OutboxMetrics = construct(&errs, "outbox", r.OutboxMetrics, func() (outboxmetrics.OutboxMetrics, error) {
+		return outboxmetrics.NewOutboxMetrics(meter, cfg.ServiceName)
+	})
+	r.ClubMetrics = construct(&errs, "club", r.ClubMetrics, func() (clubmetrics.ClubMetrics, error) {
+		return clubmetrics.NewClubMetrics(meter, cfg.ServiceName)
+	})
+	r.ImporterMetrics = construct(&errs, "importer", r.ImporterMetrics, func() (importermetrics.ImporterMetrics, error) {
+		return importermetrics.NewImporterMetrics(meter)
+	})
+	r.DatabaseMetrics = construct(&errs, "database", r.DatabaseMetrics, func() (databasemetrics.DatabaseMetrics, error) {
+		return databasemetrics.NewDatabaseMetrics(meter, cfg.ServiceName)
+	})
+	r.RegistryMetrics = construct(&errs, "registry", r.RegistryMetrics, func() (registrymetrics.RegistryMetrics, error) {
+		return registrymetrics.New(meter, nil)
+	})
+
+	return r, errors.Join(errs...)
+}
+
+// construct returns the metrics built by newMetrics, or fallback if it fails.
+func construct[T any](errs *[]error, name string, fallback T, newMetrics func() (T, error)) T {
+	m, err := newMetrics()
+	if err != nil {
+		*errs = append(*errs, fmt.Errorf("failed to create %s metrics: %w", name, err))
+		return fallback
 	}
+	return m
 }
@@ -0,0 +1,81 @@
+package observability
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestNewRegistry_BuildsAllMetricsFromSharedMeter(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := &Provider{
+		TracerProvider: tracenoop.NewTracerProvider(),
+		MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+		Logger:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	registry, err := NewRegistry(provider, Config{ServiceName: "frolf", MetricsExporter: "prometheus"})
+	if err != nil {
+		t.Fatalf("NewRegistry returned error: %v", err)
+	}
+
+	ctx := context.Background()
+	registry.ClubMetrics.RecordOperationAttempt(ctx, "create", "club")
+	registry.ImporterMetrics.RecordImportAttempt(ctx)
+	registry.DatabaseMetrics.RecordQueryResult(ctx, "select", true)
+	registry.RegistryMetrics.RecordCacheHit(ctx, "guild-1")
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	names := make(map[string]bool)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			names[m.Name] = true
+		}
+	}
+	for _, want := range []string{
+		"frolf_club_operation_attempts_total",
+		"round.import.attempts.total",
+		"frolf_db_query_results_total",
+		"registry_cache_hits_total",
+	} {
+		if !names[want] {
+			t.Fatalf("expected metric %q to be recorded, got %v", want, names)
+		}
+	}
+}
+
+func TestNewRegistry_MetricsDisabledUsesNoops(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := &Provider{
+		TracerProvider: tracenoop.NewTracerProvider(),
+		MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+		Logger:         slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	registry, err := NewRegistry(provider, Config{ServiceName: "frolf"})
+	if err != nil {
+		t.Fatalf("NewRegistry returned error: %v", err)
+	}
+
+	ctx := context.Background()
+	registry.ClubMetrics.RecordOperationAttempt(ctx, "create", "club")
+	registry.DatabaseMetrics.RecordQueryResult(ctx, "select", true)
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	for _, sm := range rm.ScopeMetrics {
+		if len(sm.Metrics) > 0 {
+			t.Fatalf("expected no metrics with metrics disabled, got %d in %s", len(sm.Metrics), sm.Scope.Name)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package observability
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceSampleRule sets the sample rate of spans whose messaging topic or span name
+// matches Pattern.
+type TraceSampleRule struct {
+	// Pattern is a glob where * matches any run of characters, e.g.
+	// "round.finalization.failed.v1", "*.error.v1" or "discord.*.trace.v1".
+	Pattern string
+	// Rate is the fraction of matching traces to keep: 1 keeps all, 0 drops all.
+	Rate float64
+}
+
+// traceSamplerFromConfig returns the sampler for cfg, or nil to keep the SDK default.
+func traceSamplerFromConfig(cfg Config) (sdktrace.Sampler, error) {
+	if len(cfg.TraceSampleRules) == 0 && !cfg.TraceErrorBiased {
+		return buildTraceSampler(cfg.TempoSampleRate), nil
+	}
+	return newRuleSampler(cfg.TraceSampleRules, cfg.TempoSampleRate, cfg.TraceErrorBiased)
+}
+
+type sampleRule struct {
+	pattern string
+	sampler sdktrace.Sampler
+}
+
+// ruleSampler samples spans by the first rule matching their topic or name. Rules apply
+// even under a parent, so a rule can keep a failure span from an unsampled trace or drop
+// chatty spans from a sampled one. Other spans follow their parent, and root spans use
+// the default rate.
+type ruleSampler struct {
+	rules       []sampleRule
+	root        sdktrace.Sampler
+	errorBiased bool
+	description string
+}
+
+func newRuleSampler(rules []TraceSampleRule, defaultRate float64, errorBiased bool) (*ruleSampler, error) {
+	s := &ruleSampler{root: ratioSampler(defaultRate), errorBiased: errorBiased}
+	if defaultRate <= 0 {
+		s.root = sdktrace.AlwaysSample()
+	}
+
+	descs := make([]string, 0, len(rules))
+	for _, r := range rules {
+		if r.Pattern == "" {
+			return nil, fmt.Errorf("trace sample rule requires a pattern")
+		}
+		if _, err := path.Match(r.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid trace sample rule pattern %q: %w", r.Pattern, err)
+		}
+		if r.Rate < 0 || r.Rate > 1 {
+			return nil, fmt.Errorf("trace sample rule %q rate %v must be between 0 and 1", r.Pattern, r.Rate)
+		}
+		s.rules = append(s.rules, sampleRule{pattern: r.Pattern, sampler: ratioSampler(r.Rate)})
+		descs = append(descs, fmt.Sprintf("%s=%v", r.Pattern, r.Rate))
+	}
+	s.description = fmt.Sprintf("RuleBasedSampler{rules=[%s],root=%s,errorBiased=%t}",
+		strings.Join(descs, ","), s.root.Description(), errorBiased)
+	return s, nil
+}
+
+// ratioSampler returns a sampler keeping rate of traces, by trace ID so every span of a
+// trace matching the same rule gets the same decision.
+func ratioSampler(rate float64) sdktrace.Sampler {
+	switch {
+	case rate <= 0:
+		return sdktrace.NeverSample()
+	case rate >= 1:
+		return sdktrace.AlwaysSample()
+	default:
+		return sdktrace.TraceIDRatioBased(rate)
+	}
+}
+
+func (s *ruleSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	result := s.decide(p)
+	if s.errorBiased && result.Decision == sdktrace.Drop {
+		// Record the span anyway so errorBiasedProcessor can export it if it fails.
+		result.Decision = sdktrace.RecordOnly
+	}
+	return result
+}
+
+func (s *ruleSampler) decide(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	topic := ""
+	for _, kv := range p.Attributes {
+		if kv.Key == semconv.MessagingDestinationNameKey {
+			topic = kv.Value.AsString()
+			break
+		}
+	}
+	for _, r := range s.rules {
+		if matchSamplePattern(r.pattern, topic) || matchSamplePattern(r.pattern, p.Name) {
+			return r.sampler.ShouldSample(p)
+		}
+	}
+
+	parent := trace.SpanContextFromContext(p.ParentContext)
+	if !parent.IsValid() {
+		return s.root.ShouldSample(p)
+	}
+	decision := sdktrace.Drop
+	if parent.IsSampled() {
+		decision = sdktrace.RecordAndSample
+	}
+	return sdktrace.SamplingResult{Decision: decision, Tracestate: parent.TraceState()}
+}
+
+func matchSamplePattern(pattern, value string) bool {
+	if value == "" {
+		return false
+	}
+	ok, _ := path.Match(pattern, value)
+	return ok
+}
+
+func (s *ruleSampler) Description() string {
+	return s.description
+}
+
+// errorBiasedProcessor passes sampled spans to next, along with spans the sampler only
+// recorded that ended with an error status. Those error spans are exported without the
+// rest of their unsampled trace; full tail sampling belongs in a collector.
+type errorBiasedProcessor struct {
+	next sdktrace.SpanProcessor
+}
+
+func (p errorBiasedProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+func (p errorBiasedProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	if !s.SpanContext().IsSampled() {
+		if s.Status().Code != codes.Error {
+			return
+		}
+		s = sampledSpan{s}
+	}
+	p.next.OnEnd(s)
+}
+
+func (p errorBiasedProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p errorBiasedProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// sampledSpan marks a recorded-only span as sampled so exporting processors accept it.
+type sampledSpan struct {
+	sdktrace.ReadOnlySpan
+}
+
+func (s sampledSpan) SpanContext() trace.SpanContext {
+	sc := s.ReadOnlySpan.SpanContext()
+	return sc.WithTraceFlags(sc.TraceFlags().WithSampled(true))
+}
@@ -0,0 +1,132 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceSamplerFromConfig_NoRulesKeepsRateSampler(t *testing.T) {
+	sampler, err := traceSamplerFromConfig(Config{})
+	if err != nil || sampler != nil {
+		t.Fatalf("expected SDK default sampler, got %v, %v", sampler, err)
+	}
+}
+
+func TestTraceSamplerFromConfig_RejectsInvalidRules(t *testing.T) {
+	for _, rule := range []TraceSampleRule{
+		{Pattern: "", Rate: 1},
+		{Pattern: "round.[", Rate: 1},
+		{Pattern: "round.*", Rate: 1.5},
+	} {
+		if _, err := traceSamplerFromConfig(Config{TraceSampleRules: []TraceSampleRule{rule}}); err == nil {
+			t.Fatalf("expected error for rule %+v", rule)
+		}
+	}
+}
+
+func TestRuleSampler_MatchesTopicsAndSpanNames(t *testing.T) {
+	sampler, err := newRuleSampler([]TraceSampleRule{
+		{Pattern: "round.finalization.failed.v1", Rate: 1},
+		{Pattern: "*.error.v1", Rate: 1},
+		{Pattern: "discord.*.trace.v1", Rate: 0},
+		{Pattern: "noisy handler", Rate: 0},
+	}, 0.000001, false)
+	if err != nil {
+		t.Fatalf("newRuleSampler: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		span  string
+		topic string
+		want  sdktrace.SamplingDecision
+	}{
+		{"exact topic kept", "round.finalization.failed.v1 publish", "round.finalization.failed.v1", sdktrace.RecordAndSample},
+		{"wildcard topic kept", "x", "leaderboard.update.error.v1", sdktrace.RecordAndSample},
+		{"chatty topic dropped", "discord.round.trace.v1 receive", "discord.round.trace.v1", sdktrace.Drop},
+		{"span name dropped", "noisy handler", "", sdktrace.Drop},
+		{"unmatched root uses default rate", "round.created.v1 publish", "round.created.v1", sdktrace.Drop},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := sdktrace.SamplingParameters{
+				ParentContext: context.Background(),
+				TraceID:       trace.TraceID{8: 0xff, 9: 0xff, 10: 0xff, 11: 0xff, 12: 0xff, 13: 0xff, 14: 0xff, 15: 0xff},
+				Name:          tt.span,
+			}
+			if tt.topic != "" {
+				p.Attributes = append(p.Attributes, semconv.MessagingDestinationName(tt.topic))
+			}
+			if got := sampler.ShouldSample(p).Decision; got != tt.want {
+				t.Fatalf("decision = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleSampler_UnmatchedSpansFollowParent(t *testing.T) {
+	sampler, err := newRuleSampler([]TraceSampleRule{{Pattern: "*.error.v1", Rate: 1}}, 1, false)
+	if err != nil {
+		t.Fatalf("newRuleSampler: %v", err)
+	}
+
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{1},
+	})
+	p := sdktrace.SamplingParameters{
+		ParentContext: trace.ContextWithSpanContext(context.Background(), parent),
+		TraceID:       parent.TraceID(),
+		Name:          "round.created.v1 publish",
+	}
+	if got := sampler.ShouldSample(p).Decision; got != sdktrace.Drop {
+		t.Fatalf("expected unsampled parent to drop child, got %v", got)
+	}
+
+	p.Name = "round.error.v1 publish"
+	p.Attributes = append(p.Attributes, semconv.MessagingDestinationName("round.error.v1"))
+	if got := sampler.ShouldSample(p).Decision; got != sdktrace.RecordAndSample {
+		t.Fatalf("expected rule to keep span under unsampled parent, got %v", got)
+	}
+}
+
+func TestErrorBiasedSampling_ExportsOnlyFailedSpansOfDroppedTraces(t *testing.T) {
+	sampler, err := traceSamplerFromConfig(Config{
+		TraceSampleRules: []TraceSampleRule{{Pattern: "discord.*", Rate: 0}},
+		TraceErrorBiased: true,
+	})
+	if err != nil {
+		t.Fatalf("traceSamplerFromConfig: %v", err)
+	}
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sampler),
+		sdktrace.WithSpanProcessor(errorBiasedProcessor{next: sdktrace.NewSimpleSpanProcessor(exporter)}),
+	)
+	defer tp.Shutdown(context.Background())
+	tracer := tp.Tracer("test")
+
+	_, ok := tracer.Start(context.Background(), "discord.ok")
+	ok.End()
+	_, failed := tracer.Start(context.Background(), "discord.failed")
+	failed.SetStatus(codes.Error, errors.New("boom").Error())
+	failed.End()
+	_, kept := tracer.Start(context.Background(), "round.created")
+	kept.End()
+
+	spans := exporter.GetSpans()
+	got := make(map[string]bool, len(spans))
+	for _, s := range spans {
+		got[s.Name] = s.SpanContext.IsSampled()
+	}
+	if len(got) != 2 || !got["discord.failed"] || !got["round.created"] {
+		t.Fatalf("expected failed and sampled spans exported as sampled, got %v", got)
+	}
+}
@@ -61,6 +61,17 @@ func (r Result) Validate() error {
 	return nil
 }
 
+// ReplyResult builds a Result addressed to a request-reply inbox.
+// The logical event topic travels in reply_topic metadata so the requester can
+// distinguish a response from its sibling failure topic.
+func ReplyResult(replyTo, topic string, payload any) Result {
+	return Result{
+		Topic:    replyTo,
+		Payload:  payload,
+		Metadata: map[string]string{utils.MetadataReplyTopic: topic},
+	}
+}
+
 // WrapTransformingTyped wraps domain handlers that return []Result.
 // Each Result is validated and transformed into a Watermill message with explicit topic routing.
 func WrapTransformingTyped[T any](
@@ -11,6 +11,15 @@ const (
 	MetadataGuildID          = "guild_id"
 	MetadataInteractionID    = "interaction_id"
 	MetadataInteractionToken = "interaction_token"
+
+	// MetadataReplyTo carries the inbox a request-reply responder should answer on.
+	MetadataReplyTo = "reply_to"
+	// MetadataReplyTopic carries the logical event topic of a reply (e.g. user.get.failed.v1),
+	// since the reply itself is published on an ephemeral inbox subject.
+	MetadataReplyTopic = "reply_topic"
+	// MetadataSubject carries the subject a message was delivered on, set by the
+	// JetStream subscriber (eventbus.SubjectMetadataKey).
+	MetadataSubject = "_js_subject"
 )
 
 // MiddlewareHelpers defines the interface for handling metadata.